@@ -1,21 +1,37 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	"github.com/aditherevenger/Budget-Tracker-API/config"
 	"github.com/aditherevenger/Budget-Tracker-API/controllers"
 	"github.com/aditherevenger/Budget-Tracker-API/database"
 	"github.com/aditherevenger/Budget-Tracker-API/middleware"
 	"github.com/aditherevenger/Budget-Tracker-API/repository"
 	"github.com/aditherevenger/Budget-Tracker-API/services"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
 )
 
 func main() {
+	cfg := config.Load()
+
+	// Signing keys; a placeholder or short HS256 secret is only tolerated outside release mode
+	release := cfg.Server.Mode == gin.ReleaseMode || gin.Mode() == gin.ReleaseMode
+	jwtKeys, err := utils.NewKeySet(cfg.JWT, release)
+	if err != nil {
+		log.Fatal("Invalid JWT configuration: ", err)
+	}
+	if jwtKeys.Weak() {
+		log.Println("Warning: JWT_SECRET is weak; set a random value of at least 32 bytes or use RS256/EdDSA keys before running in release mode")
+	}
+	utils.SetKeySet(jwtKeys)
 
 	// Connect to the database
 	database.Connect()
@@ -25,19 +41,91 @@ func main() {
 	userRepo := repository.NewUserRepository()
 	categoryRepo := repository.NewCategoryRepository()
 	transactionRepo := repository.NewTransactionRepository()
+	importRepo := repository.NewImportRepository()
+	accountRepo := repository.NewAccountRepository()
+	netWorthRepo := repository.NewNetWorthRepository()
+	budgetRepo := repository.NewBudgetRepository()
+	scheduleRepo := repository.NewScheduleRepository()
+	reportEmailRepo := repository.NewReportEmailRepository()
+	sessionRepo := repository.NewSessionRepository()
+	oneTimeTokenRepo := repository.NewOneTimeTokenRepository()
+	mfaRepo := repository.NewMFARepository()
+	securityEventRepo := repository.NewSecurityEventRepository()
+	apiKeyRepo := repository.NewAPIKeyRepository()
+	// Failed logins are counted in memory unless several instances need to share the counts
+	loginAttempts, err := repository.NewLoginAttemptStore(cfg.Login.ThrottleStore)
+	if err != nil {
+		log.Fatal("Invalid LOGIN_THROTTLE_STORE: ", err)
+	}
+
+	// The monthly rollup is built once when it is added to an existing database; writes keep it current
+	rollupRepo := repository.NewRollupRepository()
+	if missing, err := rollupRepo.Missing(); err != nil {
+		log.Fatal("Failed to check monthly rollups:", err)
+	} else if missing {
+		users, err := rollupRepo.RebuildAll()
+		if err != nil {
+			log.Fatal("Failed to build monthly rollups:", err)
+		}
+		log.Printf("Built monthly rollups for %d users", users)
+	}
+
+	// Outgoing mail is optional; without SMTP_HOST summary emails are not sent
+	var mailer services.Mailer
+	if cfg.Mail.Host != "" {
+		mailer = services.NewSMTPMailer(cfg.Mail)
+	}
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo)
+	verificationService := services.NewEmailVerificationService(userRepo, oneTimeTokenRepo, mailer, cfg.Server.PublicURL)
+	mfaService := services.NewMFAService(mfaRepo, userRepo)
+	loginThrottle := services.NewLoginThrottle(loginAttempts, securityEventRepo, cfg.Login)
+	authService := services.NewAuthService(userRepo, sessionRepo, verificationService, mfaService, loginThrottle, cfg.JWT)
+	passwordService := services.NewPasswordService(userRepo, sessionRepo, oneTimeTokenRepo, apiKeyRepo, loginThrottle, mailer, cfg.Server.PasswordResetURL)
+	securityService := services.NewSecurityService(securityEventRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
 	categoryService := services.NewCategoryService(categoryRepo)
-	transactionService := services.NewTransactionService(transactionRepo, categoryRepo)
+	transactionService := services.NewTransactionService(transactionRepo, categoryRepo, userRepo)
+	importService := services.NewImportService(importRepo, transactionRepo, categoryRepo)
+	accountService := services.NewAccountService(accountRepo)
+	reportService := services.NewReportService(transactionRepo, budgetRepo, userRepo)
+	netWorthService := services.NewNetWorthService(netWorthRepo, transactionRepo)
+	budgetService := services.NewBudgetService(budgetRepo, categoryRepo)
+	forecastService := services.NewForecastService(scheduleRepo, transactionRepo, categoryRepo)
+	insightsService := services.NewInsightsService(transactionRepo, userRepo)
+	reportEmailService := services.NewReportEmailService(reportEmailRepo, userRepo, transactionRepo, reportService, mailer, cfg.Server.PublicURL)
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(authService)
+	passwordController := controllers.NewPasswordController(passwordService)
+	verificationController := controllers.NewEmailVerificationController(verificationService)
+	mfaController := controllers.NewMFAController(mfaService)
+	securityController := controllers.NewSecurityController(securityService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
 	categoryController := controllers.NewCategoryController(categoryService)
 	transactionController := controllers.NewTransactionController(transactionService)
+	importController := controllers.NewImportController(importService)
+	accountController := controllers.NewAccountController(accountService)
+	reportController := controllers.NewReportController(reportService)
+	netWorthController := controllers.NewNetWorthController(netWorthService)
+	budgetController := controllers.NewBudgetController(budgetService)
+	forecastController := controllers.NewForecastController(forecastService)
+	reportEmailController := controllers.NewReportEmailController(reportEmailService)
+	insightsController := controllers.NewInsightsController(insightsService)
+
+	// Summary email job
+	if mailer != nil {
+		services.StartReportScheduler(context.Background(), reportEmailService, time.Duration(cfg.Mail.ReportIntervalMinutes)*time.Minute)
+	} else {
+		log.Println("SMTP_HOST is not set, summary emails, password resets and email verification are disabled")
+	}
 
 	// Set up routes
 	router := gin.Default()
+	// The client address counts towards login lockouts, so only trusted proxies may forward it
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 
 	// CORS middleware
 	router.Use(cors.New(cors.Config{
@@ -52,17 +140,58 @@ func main() {
 	{
 		auth.POST("/register", authController.Register)
 		auth.POST("/login", authController.Login)
+		auth.POST("/refresh", authController.Refresh)
+		auth.POST("/logout", authController.Logout)
+		auth.POST("/mfa/verify", authController.VerifyMFA)
+		auth.POST("/password/forgot", passwordController.ForgotPassword)
+		auth.POST("/password/reset", passwordController.ResetPassword)
+		auth.GET("/verify-email", verificationController.VerifyEmail)
+		auth.POST("/verify-email", verificationController.VerifyEmail)
 	}
+	router.GET("/.well-known/jwks.json", authController.JWKS)
+	router.GET("/api/email/unsubscribe", reportEmailController.UnsubscribePage)
+	router.POST("/api/email/unsubscribe", reportEmailController.Unsubscribe)
 
 	// Protected routes
 	api := router.Group("/api")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(middleware.AuthMiddleware(sessionRepo, apiKeyRepo))
+	// Endpoints that send the user's data out need a confirmed address, once mail can confirm one
+	verified := middleware.RequireVerifiedEmail(userRepo, mailer != nil)
+	// API keys only reach the groups they have a scope for; managing the account needs a login
+	session := middleware.SessionOnly()
 	{
 		// User Profile
-		api.GET("/profile", authController.GetProfile)
+		api.GET("/profile", session, authController.GetProfile)
+		api.PUT("/profile", session, authController.UpdateProfile)
+		api.POST("/profile/verify-email", session, verificationController.ResendVerification)
+
+		//Two-factor authentication
+		mfa := api.Group("/mfa", session)
+		{
+			mfa.POST("/enroll", mfaController.Enroll)
+			mfa.GET("/qr.png", mfaController.QRCode)
+			mfa.POST("/confirm", mfaController.Confirm)
+			mfa.DELETE("", mfaController.Disable)
+		}
+		api.GET("/security/events", session, securityController.GetEvents)
+
+		//API keys
+		keys := api.Group("/keys", session)
+		{
+			keys.GET("", apiKeyController.GetKeys)
+			keys.POST("", apiKeyController.CreateKey)
+			keys.DELETE("/:id", apiKeyController.DeleteKey)
+		}
+
+		//Account
+		account := api.Group("/account", session)
+		{
+			account.GET("/export", verified, accountController.ExportAccount)
+			account.POST("/import", accountController.ImportAccount)
+		}
 
 		//Categories
-		categories := api.Group("/categories")
+		categories := api.Group("/categories", middleware.RequireScope("categories"))
 		{
 			categories.GET("", categoryController.GetCategories)
 			categories.POST("", categoryController.CreateCategories)
@@ -71,7 +200,7 @@ func main() {
 		}
 
 		//Transactions
-		transactions := api.Group("/transactions")
+		transactions := api.Group("/transactions", middleware.RequireScope("transactions"))
 		{
 			transactions.GET("/", transactionController.GetTransactions)
 			transactions.POST("/", transactionController.CreateTransaction)
@@ -79,6 +208,94 @@ func main() {
 			transactions.PUT("/:id", transactionController.UpdateTransaction)
 			transactions.DELETE("/:id", transactionController.DeleteTransaction)
 			transactions.GET("/summary", transactionController.GetSummary)
+			transactions.GET("/summary/timeseries", transactionController.GetTimeSeries)
+			transactions.GET("/export", verified, transactionController.ExportTransactions)
+			transactions.GET("/export/qif", verified, transactionController.ExportQIF)
+		}
+
+		//Reports
+		reports := api.Group("/reports", middleware.RequireScope("reports"))
+		{
+			reports.GET("/categories", reportController.GetCategoryBreakdown)
+			reports.GET("/compare", reportController.ComparePeriods)
+			reports.GET("/budget", reportController.GetBudgetVariance)
+			reports.GET("/tax", reportController.GetTaxReport)
+			reports.GET("/statement.pdf", reportController.GetStatementPDF)
+		}
+
+		//Insights
+		insights := api.Group("/insights", middleware.RequireScope("reports"))
+		{
+			insights.GET("/anomalies", insightsController.GetAnomalies)
+			insights.GET("/kpis", insightsController.GetKPIs)
+		}
+
+		//Summary emails
+		email := api.Group("/email", session)
+		{
+			email.GET("/subscriptions", reportEmailController.GetSubscriptions)
+			email.POST("/subscriptions", verified, reportEmailController.Subscribe)
+			email.DELETE("/subscriptions/:id", reportEmailController.DeleteSubscription)
+			email.GET("/deliveries", reportEmailController.GetDeliveries)
+		}
+
+		//Budgets
+		budgets := api.Group("/budgets", middleware.RequireScope("budgets"))
+		{
+			budgets.GET("", budgetController.GetBudgets)
+			budgets.POST("", budgetController.CreateBudget)
+			budgets.GET("/:id", budgetController.GetBudget)
+			budgets.PUT("/:id", budgetController.UpdateBudget)
+			budgets.DELETE("/:id", budgetController.DeleteBudget)
+		}
+
+		//Forecast
+		recurring := api.Group("/recurring", middleware.RequireScope("forecast"))
+		{
+			recurring.GET("", forecastController.GetRecurringItems)
+			recurring.POST("", forecastController.CreateRecurringItem)
+			recurring.PUT("/:id", forecastController.UpdateRecurringItem)
+			recurring.DELETE("/:id", forecastController.DeleteRecurringItem)
+		}
+		bills := api.Group("/bills", middleware.RequireScope("forecast"))
+		{
+			bills.GET("", forecastController.GetBills)
+			bills.POST("", forecastController.CreateBill)
+			bills.PUT("/:id", forecastController.UpdateBill)
+			bills.DELETE("/:id", forecastController.DeleteBill)
+		}
+		api.GET("/forecast", middleware.RequireScope("forecast"), forecastController.GetForecast)
+
+		//Net worth
+		assets := api.Group("/assets", middleware.RequireScope("networth"))
+		{
+			assets.GET("", netWorthController.GetAssets)
+			assets.POST("", netWorthController.CreateAsset)
+			assets.PUT("/:id", netWorthController.UpdateAsset)
+			assets.DELETE("/:id", netWorthController.DeleteAsset)
+			assets.POST("/:id/valuations", netWorthController.AddValuation)
+			assets.DELETE("/:id/valuations/:valuation_id", netWorthController.DeleteValuation)
+		}
+		networth := api.Group("/networth", middleware.RequireScope("networth"))
+		{
+			networth.GET("", netWorthController.GetNetWorth)
+			networth.DELETE("/snapshots", netWorthController.ResetSnapshots)
+		}
+
+		//Imports
+		imports := api.Group("/imports", middleware.RequireScope("imports"))
+		{
+			imports.GET("", importController.GetImports)
+			imports.DELETE("/:id", importController.UndoImport)
+			imports.POST("/csv/preview", importController.PreviewCSV)
+			imports.POST("/csv", importController.CommitCSV)
+			imports.POST("/ofx/preview", importController.PreviewOFX)
+			imports.POST("/ofx", importController.CommitOFX)
+			imports.POST("/qif/preview", importController.PreviewQIF)
+			imports.POST("/qif", importController.CommitQIF)
+			imports.GET("/mappings", importController.GetMappings)
+			imports.POST("/mappings", importController.CreateMapping)
+			imports.DELETE("/mappings/:id", importController.DeleteMapping)
 		}
 	}
 
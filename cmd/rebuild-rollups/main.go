@@ -0,0 +1,34 @@
+// Command rebuild-rollups recomputes the monthly rollup from the transactions table, for every user or
+// for the one given with -user. Run it after transactions were changed outside the API.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+func main() {
+	userID := flag.Uint("user", 0, "only rebuild this user's rollup")
+	flag.Parse()
+
+	database.Connect()
+	database.Migrate()
+
+	rollupRepo := repository.NewRollupRepository()
+	if *userID != 0 {
+		if err := rollupRepo.Rebuild(uint(*userID)); err != nil {
+			log.Fatal("Failed to rebuild monthly rollups:", err)
+		}
+		log.Printf("Rebuilt monthly rollups for user %d", *userID)
+		return
+	}
+
+	users, err := rollupRepo.RebuildAll()
+	if err != nil {
+		log.Fatal("Failed to rebuild monthly rollups:", err)
+	}
+	log.Printf("Rebuilt monthly rollups for %d users", users)
+}
@@ -0,0 +1,34 @@
+// Command send-reports runs the summary email job once and exits, for deployments that schedule it
+// with cron instead of the in-process scheduler.
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+func main() {
+	cfg := config.Load()
+	if cfg.Mail.Host == "" {
+		log.Fatal("SMTP_HOST is not set")
+	}
+
+	database.Connect()
+	database.Migrate()
+
+	userRepo := repository.NewUserRepository()
+	transactionRepo := repository.NewTransactionRepository()
+	reportService := services.NewReportService(transactionRepo, repository.NewBudgetRepository(), userRepo)
+	reportEmailService := services.NewReportEmailService(repository.NewReportEmailRepository(), userRepo, transactionRepo, reportService, services.NewSMTPMailer(cfg.Mail), cfg.Server.PublicURL)
+
+	result, err := reportEmailService.RunDue(time.Now())
+	if err != nil {
+		log.Fatal("Failed to send summary emails:", err)
+	}
+	log.Printf("Summary emails: %d due, %d sent, %d failed, %d skipped", result.Due, result.Sent, result.Failed, result.Skipped)
+}
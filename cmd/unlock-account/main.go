@@ -0,0 +1,58 @@
+// Command unlock-account lifts the login lockout of the account with -email and clears the failed logins
+// of the client address given with -ip. It works on the database login attempt store; with the memory
+// store, lockouts end when the server restarts.
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+func main() {
+	email := flag.String("email", "", "unlock the account with this email")
+	ip := flag.String("ip", "", "clear the failed logins of this client address")
+	flag.Parse()
+	if *email == "" && *ip == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	database.Connect()
+	database.Migrate()
+
+	throttle := services.NewLoginThrottle(repository.NewDatabaseLoginAttemptStore(), repository.NewSecurityEventRepository(), config.Load().Login)
+	if *email != "" {
+		// an unknown email can be locked out too; it just has no account to record the event for
+		var userID uint
+		user, err := repository.NewUserRepository().GetByEmail(*email)
+		if err == nil {
+			userID = user.ID
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Fatal("Failed to look up the account:", err)
+		}
+		locked, err := throttle.Unlock(*email, userID, services.UnlockAdmin)
+		if err != nil {
+			log.Fatal("Failed to unlock the account:", err)
+		}
+		if locked {
+			log.Printf("Unlocked %s", *email)
+		} else {
+			log.Printf("%s was not locked out; its failed logins were cleared", *email)
+		}
+	}
+	if *ip != "" {
+		if err := throttle.UnlockAddress(*ip); err != nil {
+			log.Fatal("Failed to clear the address:", err)
+		}
+		log.Printf("Cleared the failed logins of %s", *ip)
+	}
+}
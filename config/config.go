@@ -3,12 +3,15 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
 	Database DatabaseConfig
 	JWT      JWTConfig
 	Server   ServerConfig
+	Mail     MailConfig
+	Login    LoginConfig
 }
 type DatabaseConfig struct {
 	Host     string
@@ -17,12 +20,51 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 }
+
+// JWTConfig selects how access tokens are signed. HS256 signs with Secret; RS256 and EdDSA sign with
+// the PEM private key in PrivateKeyFile. Keys that were replaced stay valid for verification while
+// listed in PreviousSecrets or PreviousPublicKeyFiles.
 type JWTConfig struct {
-	Secret string
+	Algorithm              string
+	Secret                 string
+	PrivateKeyFile         string
+	PreviousSecrets        []string
+	PreviousPublicKeyFiles []string
+	// AccessTokenMinutes is how long an access token is valid; RefreshTokenDays how long a session
+	// survives without being refreshed
+	AccessTokenMinutes int
+	RefreshTokenDays   int
 }
 type ServerConfig struct {
 	Port string
 	Mode string
+	// PublicURL is where users reach the API; links in emails are built from it
+	PublicURL string
+	// PasswordResetURL is the front-end page reset emails link to, with the token appended as ?token=;
+	// when empty the email only carries the token
+	PasswordResetURL string
+	// TrustedProxies may set X-Forwarded-For; without them the client address is the connecting peer
+	TrustedProxies []string
+}
+
+// MailConfig configures the SMTP server outgoing mail is handed to. Mail is disabled when Host is empty.
+type MailConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// ReportIntervalMinutes is how often the scheduler looks for summary emails that are due
+	ReportIntervalMinutes int
+}
+
+// LoginConfig sets when failed logins lock out an account or a client address. ThrottleStore is
+// "memory" for a single instance or "database" when several instances share the failure counts.
+type LoginConfig struct {
+	ThrottleStore  string
+	MaxFailures    int
+	IPMaxFailures  int
+	LockoutMinutes int
 }
 
 func Load() *Config {
@@ -35,11 +77,34 @@ func Load() *Config {
 			Name:     getEnv("DB_NAME", "budget_tracker"),
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "default-secret-change-in-production"),
+			Algorithm:              getEnv("JWT_ALGORITHM", "HS256"),
+			Secret:                 getEnv("JWT_SECRET", "default-secret-change-in-production"),
+			PrivateKeyFile:         getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			PreviousSecrets:        getEnvAsList("JWT_PREVIOUS_SECRETS"),
+			PreviousPublicKeyFiles: getEnvAsList("JWT_PREVIOUS_PUBLIC_KEY_FILES"),
+			AccessTokenMinutes:     getEnvAsInt("JWT_ACCESS_TTL_MINUTES", 15),
+			RefreshTokenDays:       getEnvAsInt("JWT_REFRESH_TTL_DAYS", 30),
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Mode: getEnv("SERVER_MODE", "debug"),
+			Port:             getEnv("SERVER_PORT", "8080"),
+			Mode:             getEnv("SERVER_MODE", "debug"),
+			PublicURL:        getEnv("PUBLIC_URL", "http://localhost:8080"),
+			PasswordResetURL: getEnv("PASSWORD_RESET_URL", ""),
+			TrustedProxies:   getEnvAsList("TRUSTED_PROXIES"),
+		},
+		Mail: MailConfig{
+			Host:                  getEnv("SMTP_HOST", ""),
+			Port:                  getEnv("SMTP_PORT", "587"),
+			Username:              getEnv("SMTP_USERNAME", ""),
+			Password:              getEnv("SMTP_PASSWORD", ""),
+			From:                  getEnv("SMTP_FROM", "Budget Tracker <no-reply@localhost>"),
+			ReportIntervalMinutes: getEnvAsPositiveInt("REPORT_INTERVAL_MINUTES", 15),
+		},
+		Login: LoginConfig{
+			ThrottleStore:  getEnv("LOGIN_THROTTLE_STORE", "memory"),
+			MaxFailures:    getEnvAsInt("LOGIN_MAX_FAILURES", 5),
+			IPMaxFailures:  getEnvAsInt("LOGIN_IP_MAX_FAILURES", 20),
+			LockoutMinutes: getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15),
 		},
 	}
 }
@@ -59,3 +124,22 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsPositiveInt falls back to defaultValue unless the variable is a number above zero
+func getEnvAsPositiveInt(key string, defaultValue int) int {
+	if value := getEnvAsInt(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma-separated variable, dropping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -13,8 +13,13 @@ func TestLoad_Defaults(t *testing.T) {
 	os.Unsetenv("DB_PASSWORD")
 	os.Unsetenv("DB_NAME")
 	os.Unsetenv("JWT_SECRET")
+	os.Unsetenv("JWT_ALGORITHM")
+	os.Unsetenv("JWT_ACCESS_TTL_MINUTES")
+	os.Unsetenv("JWT_REFRESH_TTL_DAYS")
 	os.Unsetenv("SERVER_PORT")
 	os.Unsetenv("SERVER_MODE")
+	os.Unsetenv("LOGIN_THROTTLE_STORE")
+	os.Unsetenv("LOGIN_MAX_FAILURES")
 
 	cfg := Load()
 
@@ -36,12 +41,24 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.JWT.Secret != "default-secret-change-in-production" {
 		t.Errorf("expected JWT_SECRET default, got '%s'", cfg.JWT.Secret)
 	}
+	if cfg.JWT.Algorithm != "HS256" {
+		t.Errorf("expected JWT_ALGORITHM default 'HS256', got '%s'", cfg.JWT.Algorithm)
+	}
+	if cfg.JWT.AccessTokenMinutes != 15 {
+		t.Errorf("expected JWT_ACCESS_TTL_MINUTES default 15, got %d", cfg.JWT.AccessTokenMinutes)
+	}
+	if cfg.JWT.RefreshTokenDays != 30 {
+		t.Errorf("expected JWT_REFRESH_TTL_DAYS default 30, got %d", cfg.JWT.RefreshTokenDays)
+	}
 	if cfg.Server.Port != "8080" {
 		t.Errorf("expected SERVER_PORT default '8080', got '%s'", cfg.Server.Port)
 	}
 	if cfg.Server.Mode != "debug" {
 		t.Errorf("expected SERVER_MODE default 'debug', got '%s'", cfg.Server.Mode)
 	}
+	if cfg.Login.ThrottleStore != "memory" || cfg.Login.MaxFailures != 5 || cfg.Login.IPMaxFailures != 20 || cfg.Login.LockoutMinutes != 15 {
+		t.Errorf("unexpected login throttle defaults %+v", cfg.Login)
+	}
 }
 
 func TestLoad_EnvOverride(t *testing.T) {
@@ -107,3 +124,24 @@ func TestGetEnvAsInt(t *testing.T) {
 		t.Errorf("expected fallback 42, got %d", v)
 	}
 }
+
+func TestGetEnvAsList(t *testing.T) {
+	os.Setenv("TEST_LIST", " a, ,b ,")
+	if v := getEnvAsList("TEST_LIST"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("expected [a b], got %q", v)
+	}
+	os.Unsetenv("TEST_LIST")
+	if v := getEnvAsList("TEST_LIST"); v != nil {
+		t.Errorf("expected nil, got %q", v)
+	}
+}
+
+func TestLoad_ReportIntervalMustBePositive(t *testing.T) {
+	defer os.Unsetenv("REPORT_INTERVAL_MINUTES")
+	for value, want := range map[string]int{"0": 15, "-5": 15, "30": 30} {
+		os.Setenv("REPORT_INTERVAL_MINUTES", value)
+		if got := Load().Mail.ReportIntervalMinutes; got != want {
+			t.Errorf("REPORT_INTERVAL_MINUTES=%s: expected %d, got %d", value, want, got)
+		}
+	}
+}
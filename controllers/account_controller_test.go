@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockAccountService struct {
+	ExportFn func(userID uint, w io.Writer) error
+	ImportFn func(userID uint, data io.ReaderAt, size int64) (*models.AccountImportResult, error)
+}
+
+func (m *mockAccountService) ExportAccount(userID uint, w io.Writer) error { return m.ExportFn(userID, w) }
+func (m *mockAccountService) ImportAccount(userID uint, data io.ReaderAt, size int64) (*models.AccountImportResult, error) {
+	return m.ImportFn(userID, data, size)
+}
+
+func TestAccountController_ExportAccount(t *testing.T) {
+	mockSvc := &mockAccountService{ExportFn: func(userID uint, w io.Writer) error {
+		_, err := io.WriteString(w, "PK")
+		return err
+	}}
+	ctrl := NewAccountController(mockSvc)
+	r := setupGin()
+	r.GET("/api/account/export", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.ExportAccount(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/account/export", nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" || rec.Body.String() != "PK" {
+		t.Fatalf("unexpected export response %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+	}
+}
+
+func TestAccountController_ImportAccount_Errors(t *testing.T) {
+	var importErr error
+	mockSvc := &mockAccountService{ImportFn: func(userID uint, data io.ReaderAt, size int64) (*models.AccountImportResult, error) {
+		if size != 5 { t.Fatalf("expected upload size to be passed through, got %d", size) }
+		if importErr != nil { return nil, importErr }
+		return &models.AccountImportResult{Version: 1}, nil
+	}}
+	ctrl := NewAccountController(mockSvc)
+	r := setupGin()
+	r.POST("/api/account/import", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.ImportAccount(c) })
+
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{nil, http.StatusCreated},
+		{services.ErrAccountNotEmpty, http.StatusConflict},
+		{fmt.Errorf("%w: missing manifest.json", services.ErrInvalidArchive), http.StatusBadRequest},
+	} {
+		importErr = tc.err
+		rec := performMultipartRequest(r, "/api/account/import", nil, "export.zip", "PK...")
+		if rec.Code != tc.code { t.Fatalf("expected %d for %v, got %d body=%s", tc.code, tc.err, rec.Code, rec.Body.String()) }
+	}
+
+	rec := performMultipartRequest(r, "/api/account/import", nil, "", "")
+	if rec.Code != http.StatusBadRequest { t.Fatalf("expected %d without a file, got %d", http.StatusBadRequest, rec.Code) }
+}
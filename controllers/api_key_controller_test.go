@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockAPIKeyService struct {
+	CreateKeyFn func(userID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error)
+	GetKeysFn   func(userID uint) ([]models.APIKeyResponse, error)
+	DeleteKeyFn func(userID uint, id uint) error
+}
+
+func (m *mockAPIKeyService) CreateKey(userID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) { return m.CreateKeyFn(userID, req) }
+func (m *mockAPIKeyService) GetKeys(userID uint) ([]models.APIKeyResponse, error) { return m.GetKeysFn(userID) }
+func (m *mockAPIKeyService) DeleteKey(userID uint, id uint) error { return m.DeleteKeyFn(userID, id) }
+
+func TestAPIKeyController(t *testing.T) {
+	svc := &mockAPIKeyService{
+		CreateKeyFn: func(userID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+			if req.Scopes[0] == "profile:write" { return nil, services.ErrInvalidAPIKeyScope }
+			return &models.CreatedAPIKey{APIKeyResponse: models.APIKeyResponse{ID: 1, Name: req.Name, Scopes: req.Scopes}, Key: "bt_secret"}, nil
+		},
+		GetKeysFn: func(userID uint) ([]models.APIKeyResponse, error) { return []models.APIKeyResponse{{ID: 1, Prefix: "bt_secretpr"}}, nil },
+		DeleteKeyFn: func(userID uint, id uint) error {
+			if id != 1 { return gorm.ErrRecordNotFound }
+			return nil
+		},
+	}
+	ctrl := NewAPIKeyController(svc)
+	r := setupGin()
+	r.Use(func(c *gin.Context) { c.Set("user_id", uint(1)) })
+	r.POST("/api/keys", ctrl.CreateKey)
+	r.GET("/api/keys", ctrl.GetKeys)
+	r.DELETE("/api/keys/:id", ctrl.DeleteKey)
+
+	rec := performRequest(r, http.MethodPost, "/api/keys", models.CreateAPIKeyRequest{Name: "sync", Scopes: []string{"reports:read"}}, nil)
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"key":"bt_secret"`) { t.Fatalf("expected the key once, got %d %s", rec.Code, rec.Body) }
+	if rec := performRequest(r, http.MethodPost, "/api/keys", models.CreateAPIKeyRequest{Name: "sync", Scopes: []string{"profile:write"}}, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400 for a bad scope, got %d", rec.Code) }
+	if rec := performRequest(r, http.MethodPost, "/api/keys", map[string]interface{}{"name": "sync"}, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400 without scopes, got %d", rec.Code) }
+
+	rec = performRequest(r, http.MethodGet, "/api/keys", nil, nil)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), `"key"`) || !strings.Contains(rec.Body.String(), `"transactions:write"`) { t.Fatalf("expected the keys without secrets and the scope list, got %d %s", rec.Code, rec.Body) }
+
+	if rec := performRequest(r, http.MethodDelete, "/api/keys/1", nil, nil); rec.Code != http.StatusOK { t.Fatalf("expected 200, got %d", rec.Code) }
+	if rec := performRequest(r, http.MethodDelete, "/api/keys/2", nil, nil); rec.Code != http.StatusNotFound { t.Fatalf("expected 404, got %d", rec.Code) }
+	if rec := performRequest(r, http.MethodDelete, "/api/keys/abc", nil, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400, got %d", rec.Code) }
+}
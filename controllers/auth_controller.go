@@ -1,10 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/aditherevenger/Budget-Tracker-API/models"
 	"github.com/aditherevenger/Budget-Tracker-API/services"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
 	"github.com/gin-gonic/gin"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 type AuthController struct {
@@ -26,6 +30,10 @@ func (ac *AuthController) Register(c *gin.Context) {
 
 	user, err := ac.authService.Register(&req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -43,19 +51,119 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := ac.authService.Login(&req)
+	result, err := ac.authService.Login(&req, c.ClientIP())
 	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	respondLogin(c, result)
+}
+
+// VerifyMFA completes a login that answered with mfa_required
+func (ac *AuthController) VerifyMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := ac.authService.VerifyMFA(&req, c.ClientIP())
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	respondLogin(c, result)
+}
+
+// respondLoginError answers a locked out account and a locked out address alike, with the time to wait
+func respondLoginError(c *gin.Context, err error) {
+	var throttled *services.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCredentials), errors.Is(err, services.ErrInvalidMFAChallenge),
+		errors.Is(err, services.ErrInvalidMFACode), errors.Is(err, services.ErrMFANotEnrolled):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// respondLogin sends either the challenge or the new session's tokens
+func respondLogin(c *gin.Context, result *models.LoginResult) {
+	if result.Challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": result.Challenge.MFARequired,
+			"mfa_token":    result.Challenge.MFAToken,
+			"expires_in":   result.Challenge.ExpiresIn,
+			"message":      "Enter the code from your authenticator app",
+		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token":   token,
-		"user":    user,
-		"message": "Login successful",
+		"token":         result.Tokens.Token,
+		"refresh_token": result.Tokens.RefreshToken,
+		"token_type":    result.Tokens.TokenType,
+		"expires_in":    result.Tokens.ExpiresIn,
+		"user":          result.User,
+		"message":       "Login successful",
+	})
+}
+
+// Refresh rotates the refresh token and returns a new access token
+func (ac *AuthController) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := ac.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		respondRefreshError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the session behind a refresh token, or every session with "all"
+func (ac *AuthController) Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ac.authService.Logout(&req); err != nil {
+		respondRefreshError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out",
 	})
 }
 
+// JWKS publishes the public keys access tokens are signed with so other services can verify them
+func (ac *AuthController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.CurrentJWKS())
+}
+
+// respondRefreshError maps unusable refresh tokens to 401 and everything else to 500
+func respondRefreshError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
 func (ac *AuthController) GetProfile(c *gin.Context) {
 	userIDStr, exists := c.Get("user_id")
 	if !exists {
@@ -74,3 +182,33 @@ func (ac *AuthController) GetProfile(c *gin.Context) {
 		"user": user,
 	})
 }
+
+func (ac *AuthController) UpdateProfile(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDStr.(uint)
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := ac.authService.UpdateProfile(userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
@@ -3,32 +3,56 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
 	"github.com/gin-gonic/gin"
 )
 
 type mockAuthService struct {
 	RegisterFn      func(req *models.UserRegistrationRequest) (*models.UserResponse, error)
-	LoginFn         func(req *models.UserLoginRequest) (string, *models.UserResponse, error)
+	LoginFn         func(req *models.UserLoginRequest, clientIP string) (*models.LoginResult, error)
+	VerifyMFAFn     func(req *models.MFAVerifyRequest, clientIP string) (*models.LoginResult, error)
+	RefreshFn       func(refreshToken string) (*models.AuthTokens, error)
+	LogoutFn        func(req *models.LogoutRequest) error
 	GetProfileFn    func(userID uint) (*models.UserResponse, error)
+	UpdateProfileFn func(userID uint, req *models.UpdateProfileRequest) (*models.UserResponse, error)
 }
 
 func (m *mockAuthService) Register(req *models.UserRegistrationRequest) (*models.UserResponse, error) {
 	return m.RegisterFn(req)
 }
 
-func (m *mockAuthService) Login(req *models.UserLoginRequest) (string, *models.UserResponse, error) {
-	return m.LoginFn(req)
+func (m *mockAuthService) Login(req *models.UserLoginRequest, clientIP string) (*models.LoginResult, error) {
+	return m.LoginFn(req, clientIP)
+}
+
+func (m *mockAuthService) VerifyMFA(req *models.MFAVerifyRequest, clientIP string) (*models.LoginResult, error) {
+	return m.VerifyMFAFn(req, clientIP)
+}
+
+func (m *mockAuthService) Refresh(refreshToken string) (*models.AuthTokens, error) {
+	return m.RefreshFn(refreshToken)
+}
+
+func (m *mockAuthService) Logout(req *models.LogoutRequest) error {
+	return m.LogoutFn(req)
 }
 
 func (m *mockAuthService) GetUserProfile(userID uint) (*models.UserResponse, error) {
 	return m.GetProfileFn(userID)
 }
 
+func (m *mockAuthService) UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.UserResponse, error) {
+	return m.UpdateProfileFn(userID, req)
+}
+
 func setupGin() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -70,8 +94,11 @@ func TestAuthController_Register_Success(t *testing.T) {
 
 func TestAuthController_Login_Success(t *testing.T) {
 	mockSvc := &mockAuthService{
-		LoginFn: func(req *models.UserLoginRequest) (string, *models.UserResponse, error) {
-			return "token123", &models.UserResponse{ID: 1, Email: req.Email}, nil
+		LoginFn: func(req *models.UserLoginRequest, clientIP string) (*models.LoginResult, error) {
+			return &models.LoginResult{
+				Tokens: &models.AuthTokens{Token: "token123", RefreshToken: "refresh123", TokenType: "Bearer", ExpiresIn: 900},
+				User:   &models.UserResponse{ID: 1, Email: req.Email},
+			}, nil
 		},
 	}
 	ctrl := NewAuthController(mockSvc)
@@ -83,6 +110,85 @@ func TestAuthController_Login_Success(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
 	}
+	if !strings.Contains(rec.Body.String(), `"refresh_token":"refresh123"`) {
+		t.Fatalf("expected refresh token in body, got %s", rec.Body.String())
+	}
+}
+
+func TestAuthController_Login_Throttled(t *testing.T) {
+	var seenIP string
+	mockSvc := &mockAuthService{
+		LoginFn: func(req *models.UserLoginRequest, clientIP string) (*models.LoginResult, error) {
+			seenIP = clientIP
+			if req.Password == "locked" {
+				return nil, &services.LoginThrottledError{RetryAfter: 1500 * time.Millisecond}
+			}
+			return nil, services.ErrInvalidCredentials
+		},
+	}
+	ctrl := NewAuthController(mockSvc)
+	r := setupGin()
+	r.POST("/api/auth/login", ctrl.Login)
+
+	rec := performRequest(r, http.MethodPost, "/api/auth/login", models.UserLoginRequest{Email: "jane@example.com", Password: "wrong"}, nil)
+	if rec.Code != http.StatusUnauthorized || seenIP == "" {
+		t.Fatalf("expected status %d with the client address, got %d ip=%q", http.StatusUnauthorized, rec.Code, seenIP)
+	}
+
+	rec = performRequest(r, http.MethodPost, "/api/auth/login", models.UserLoginRequest{Email: "jane@example.com", Password: "locked"}, nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("expected 429 with Retry-After 2, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestAuthController_Refresh(t *testing.T) {
+	mockSvc := &mockAuthService{
+		RefreshFn: func(refreshToken string) (*models.AuthTokens, error) {
+			if refreshToken == "spent" {
+				return nil, services.ErrRefreshTokenReused
+			}
+			return &models.AuthTokens{Token: "token456", RefreshToken: "refresh456", TokenType: "Bearer", ExpiresIn: 900}, nil
+		},
+	}
+	ctrl := NewAuthController(mockSvc)
+	r := setupGin()
+	r.POST("/api/auth/refresh", ctrl.Refresh)
+
+	rec := performRequest(r, http.MethodPost, "/api/auth/refresh", models.RefreshRequest{RefreshToken: "refresh123"}, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"token":"token456"`) {
+		t.Fatalf("expected new tokens, got %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = performRequest(r, http.MethodPost, "/api/auth/refresh", models.RefreshRequest{RefreshToken: "spent"}, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d, body=%s", http.StatusUnauthorized, rec.Code, rec.Body.String())
+	}
+
+	rec = performRequest(r, http.MethodPost, "/api/auth/refresh", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestAuthController_Logout(t *testing.T) {
+	var got *models.LogoutRequest
+	mockSvc := &mockAuthService{
+		LogoutFn: func(req *models.LogoutRequest) error {
+			got = req
+			return nil
+		},
+	}
+	ctrl := NewAuthController(mockSvc)
+	r := setupGin()
+	r.POST("/api/auth/logout", ctrl.Logout)
+
+	rec := performRequest(r, http.MethodPost, "/api/auth/logout", map[string]any{"refresh_token": "refresh123", "all": true}, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if got == nil || got.RefreshToken != "refresh123" || !got.All {
+		t.Fatalf("unexpected logout request %+v", got)
+	}
 }
 
 func TestAuthController_Login_BadRequest(t *testing.T) {
@@ -128,3 +234,80 @@ func TestAuthController_GetProfile_Success(t *testing.T) {
 		t.Fatalf("expected status %d, got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
 	}
 }
+
+func TestAuthController_UpdateProfile(t *testing.T) {
+	mockSvc := &mockAuthService{
+		UpdateProfileFn: func(userID uint, req *models.UpdateProfileRequest) (*models.UserResponse, error) {
+			if *req.Timezone != "Europe/Berlin" {
+				return nil, fmt.Errorf("%w: %q", services.ErrInvalidTimezone, *req.Timezone)
+			}
+			return &models.UserResponse{ID: userID, Timezone: *req.Timezone}, nil
+		},
+	}
+	ctrl := NewAuthController(mockSvc)
+	r := setupGin()
+	r.PUT("/api/profile", func(c *gin.Context) {
+		c.Set("user_id", uint(42))
+		ctrl.UpdateProfile(c)
+	})
+
+	rec := performRequest(r, http.MethodPut, "/api/profile", map[string]string{"timezone": "Europe/Berlin"}, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"timezone":"Europe/Berlin"`) {
+		t.Fatalf("expected status %d with the new zone, got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	rec = performRequest(r, http.MethodPut, "/api/profile", map[string]string{"timezone": "Moon/Base"}, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestAuthController_JWKS(t *testing.T) {
+	ctrl := NewAuthController(&mockAuthService{})
+	r := setupGin()
+	r.GET("/.well-known/jwks.json", ctrl.JWKS)
+
+	rec := performRequest(r, http.MethodGet, "/.well-known/jwks.json", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	var body struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Keys == nil {
+		t.Fatalf("expected a keys array, got %s", rec.Body.String())
+	}
+}
+
+func TestAuthController_Login_MFAChallenge(t *testing.T) {
+	mockSvc := &mockAuthService{
+		LoginFn: func(req *models.UserLoginRequest, clientIP string) (*models.LoginResult, error) {
+			return &models.LoginResult{Challenge: &models.MFAChallenge{MFARequired: true, MFAToken: "challenge123", ExpiresIn: 300}}, nil
+		},
+		VerifyMFAFn: func(req *models.MFAVerifyRequest, clientIP string) (*models.LoginResult, error) {
+			if req.MFAToken != "challenge123" || req.Code != "123456" {
+				return nil, services.ErrInvalidMFACode
+			}
+			return &models.LoginResult{Tokens: &models.AuthTokens{Token: "token123", RefreshToken: "refresh123"}, User: &models.UserResponse{ID: 1}}, nil
+		},
+	}
+	ctrl := NewAuthController(mockSvc)
+	r := setupGin()
+	r.POST("/api/auth/login", ctrl.Login)
+	r.POST("/api/auth/mfa/verify", ctrl.VerifyMFA)
+
+	rec := performRequest(r, http.MethodPost, "/api/auth/login", models.UserLoginRequest{Email: "jane@example.com", Password: "Pass1234"}, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"mfa_required":true`) || strings.Contains(rec.Body.String(), `"refresh_token"`) {
+		t.Fatalf("expected a challenge without tokens, got %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = performRequest(r, http.MethodPost, "/api/auth/mfa/verify", models.MFAVerifyRequest{MFAToken: "challenge123", Code: "123456"}, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"refresh_token":"refresh123"`) {
+		t.Fatalf("expected tokens, got %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = performRequest(r, http.MethodPost, "/api/auth/mfa/verify", models.MFAVerifyRequest{MFAToken: "challenge123", Code: "000000"}, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockBudgetService struct {
+	services.BudgetService
+	CreateFn func(userID uint, req *models.CreateBudgetRequest) (*models.Budget, error)
+}
+
+func (m *mockBudgetService) CreateBudget(userID uint, req *models.CreateBudgetRequest) (*models.Budget, error) {
+	return m.CreateFn(userID, req)
+}
+
+func TestBudgetController_CreateBudget(t *testing.T) {
+	mockSvc := &mockBudgetService{CreateFn: func(userID uint, req *models.CreateBudgetRequest) (*models.Budget, error) {
+		if req.StartMonth == "bad" { return nil, fmt.Errorf("%w: start_month must be YYYY-MM", services.ErrInvalidBudget) }
+		return &models.Budget{ID: 1, UserID: userID, CategoryID: req.CategoryID, Amount: req.Amount, StartMonth: req.StartMonth}, nil
+	}}
+	ctrl := NewBudgetController(mockSvc)
+	r := setupGin()
+	r.POST("/api/budgets", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.CreateBudget(c) })
+
+	rec := performRequest(r, http.MethodPost, "/api/budgets", map[string]any{"category_id": 2, "amount": 400, "start_month": "2025-09"}, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodPost, "/api/budgets", map[string]any{"category_id": 2, "amount": 400, "start_month": "bad"}, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodPost, "/api/budgets", map[string]any{"category_id": 2, "amount": -1, "start_month": "2025-09"}, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
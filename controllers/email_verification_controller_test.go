@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockVerificationService struct {
+	services.EmailVerificationService
+	VerifyEmailFn        func(token string) error
+	ResendVerificationFn func(userID uint) error
+}
+
+func (m *mockVerificationService) VerifyEmail(token string) error { return m.VerifyEmailFn(token) }
+func (m *mockVerificationService) ResendVerification(userID uint) error { return m.ResendVerificationFn(userID) }
+
+func TestEmailVerificationController_VerifyEmail(t *testing.T) {
+	svc := &mockVerificationService{VerifyEmailFn: func(token string) error {
+		if token != "good" { return services.ErrInvalidVerificationToken }
+		return nil
+	}}
+	r := setupGin()
+	ctrl := NewEmailVerificationController(svc)
+	r.GET("/api/auth/verify-email", ctrl.VerifyEmail)
+	r.POST("/api/auth/verify-email", ctrl.VerifyEmail)
+
+	if rec := performRequest(r, http.MethodGet, "/api/auth/verify-email?token=good", nil, nil); rec.Code != http.StatusOK { t.Fatalf("expected 200 for the link, got %d %s", rec.Code, rec.Body) }
+	if rec := performRequest(r, http.MethodPost, "/api/auth/verify-email", map[string]string{"token": "good"}, nil); rec.Code != http.StatusOK { t.Fatalf("expected 200 for a JSON body, got %d %s", rec.Code, rec.Body) }
+	if rec := performRequest(r, http.MethodGet, "/api/auth/verify-email?token=bad", nil, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400, got %d", rec.Code) }
+	if rec := performRequest(r, http.MethodGet, "/api/auth/verify-email", nil, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400 without a token, got %d", rec.Code) }
+}
+
+func TestEmailVerificationController_ResendVerification(t *testing.T) {
+	var result error
+	svc := &mockVerificationService{ResendVerificationFn: func(userID uint) error { return result }}
+	r := setupGin()
+	r.Use(func(c *gin.Context) { c.Set("user_id", uint(1)) })
+	r.POST("/api/profile/verify-email", NewEmailVerificationController(svc).ResendVerification)
+
+	for err, want := range map[error]int{nil: http.StatusAccepted, services.ErrVerificationRateLimited: http.StatusTooManyRequests, services.ErrEmailAlreadyVerified: http.StatusConflict, services.ErrMailerNotConfigured: http.StatusServiceUnavailable} {
+		result = err
+		if rec := performRequest(r, http.MethodPost, "/api/profile/verify-email", nil, nil); rec.Code != want { t.Fatalf("%v: expected %d, got %d", err, want, rec.Code) }
+	}
+}
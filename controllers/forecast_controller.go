@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type ForecastController struct {
+	forecastService services.ForecastService
+}
+
+func NewForecastController(forecastService services.ForecastService) *ForecastController {
+	return &ForecastController{
+		forecastService: forecastService,
+	}
+}
+
+func (fc *ForecastController) GetForecast(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.ForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	forecast, err := fc.forecastService.GetForecast(userID, req.Days, req.LookbackDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"forecast": forecast,
+	})
+}
+
+func (fc *ForecastController) CreateRecurringItem(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.CreateRecurringItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := fc.forecastService.CreateRecurringItem(userID, &req)
+	if err != nil {
+		respondScheduleError(c, err, "Recurring item not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "Recurring item created successfully",
+		"recurring_item": item,
+	})
+}
+
+func (fc *ForecastController) GetRecurringItems(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	items, err := fc.forecastService.GetRecurringItems(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recurring_items": items,
+	})
+}
+
+func (fc *ForecastController) UpdateRecurringItem(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurring item ID"})
+		return
+	}
+
+	var req models.UpdateRecurringItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := fc.forecastService.UpdateRecurringItem(uint(id), userID, &req)
+	if err != nil {
+		respondScheduleError(c, err, "Recurring item not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recurring item updated successfully",
+		"recurring_item": item,
+	})
+}
+
+func (fc *ForecastController) DeleteRecurringItem(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurring item ID"})
+		return
+	}
+
+	if err := fc.forecastService.DeleteRecurringItem(uint(id), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recurring item deleted successfully",
+	})
+}
+
+func (fc *ForecastController) CreateBill(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.CreateBillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bill, err := fc.forecastService.CreateBill(userID, &req)
+	if err != nil {
+		respondScheduleError(c, err, "Bill not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Bill created successfully",
+		"bill":    bill,
+	})
+}
+
+// GetBills lists bills; pass unpaid=true to leave out the ones already paid
+func (fc *ForecastController) GetBills(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	bills, err := fc.forecastService.GetBills(userID, c.Query("unpaid") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bills": bills,
+	})
+}
+
+func (fc *ForecastController) UpdateBill(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bill ID"})
+		return
+	}
+
+	var req models.UpdateBillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bill, err := fc.forecastService.UpdateBill(uint(id), userID, &req)
+	if err != nil {
+		respondScheduleError(c, err, "Bill not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bill updated successfully",
+		"bill":    bill,
+	})
+}
+
+func (fc *ForecastController) DeleteBill(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bill ID"})
+		return
+	}
+
+	if err := fc.forecastService.DeleteBill(uint(id), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bill deleted successfully",
+	})
+}
+
+func respondScheduleError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
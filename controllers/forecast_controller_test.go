@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockForecastService struct {
+	services.ForecastService
+	ForecastFn   func(userID uint, days, lookbackDays int) (*models.Forecast, error)
+	CreateBillFn func(userID uint, req *models.CreateBillRequest) (*models.Bill, error)
+}
+
+func (m *mockForecastService) GetForecast(userID uint, days, lookbackDays int) (*models.Forecast, error) {
+	return m.ForecastFn(userID, days, lookbackDays)
+}
+func (m *mockForecastService) CreateBill(userID uint, req *models.CreateBillRequest) (*models.Bill, error) {
+	return m.CreateBillFn(userID, req)
+}
+
+func TestForecastController_GetForecast(t *testing.T) {
+	mockSvc := &mockForecastService{ForecastFn: func(userID uint, days, lookbackDays int) (*models.Forecast, error) {
+		if days != 30 { t.Fatalf("expected days=30, got %d", days) }
+		return &models.Forecast{Days: days}, nil
+	}}
+	ctrl := NewForecastController(mockSvc)
+	r := setupGin()
+	r.GET("/api/forecast", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetForecast(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/forecast?days=30", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/forecast?days=1000", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestForecastController_CreateBill(t *testing.T) {
+	mockSvc := &mockForecastService{CreateBillFn: func(userID uint, req *models.CreateBillRequest) (*models.Bill, error) {
+		if req.CategoryID == 9 { return nil, fmt.Errorf("%w: category not found or does not belong to user", services.ErrInvalidSchedule) }
+		return &models.Bill{ID: 1, UserID: userID, CategoryID: req.CategoryID, Amount: req.Amount, DueDate: req.DueDate}, nil
+	}}
+	ctrl := NewForecastController(mockSvc)
+	r := setupGin()
+	r.POST("/api/bills", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.CreateBill(c) })
+
+	rec := performRequest(r, http.MethodPost, "/api/bills", map[string]any{"category_id": 1, "amount": 600, "due_date": "2025-10-01T00:00:00Z"}, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodPost, "/api/bills", map[string]any{"category_id": 9, "amount": 600, "due_date": "2025-10-01T00:00:00Z"}, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,446 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type ImportController struct {
+	importService services.ImportService
+}
+
+const (
+	// maxImportUploadBytes caps the whole multipart request of an import upload
+	maxImportUploadBytes = 10 << 20
+	// importFormMemory is how much of a parsed upload is held in memory before spilling to disk
+	importFormMemory = 8 << 20
+)
+
+func NewImportController(importService services.ImportService) *ImportController {
+	return &ImportController{
+		importService: importService,
+	}
+}
+
+func (ic *ImportController) CreateMapping(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.CreateImportMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping, err := ic.importService.CreateMapping(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Import mapping created successfully",
+		"mapping": mapping,
+	})
+}
+
+func (ic *ImportController) GetMappings(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	mappings, err := ic.importService.GetMappings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mappings": mappings,
+	})
+}
+
+func (ic *ImportController) DeleteMapping(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping ID"})
+		return
+	}
+
+	if err := ic.importService.DeleteMapping(uint(id), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Import mapping deleted successfully",
+	})
+}
+
+func (ic *ImportController) PreviewCSV(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	if !parseUpload(c) {
+		return
+	}
+
+	mapping, ok := ic.resolveMapping(c, userID)
+	if !ok {
+		return
+	}
+
+	file, _, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := ic.importService.PreviewCSV(userID, file, mapping)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preview": preview,
+	})
+}
+
+func (ic *ImportController) CommitCSV(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	if !parseUpload(c) {
+		return
+	}
+
+	mapping, ok := ic.resolveMapping(c, userID)
+	if !ok {
+		return
+	}
+
+	file, fileName, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	skipErrors, _ := strconv.ParseBool(c.PostForm("skip_errors"))
+
+	batch, err := ic.importService.CommitCSV(userID, fileName, file, mapping, skipErrors)
+	respondCommit(c, batch, err)
+}
+
+func (ic *ImportController) PreviewOFX(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	if !parseUpload(c) {
+		return
+	}
+
+	categoryID, err := strconv.ParseUint(c.PostForm("category_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+		return
+	}
+
+	file, _, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := ic.importService.PreviewOFX(userID, file, uint(categoryID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preview": preview,
+	})
+}
+
+func (ic *ImportController) CommitOFX(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	if !parseUpload(c) {
+		return
+	}
+
+	categoryID, err := strconv.ParseUint(c.PostForm("category_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+		return
+	}
+
+	file, fileName, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	skipErrors, _ := strconv.ParseBool(c.PostForm("skip_errors"))
+
+	batch, err := ic.importService.CommitOFX(userID, fileName, file, uint(categoryID), skipErrors)
+	respondCommit(c, batch, err)
+}
+
+func (ic *ImportController) PreviewQIF(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	if !parseUpload(c) {
+		return
+	}
+
+	categoryID, dayFirst, ok := qifOptions(c)
+	if !ok {
+		return
+	}
+
+	file, _, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := ic.importService.PreviewQIF(userID, file, categoryID, dayFirst)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preview": preview,
+	})
+}
+
+func (ic *ImportController) CommitQIF(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	if !parseUpload(c) {
+		return
+	}
+
+	categoryID, dayFirst, ok := qifOptions(c)
+	if !ok {
+		return
+	}
+
+	file, fileName, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	skipErrors, _ := strconv.ParseBool(c.PostForm("skip_errors"))
+
+	batch, err := ic.importService.CommitQIF(userID, fileName, file, categoryID, dayFirst, skipErrors)
+	respondCommit(c, batch, err)
+}
+
+func (ic *ImportController) GetImports(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	batches, err := ic.importService.GetImports(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"imports": batches,
+	})
+}
+
+func (ic *ImportController) UndoImport(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return
+	}
+
+	if err := ic.importService.UndoImport(uint(id), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Import undone successfully",
+	})
+}
+
+// parseUpload caps the request body at maxImportUploadBytes and parses the multipart form up front,
+// so an oversized upload is rejected before any form field is read
+func parseUpload(c *gin.Context) bool {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportUploadBytes)
+	var tooLarge *http.MaxBytesError
+	if err := c.Request.ParseMultipartForm(importFormMemory); errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file exceeds the 10 MB upload limit"})
+		return false
+	}
+	return true
+}
+
+func openUpload(c *gin.Context) (multipart.File, string, bool) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return nil, "", false
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, "", false
+	}
+	return file, fileHeader.Filename, true
+}
+
+// qifOptions reads the optional fallback category_id and date_format (mdy or dmy)
+func qifOptions(c *gin.Context) (uint, bool, bool) {
+	var categoryID uint64
+	if raw := c.PostForm("category_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+			return 0, false, false
+		}
+		categoryID = id
+	}
+
+	switch c.DefaultPostForm("date_format", "mdy") {
+	case "mdy":
+		return uint(categoryID), false, true
+	case "dmy":
+		return uint(categoryID), true, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_format must be mdy or dmy"})
+		return 0, false, false
+	}
+}
+
+func respondCommit(c *gin.Context, batch *models.ImportBatch, err error) {
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrNothingToImport) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Import committed successfully",
+		"import":  batch,
+	})
+}
+
+// resolveMapping loads a saved mapping when mapping_id is given, otherwise decodes an inline JSON mapping
+func (ic *ImportController) resolveMapping(c *gin.Context, userID uint) (*models.ImportMapping, bool) {
+	if mappingID := c.PostForm("mapping_id"); mappingID != "" {
+		id, err := strconv.ParseUint(mappingID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping ID"})
+			return nil, false
+		}
+		mapping, err := ic.importService.GetMapping(uint(id), userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import mapping not found"})
+			return nil, false
+		}
+		return mapping, true
+	}
+
+	raw := c.PostForm("mapping")
+	if raw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mapping_id or mapping is required"})
+		return nil, false
+	}
+	var mapping models.ImportMapping
+	if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping: " + err.Error()})
+		return nil, false
+	}
+	mapping.ID = 0
+	mapping.UserID = userID
+	return &mapping, true
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type mockImportService struct {
+	CreateMappingFn func(userID uint, req *models.CreateImportMappingRequest) (*models.ImportMapping, error)
+	GetMappingsFn   func(userID uint) ([]models.ImportMapping, error)
+	GetMappingFn    func(id uint, userID uint) (*models.ImportMapping, error)
+	DeleteMappingFn func(id uint, userID uint) error
+	PreviewCSVFn    func(userID uint, data io.Reader, mapping *models.ImportMapping) (*models.ImportPreview, error)
+	CommitCSVFn     func(userID uint, fileName string, data io.Reader, mapping *models.ImportMapping, skipErrors bool) (*models.ImportBatch, error)
+	PreviewOFXFn    func(userID uint, data io.Reader, categoryID uint) (*models.ImportPreview, error)
+	CommitOFXFn     func(userID uint, fileName string, data io.Reader, categoryID uint, skipErrors bool) (*models.ImportBatch, error)
+	PreviewQIFFn    func(userID uint, data io.Reader, categoryID uint, dayFirst bool) (*models.ImportPreview, error)
+	CommitQIFFn     func(userID uint, fileName string, data io.Reader, categoryID uint, dayFirst bool, skipErrors bool) (*models.ImportBatch, error)
+	GetImportsFn    func(userID uint) ([]models.ImportBatch, error)
+	UndoImportFn    func(id uint, userID uint) error
+}
+
+func (m *mockImportService) CreateMapping(userID uint, req *models.CreateImportMappingRequest) (*models.ImportMapping, error) {
+	return m.CreateMappingFn(userID, req)
+}
+func (m *mockImportService) GetMappings(userID uint) ([]models.ImportMapping, error) {
+	return m.GetMappingsFn(userID)
+}
+func (m *mockImportService) GetMapping(id uint, userID uint) (*models.ImportMapping, error) {
+	return m.GetMappingFn(id, userID)
+}
+func (m *mockImportService) DeleteMapping(id uint, userID uint) error { return m.DeleteMappingFn(id, userID) }
+func (m *mockImportService) PreviewCSV(userID uint, data io.Reader, mapping *models.ImportMapping) (*models.ImportPreview, error) {
+	return m.PreviewCSVFn(userID, data, mapping)
+}
+func (m *mockImportService) CommitCSV(userID uint, fileName string, data io.Reader, mapping *models.ImportMapping, skipErrors bool) (*models.ImportBatch, error) {
+	return m.CommitCSVFn(userID, fileName, data, mapping, skipErrors)
+}
+func (m *mockImportService) PreviewOFX(userID uint, data io.Reader, categoryID uint) (*models.ImportPreview, error) {
+	return m.PreviewOFXFn(userID, data, categoryID)
+}
+func (m *mockImportService) CommitOFX(userID uint, fileName string, data io.Reader, categoryID uint, skipErrors bool) (*models.ImportBatch, error) {
+	return m.CommitOFXFn(userID, fileName, data, categoryID, skipErrors)
+}
+func (m *mockImportService) PreviewQIF(userID uint, data io.Reader, categoryID uint, dayFirst bool) (*models.ImportPreview, error) {
+	return m.PreviewQIFFn(userID, data, categoryID, dayFirst)
+}
+func (m *mockImportService) CommitQIF(userID uint, fileName string, data io.Reader, categoryID uint, dayFirst bool, skipErrors bool) (*models.ImportBatch, error) {
+	return m.CommitQIFFn(userID, fileName, data, categoryID, dayFirst, skipErrors)
+}
+func (m *mockImportService) GetImports(userID uint) ([]models.ImportBatch, error) {
+	return m.GetImportsFn(userID)
+}
+func (m *mockImportService) UndoImport(id uint, userID uint) error { return m.UndoImportFn(id, userID) }
+
+func performMultipartRequest(r http.Handler, path string, fields map[string]string, fileName, fileContent string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		_ = w.WriteField(k, v)
+	}
+	if fileName != "" {
+		part, _ := w.CreateFormFile("file", fileName)
+		_, _ = part.Write([]byte(fileContent))
+	}
+	_ = w.Close()
+	req := httptest.NewRequest(http.MethodPost, path, &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImportController_PreviewCSV_InlineMapping(t *testing.T) {
+	mockSvc := &mockImportService{PreviewCSVFn: func(userID uint, data io.Reader, mapping *models.ImportMapping) (*models.ImportPreview, error) {
+		body, _ := io.ReadAll(data)
+		if mapping.DateColumn != "Date" || mapping.UserID != userID || string(body) != "Date,Amount\n" {
+			t.Fatalf("unexpected mapping or file: %+v %q", mapping, body)
+		}
+		return &models.ImportPreview{Total: 0}, nil
+	}}
+	ctrl := NewImportController(mockSvc)
+	r := setupGin()
+	r.POST("/api/imports/csv/preview", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.PreviewCSV(c) })
+
+	fields := map[string]string{"mapping": `{"has_header":true,"date_column":"Date","amount_column":"Amount"}`}
+	rec := performMultipartRequest(r, "/api/imports/csv/preview", fields, "bank.csv", "Date,Amount\n")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+}
+
+func TestImportController_CommitCSV_SavedMapping(t *testing.T) {
+	mockSvc := &mockImportService{
+		GetMappingFn: func(id uint, userID uint) (*models.ImportMapping, error) { return &models.ImportMapping{ID: id, UserID: userID}, nil },
+		CommitCSVFn: func(userID uint, fileName string, data io.Reader, mapping *models.ImportMapping, skipErrors bool) (*models.ImportBatch, error) {
+			if mapping.ID != 3 || fileName != "bank.csv" || !skipErrors { t.Fatalf("unexpected commit args: %+v %s %v", mapping, fileName, skipErrors) }
+			return &models.ImportBatch{ID: 1, UserID: userID, RowCount: 2}, nil
+		},
+	}
+	ctrl := NewImportController(mockSvc)
+	r := setupGin()
+	r.POST("/api/imports/csv", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.CommitCSV(c) })
+
+	rec := performMultipartRequest(r, "/api/imports/csv", map[string]string{"mapping_id": "3", "skip_errors": "true"}, "bank.csv", "x")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+}
+
+func TestImportController_PreviewCSV_MissingMappingOrFile(t *testing.T) {
+	ctrl := NewImportController(&mockImportService{})
+	r := setupGin()
+	r.POST("/api/imports/csv/preview", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.PreviewCSV(c) })
+
+	rec := performMultipartRequest(r, "/api/imports/csv/preview", nil, "bank.csv", "x")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	rec = performMultipartRequest(r, "/api/imports/csv/preview", map[string]string{"mapping": `{"date_column":"1","amount_column":"2"}`}, "", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestImportController_UndoImport_NotFound(t *testing.T) {
+	mockSvc := &mockImportService{UndoImportFn: func(id uint, userID uint) error { return gorm.ErrRecordNotFound }}
+	ctrl := NewImportController(mockSvc)
+	r := setupGin()
+	r.DELETE("/api/imports/:id", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.UndoImport(c) })
+
+	rec := performRequest(r, http.MethodDelete, "/api/imports/7", nil, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+}
+
+func TestImportController_CommitOFX(t *testing.T) {
+	mockSvc := &mockImportService{CommitOFXFn: func(userID uint, fileName string, data io.Reader, categoryID uint, skipErrors bool) (*models.ImportBatch, error) {
+		if categoryID != 4 || fileName != "statement.qfx" { t.Fatalf("unexpected args: %d %s", categoryID, fileName) }
+		return &models.ImportBatch{ID: 1, UserID: userID, Source: models.ImportSourceOFX}, nil
+	}}
+	ctrl := NewImportController(mockSvc)
+	r := setupGin()
+	r.POST("/api/imports/ofx", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.CommitOFX(c) })
+
+	rec := performMultipartRequest(r, "/api/imports/ofx", map[string]string{"category_id": "4"}, "statement.qfx", "<OFX></OFX>")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	rec = performMultipartRequest(r, "/api/imports/ofx", nil, "statement.qfx", "<OFX></OFX>")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d without category_id, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestImportController_PreviewQIF_DateFormat(t *testing.T) {
+	mockSvc := &mockImportService{PreviewQIFFn: func(userID uint, data io.Reader, categoryID uint, dayFirst bool) (*models.ImportPreview, error) {
+		if !dayFirst || categoryID != 0 { t.Fatalf("unexpected options: category=%d dayFirst=%v", categoryID, dayFirst) }
+		return &models.ImportPreview{}, nil
+	}}
+	ctrl := NewImportController(mockSvc)
+	r := setupGin()
+	r.POST("/api/imports/qif/preview", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.PreviewQIF(c) })
+
+	rec := performMultipartRequest(r, "/api/imports/qif/preview", map[string]string{"date_format": "dmy"}, "export.qif", "!Type:Bank\n")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	rec = performMultipartRequest(r, "/api/imports/qif/preview", map[string]string{"date_format": "ymd"}, "export.qif", "!Type:Bank\n")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for unknown date format, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestImportController_CommitCSV_UploadTooLarge(t *testing.T) {
+	ctrl := NewImportController(&mockImportService{})
+	r := setupGin()
+	r.POST("/api/imports/csv", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.CommitCSV(c) })
+
+	body := strings.Repeat("x", maxImportUploadBytes+1)
+	rec := performMultipartRequest(r, "/api/imports/csv", map[string]string{"mapping_id": "3"}, "bank.csv", body)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusRequestEntityTooLarge, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type InsightsController struct {
+	insightsService services.InsightsService
+}
+
+func NewInsightsController(insightsService services.InsightsService) *InsightsController {
+	return &InsightsController{
+		insightsService: insightsService,
+	}
+}
+
+func (ic *InsightsController) GetAnomalies(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.ReportPeriodRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := ic.insightsService.GetAnomalies(userID, req.StartDate, req.EndDate)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"anomalies": report,
+	})
+}
+
+// GetKPIs returns the financial health indicators of the period with trends against the period before
+func (ic *InsightsController) GetKPIs(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.ReportPeriodRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	kpis, err := ic.insightsService.GetKPIs(userID, req.StartDate, req.EndDate)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"kpis": kpis,
+	})
+}
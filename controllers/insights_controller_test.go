@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockInsightsService struct {
+	services.InsightsService
+	AnomaliesFn func(userID uint, startDate, endDate string) (*models.AnomalyReport, error)
+	KPIsFn      func(userID uint, startDate, endDate string) (*models.FinancialKPIs, error)
+}
+
+func (m *mockInsightsService) GetAnomalies(userID uint, startDate, endDate string) (*models.AnomalyReport, error) {
+	return m.AnomaliesFn(userID, startDate, endDate)
+}
+func (m *mockInsightsService) GetKPIs(userID uint, startDate, endDate string) (*models.FinancialKPIs, error) {
+	return m.KPIsFn(userID, startDate, endDate)
+}
+
+func TestInsightsController_GetAnomalies(t *testing.T) {
+	mockSvc := &mockInsightsService{AnomaliesFn: func(userID uint, startDate, endDate string) (*models.AnomalyReport, error) {
+		if startDate == "bad" { return nil, fmt.Errorf("%w: invalid start_date", services.ErrInvalidSummaryRange) }
+		return &models.AnomalyReport{Transactions: []models.Transaction{{ID: 7, Amount: 400, Anomaly: &models.TransactionAnomaly{Basis: models.AnomalyByCategory, Typical: 62, Score: 32.57, Samples: 13}}}}, nil
+	}}
+	ctrl := NewInsightsController(mockSvc)
+	r := setupGin()
+	r.GET("/api/insights/anomalies", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetAnomalies(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/insights/anomalies?start_date=2026-10-01", nil, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"anomaly":{"basis":"category","typical":62`) {
+		t.Fatalf("expected %d with the anomaly annotation, got %d body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/insights/anomalies?start_date=bad", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestInsightsController_GetKPIs(t *testing.T) {
+	mockSvc := &mockInsightsService{KPIsFn: func(userID uint, startDate, endDate string) (*models.FinancialKPIs, error) {
+		if startDate == "bad" { return nil, fmt.Errorf("%w: invalid start_date", services.ErrInvalidSummaryRange) }
+		rate, previous, delta, improved := 55.0, 22.5, 32.5, true
+		return &models.FinancialKPIs{SavingsRate: models.KPIValue{Current: &rate, Previous: &previous, Delta: &delta, Trend: models.TrendUp, Improved: &improved}}, nil
+	}}
+	ctrl := NewInsightsController(mockSvc)
+	r := setupGin()
+	r.GET("/api/insights/kpis", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetKPIs(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/insights/kpis?start_date=2025-09-01", nil, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"savings_rate":{"current":55,"previous":22.5,"delta":32.5,"trend":"up","improved":true}`) {
+		t.Fatalf("expected %d with the savings rate trend, got %d body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/insights/kpis?start_date=bad", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockMFAService struct {
+	services.MFAService
+	EnrollFn  func(userID uint) (*models.MFAEnrollment, error)
+	QRCodeFn  func(userID uint) ([]byte, error)
+	ConfirmFn func(userID uint, code string) ([]string, error)
+	DisableFn func(userID uint, req *models.MFADisableRequest) error
+}
+
+func (m *mockMFAService) Enroll(userID uint) (*models.MFAEnrollment, error) { return m.EnrollFn(userID) }
+func (m *mockMFAService) QRCode(userID uint) ([]byte, error)                { return m.QRCodeFn(userID) }
+func (m *mockMFAService) Confirm(userID uint, code string) ([]string, error) { return m.ConfirmFn(userID, code) }
+func (m *mockMFAService) Disable(userID uint, req *models.MFADisableRequest) error { return m.DisableFn(userID, req) }
+
+func setupMFARouter(svc services.MFAService) *gin.Engine {
+	r := setupGin()
+	r.Use(func(c *gin.Context) { c.Set("user_id", uint(1)) })
+	ctrl := NewMFAController(svc)
+	r.POST("/api/mfa/enroll", ctrl.Enroll)
+	r.GET("/api/mfa/qr.png", ctrl.QRCode)
+	r.POST("/api/mfa/confirm", ctrl.Confirm)
+	r.DELETE("/api/mfa", ctrl.Disable)
+	return r
+}
+
+func TestMFAController_EnrollAndConfirm(t *testing.T) {
+	svc := &mockMFAService{
+		EnrollFn: func(userID uint) (*models.MFAEnrollment, error) { return &models.MFAEnrollment{Secret: "ABC", OTPAuthURI: "otpauth://totp/x"}, nil },
+		QRCodeFn: func(userID uint) ([]byte, error) { return []byte("\x89PNG"), nil },
+		ConfirmFn: func(userID uint, code string) ([]string, error) {
+			if code != "123456" { return nil, services.ErrInvalidMFACode }
+			return []string{"abcd-efgh"}, nil
+		},
+	}
+	r := setupMFARouter(svc)
+
+	if rec := performRequest(r, http.MethodPost, "/api/mfa/enroll", nil, nil); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"otpauth_uri":"otpauth://totp/x"`) { t.Fatalf("enroll: %d %s", rec.Code, rec.Body) }
+	rec := performRequest(r, http.MethodGet, "/api/mfa/qr.png", nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" || rec.Header().Get("Cache-Control") != "no-store" { t.Fatalf("qr: %d %v", rec.Code, rec.Header()) }
+	if rec := performRequest(r, http.MethodPost, "/api/mfa/confirm", models.MFACodeRequest{Code: "123456"}, nil); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "abcd-efgh") { t.Fatalf("confirm: %d %s", rec.Code, rec.Body) }
+	if rec := performRequest(r, http.MethodPost, "/api/mfa/confirm", models.MFACodeRequest{Code: "000000"}, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400, got %d", rec.Code) }
+
+	svc.EnrollFn = func(userID uint) (*models.MFAEnrollment, error) { return nil, services.ErrMFAAlreadyEnabled }
+	if rec := performRequest(r, http.MethodPost, "/api/mfa/enroll", nil, nil); rec.Code != http.StatusConflict { t.Fatalf("expected 409, got %d", rec.Code) }
+}
+
+func TestMFAController_Disable(t *testing.T) {
+	svc := &mockMFAService{DisableFn: func(userID uint, req *models.MFADisableRequest) error {
+		if req.Password != "Pass1234" { return services.ErrInvalidCredentials }
+		return nil
+	}}
+	r := setupMFARouter(svc)
+
+	if rec := performRequest(r, http.MethodDelete, "/api/mfa", models.MFADisableRequest{Password: "Pass1234", Code: "123456"}, nil); rec.Code != http.StatusOK { t.Fatalf("disable: %d %s", rec.Code, rec.Body) }
+	if rec := performRequest(r, http.MethodDelete, "/api/mfa", models.MFADisableRequest{Password: "wrong", Code: "123456"}, nil); rec.Code != http.StatusUnauthorized { t.Fatalf("expected 401, got %d", rec.Code) }
+	if rec := performRequest(r, http.MethodDelete, "/api/mfa", nil, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400, got %d", rec.Code) }
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockNetWorthService struct {
+	services.NetWorthService
+	AddValuationFn func(assetID uint, userID uint, req *models.CreateValuationRequest) (*models.AssetValuation, error)
+	GetNetWorthFn  func(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.NetWorthSeries, error)
+}
+
+func (m *mockNetWorthService) AddValuation(assetID uint, userID uint, req *models.CreateValuationRequest) (*models.AssetValuation, error) {
+	return m.AddValuationFn(assetID, userID, req)
+}
+func (m *mockNetWorthService) GetNetWorth(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.NetWorthSeries, error) {
+	return m.GetNetWorthFn(userID, interval, startDate, endDate)
+}
+
+func TestNetWorthController_AddValuation(t *testing.T) {
+	mockSvc := &mockNetWorthService{AddValuationFn: func(assetID uint, userID uint, req *models.CreateValuationRequest) (*models.AssetValuation, error) {
+		if assetID == 404 { return nil, gorm.ErrRecordNotFound }
+		return &models.AssetValuation{AssetID: assetID, UserID: userID, Value: *req.Value, Date: req.Date}, nil
+	}}
+	ctrl := NewNetWorthController(mockSvc)
+	r := setupGin()
+	r.POST("/api/assets/:id/valuations", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.AddValuation(c) })
+
+	body := map[string]any{"value": 250000, "date": "2025-09-01T00:00:00Z"}
+	rec := performRequest(r, http.MethodPost, "/api/assets/3/valuations", body, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodPost, "/api/assets/404/valuations", body, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodPost, "/api/assets/3/valuations", map[string]any{"date": "2025-09-01T00:00:00Z"}, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestNetWorthController_GetNetWorth(t *testing.T) {
+	mockSvc := &mockNetWorthService{GetNetWorthFn: func(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.NetWorthSeries, error) {
+		if startDate == "bad" { return nil, fmt.Errorf("%w: invalid start_date", services.ErrInvalidSummaryRange) }
+		return &models.NetWorthSeries{Interval: interval}, nil
+	}}
+	ctrl := NewNetWorthController(mockSvc)
+	r := setupGin()
+	r.GET("/api/networth", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetNetWorth(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/networth?interval=month", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/networth?start_date=bad", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
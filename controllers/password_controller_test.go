@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockPasswordService struct {
+	ForgotPasswordFn func(email string) error
+	ResetPasswordFn  func(req *models.ResetPasswordRequest) error
+}
+
+func (m *mockPasswordService) ForgotPassword(email string) error { return m.ForgotPasswordFn(email) }
+func (m *mockPasswordService) ResetPassword(req *models.ResetPasswordRequest) error { return m.ResetPasswordFn(req) }
+
+func TestPasswordController_ForgotPassword(t *testing.T) {
+	var asked []string
+	svc := &mockPasswordService{ForgotPasswordFn: func(email string) error { asked = append(asked, email); return nil }}
+	r := setupGin()
+	r.POST("/api/auth/password/forgot", NewPasswordController(svc).ForgotPassword)
+
+	known := performRequest(r, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "jane@example.com"}, nil)
+	unknown := performRequest(r, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "nobody@example.com"}, nil)
+	if known.Code != http.StatusAccepted || unknown.Code != known.Code || unknown.Body.String() != known.Body.String() { t.Fatalf("expected identical responses, got %d %s / %d %s", known.Code, known.Body, unknown.Code, unknown.Body) }
+	if len(asked) != 2 || asked[0] != "jane@example.com" { t.Fatalf("unexpected calls %v", asked) }
+
+	if rec := performRequest(r, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "not-an-email"}, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400, got %d", rec.Code) }
+
+	svc.ForgotPasswordFn = func(email string) error { return services.ErrMailerNotConfigured }
+	if rec := performRequest(r, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "jane@example.com"}, nil); rec.Code != http.StatusServiceUnavailable { t.Fatalf("expected 503, got %d", rec.Code) }
+}
+
+func TestPasswordController_ResetPassword(t *testing.T) {
+	svc := &mockPasswordService{ResetPasswordFn: func(req *models.ResetPasswordRequest) error {
+		if req.Token != "good" { return services.ErrInvalidResetToken }
+		return nil
+	}}
+	r := setupGin()
+	r.POST("/api/auth/password/reset", NewPasswordController(svc).ResetPassword)
+
+	if rec := performRequest(r, http.MethodPost, "/api/auth/password/reset", models.ResetPasswordRequest{Token: "good", NewPassword: "NewPass1"}, nil); rec.Code != http.StatusOK { t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body) }
+	if rec := performRequest(r, http.MethodPost, "/api/auth/password/reset", models.ResetPasswordRequest{Token: "bad", NewPassword: "NewPass1"}, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected 400, got %d", rec.Code) }
+	if rec := performRequest(r, http.MethodPost, "/api/auth/password/reset", models.ResetPasswordRequest{Token: "good", NewPassword: "123"}, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected a short password to be rejected, got %d", rec.Code) }
+}
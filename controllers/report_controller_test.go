@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockReportService struct {
+	CategoryBreakdownFn func(userID uint, startDate, endDate string) (*models.CategoryBreakdown, error)
+	CompareFn           func(userID uint, req *models.CompareRequest) (*models.PeriodComparison, error)
+	VarianceFn          func(userID uint, month string) (*models.VarianceReport, error)
+	StatementFn         func(userID uint, month string) (*models.Statement, error)
+	TaxReportFn         func(userID uint, year int, start string) (*models.TaxReport, error)
+}
+
+func (m *mockReportService) GetCategoryBreakdown(userID uint, startDate, endDate string) (*models.CategoryBreakdown, error) {
+	return m.CategoryBreakdownFn(userID, startDate, endDate)
+}
+func (m *mockReportService) ComparePeriods(userID uint, req *models.CompareRequest) (*models.PeriodComparison, error) {
+	return m.CompareFn(userID, req)
+}
+func (m *mockReportService) GetBudgetVariance(userID uint, month string) (*models.VarianceReport, error) {
+	return m.VarianceFn(userID, month)
+}
+func (m *mockReportService) GetStatement(userID uint, month string) (*models.Statement, error) {
+	return m.StatementFn(userID, month)
+}
+func (m *mockReportService) GetTaxReport(userID uint, year int, start string) (*models.TaxReport, error) {
+	return m.TaxReportFn(userID, year, start)
+}
+
+func TestReportController_GetCategoryBreakdown(t *testing.T) {
+	mockSvc := &mockReportService{CategoryBreakdownFn: func(userID uint, startDate, endDate string) (*models.CategoryBreakdown, error) {
+		if startDate == "bad" { return nil, fmt.Errorf("%w: invalid start_date", services.ErrInvalidSummaryRange) }
+		return &models.CategoryBreakdown{}, nil
+	}}
+	ctrl := NewReportController(mockSvc)
+	r := setupGin()
+	r.GET("/api/reports/categories", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetCategoryBreakdown(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/reports/categories?start_date=2025-09-01", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/categories?start_date=bad", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestReportController_ComparePeriods(t *testing.T) {
+	mockSvc := &mockReportService{CompareFn: func(userID uint, req *models.CompareRequest) (*models.PeriodComparison, error) {
+		if req.Period != "year" || req.Date != "2025-06-01" { t.Fatalf("unexpected request %+v", req) }
+		return &models.PeriodComparison{}, nil
+	}}
+	ctrl := NewReportController(mockSvc)
+	r := setupGin()
+	r.GET("/api/reports/compare", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.ComparePeriods(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/reports/compare?period=year&date=2025-06-01", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/compare?period=decade", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestReportController_GetBudgetVariance(t *testing.T) {
+	mockSvc := &mockReportService{VarianceFn: func(userID uint, month string) (*models.VarianceReport, error) {
+		if month == "bad" { return nil, fmt.Errorf("%w: month must be YYYY-MM", services.ErrInvalidSummaryRange) }
+		return &models.VarianceReport{Month: month, Categories: []models.VarianceRow{{CategoryID: 1, CategoryName: "Food", Type: models.Expense}}}, nil
+	}}
+	ctrl := NewReportController(mockSvc)
+	r := setupGin()
+	r.GET("/api/reports/budget", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetBudgetVariance(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/reports/budget?month=2025-09", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/budget?month=2025-09&format=csv", nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("expected csv, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); disposition != `attachment; filename="budget-variance-2025-09.csv"` {
+		t.Fatalf("unexpected disposition %q", disposition)
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/budget?month=bad", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/budget?format=pdf", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestReportController_GetTaxReport(t *testing.T) {
+	mockSvc := &mockReportService{TaxReportFn: func(userID uint, year int, start string) (*models.TaxReport, error) {
+		if start == "02-29" { return nil, fmt.Errorf("%w: start must be a MM-DD date that exists every year", services.ErrInvalidSummaryRange) }
+		if year != 2025 || start != "04-06" { t.Fatalf("unexpected year %d start %q", year, start) }
+		return &models.TaxReport{TaxYear: "2025/26", Items: []models.TaxItem{{TaxClass: "charity", Type: models.Expense, Amount: 50}}}, nil
+	}}
+	ctrl := NewReportController(mockSvc)
+	r := setupGin()
+	r.GET("/api/reports/tax", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetTaxReport(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/reports/tax?year=2025&start=04-06", nil, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"tax_year":"2025/26"`) {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/tax?year=2025&start=04-06&format=csv", nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("expected csv, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); disposition != `attachment; filename="tax-report-2025-26.csv"` {
+		t.Fatalf("unexpected disposition %q", disposition)
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/tax?start=02-29", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/tax?year=12", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestReportController_GetStatementPDF(t *testing.T) {
+	mockSvc := &mockReportService{StatementFn: func(userID uint, month string) (*models.Statement, error) {
+		if month == "bad" { return nil, fmt.Errorf("%w: month must be YYYY-MM", services.ErrInvalidSummaryRange) }
+		start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		return &models.Statement{Month: "2026-09", StartDate: start, EndDate: start.AddDate(0, 1, 0)}, nil
+	}}
+	ctrl := NewReportController(mockSvc)
+	r := setupGin()
+	r.GET("/api/reports/statement.pdf", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetStatementPDF(c) })
+
+	rec := performRequest(r, http.MethodGet, "/api/reports/statement.pdf?month=2026-09", nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("expected pdf, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.HasPrefix(rec.Body.String(), "%PDF-1.4") || rec.Header().Get("Content-Disposition") != `attachment; filename="statement-2026-09.pdf"` {
+		t.Fatalf("unexpected response %q %q", rec.Header().Get("Content-Disposition"), rec.Body.String()[:20])
+	}
+	rec = performRequest(r, http.MethodGet, "/api/reports/statement.pdf?month=bad", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
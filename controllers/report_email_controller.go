@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+// unsubscribePage asks for a click before unsubscribing, so link scanners and mail clients that fetch
+// the link don't unsubscribe anyone
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+<p>Stop receiving Budget Tracker summary emails?</p>
+<form method="post" action="?token={{.}}">
+<input type="hidden" name="token" value="{{.}}">
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+type ReportEmailController struct {
+	reportEmailService services.ReportEmailService
+}
+
+func NewReportEmailController(reportEmailService services.ReportEmailService) *ReportEmailController {
+	return &ReportEmailController{
+		reportEmailService: reportEmailService,
+	}
+}
+
+func (rc *ReportEmailController) Subscribe(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.SubscribeReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := rc.reportEmailService.Subscribe(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Subscribed successfully",
+		"subscription": subscription,
+	})
+}
+
+func (rc *ReportEmailController) GetSubscriptions(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	subscriptions, err := rc.reportEmailService.GetSubscriptions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": subscriptions,
+	})
+}
+
+func (rc *ReportEmailController) DeleteSubscription(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	if err := rc.reportEmailService.DeleteSubscription(uint(id), userID); err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Subscription deleted successfully",
+	})
+}
+
+func (rc *ReportEmailController) GetDeliveries(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	deliveries, err := rc.reportEmailService.GetDeliveries(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+	})
+}
+
+// UnsubscribePage is where the link in the email leads. It only shows a confirmation form; opening
+// the link changes nothing.
+func (rc *ReportEmailController) UnsubscribePage(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := unsubscribePage.Execute(c.Writer, token); err != nil {
+		c.Error(err)
+	}
+}
+
+// Unsubscribe is public: the token identifies the subscription. It takes the POST of the confirmation
+// form and the RFC 8058 one-click POST mail clients send for the List-Unsubscribe-Post header.
+func (rc *ReportEmailController) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := rc.reportEmailService.Unsubscribe(token); err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Unsubscribed successfully",
+	})
+}
+
+func respondSubscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
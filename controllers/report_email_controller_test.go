@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type mockReportEmailService struct {
+	services.ReportEmailService
+	SubscribeFn   func(userID uint, req *models.SubscribeReportRequest) (*models.ReportSubscription, error)
+	UnsubscribeFn func(token string) error
+}
+
+func (m *mockReportEmailService) Subscribe(userID uint, req *models.SubscribeReportRequest) (*models.ReportSubscription, error) {
+	return m.SubscribeFn(userID, req)
+}
+func (m *mockReportEmailService) Unsubscribe(token string) error { return m.UnsubscribeFn(token) }
+
+func TestReportEmailController_Subscribe(t *testing.T) {
+	mockSvc := &mockReportEmailService{SubscribeFn: func(userID uint, req *models.SubscribeReportRequest) (*models.ReportSubscription, error) {
+		return &models.ReportSubscription{ID: 1, UserID: userID, Frequency: req.Frequency, Active: true}, nil
+	}}
+	ctrl := NewReportEmailController(mockSvc)
+	r := setupGin()
+	r.POST("/api/email/subscriptions", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.Subscribe(c) })
+
+	rec := performRequest(r, http.MethodPost, "/api/email/subscriptions", map[string]any{"frequency": "weekly"}, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	rec = performRequest(r, http.MethodPost, "/api/email/subscriptions", map[string]any{"frequency": "daily"}, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestReportEmailController_Unsubscribe(t *testing.T) {
+	var calls []string
+	mockSvc := &mockReportEmailService{UnsubscribeFn: func(token string) error {
+		calls = append(calls, token)
+		if token != "abc" { return gorm.ErrRecordNotFound }
+		return nil
+	}}
+	ctrl := NewReportEmailController(mockSvc)
+	r := setupGin()
+	r.GET("/api/email/unsubscribe", ctrl.UnsubscribePage)
+	r.POST("/api/email/unsubscribe", ctrl.Unsubscribe)
+
+	// opening the link only shows the confirmation form
+	rec := performRequest(r, http.MethodGet, "/api/email/unsubscribe?token=a%22b", nil, nil)
+	if rec.Code != http.StatusOK { t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String()) }
+	if !strings.Contains(rec.Body.String(), `method="post"`) || !strings.Contains(rec.Body.String(), `value="a&#34;b"`) { t.Fatalf("expected an escaped confirmation form, got %s", rec.Body.String()) }
+	if len(calls) != 0 { t.Fatalf("expected GET not to unsubscribe, got %v", calls) }
+	if rec := performRequest(r, http.MethodGet, "/api/email/unsubscribe", nil, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected %d got %d", http.StatusBadRequest, rec.Code) }
+
+	if rec := performRequest(r, http.MethodPost, "/api/email/unsubscribe?token=abc", nil, nil); rec.Code != http.StatusOK { t.Fatalf("expected one-click unsubscribe to succeed, got %d", rec.Code) }
+	req := httptest.NewRequest(http.MethodPost, "/api/email/unsubscribe", strings.NewReader("token=abc"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	form := httptest.NewRecorder()
+	r.ServeHTTP(form, req)
+	if form.Code != http.StatusOK { t.Fatalf("expected the confirmation form to unsubscribe, got %d", form.Code) }
+	if rec := performRequest(r, http.MethodPost, "/api/email/unsubscribe?token=nope", nil, nil); rec.Code != http.StatusNotFound { t.Fatalf("expected %d got %d", http.StatusNotFound, rec.Code) }
+	if rec := performRequest(r, http.MethodPost, "/api/email/unsubscribe", nil, nil); rec.Code != http.StatusBadRequest { t.Fatalf("expected %d got %d", http.StatusBadRequest, rec.Code) }
+	if len(calls) != 3 { t.Fatalf("expected three unsubscribe calls, got %v", calls) }
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/services"
+)
+
+type SecurityController struct {
+	securityService services.SecurityService
+}
+
+func NewSecurityController(securityService services.SecurityService) *SecurityController {
+	return &SecurityController{
+		securityService: securityService,
+	}
+}
+
+// GetEvents lists lockouts and unlocks of the user's account
+func (sc *SecurityController) GetEvents(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	events, err := sc.securityService.GetEvents(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+	})
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type mockSecurityService struct {
+	GetEventsFn func(userID uint) ([]models.SecurityEvent, error)
+}
+
+func (m *mockSecurityService) GetEvents(userID uint) ([]models.SecurityEvent, error) { return m.GetEventsFn(userID) }
+
+func TestSecurityController_GetEvents(t *testing.T) {
+	svc := &mockSecurityService{GetEventsFn: func(userID uint) ([]models.SecurityEvent, error) {
+		return []models.SecurityEvent{{ID: 1, UserID: userID, Type: models.SecurityAccountLocked, IPAddress: "10.0.0.1"}}, nil
+	}}
+	ctrl := NewSecurityController(svc)
+
+	r := setupGin()
+	r.GET("/api/security/events", ctrl.GetEvents)
+	if rec := performRequest(r, http.MethodGet, "/api/security/events", nil, nil); rec.Code != http.StatusUnauthorized { t.Fatalf("expected 401 without a user, got %d", rec.Code) }
+
+	r = setupGin()
+	r.Use(func(c *gin.Context) { c.Set("user_id", uint(4)) })
+	r.GET("/api/security/events", ctrl.GetEvents)
+	rec := performRequest(r, http.MethodGet, "/api/security/events", nil, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"type":"account_locked"`) || !strings.Contains(rec.Body.String(), `"user_id":4`) { t.Fatalf("unexpected response %d %s", rec.Code, rec.Body) }
+}
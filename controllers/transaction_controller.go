@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"bytes"
+	"errors"
 	"github.com/aditherevenger/Budget-Tracker-API/models"
 	"github.com/aditherevenger/Budget-Tracker-API/services"
 	"github.com/gin-gonic/gin"
@@ -62,6 +64,10 @@ func (tc *TransactionController) GetTransactions(c *gin.Context) {
 
 	transactions, err := tc.transactionService.GetTransactions(userID, &filter)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidSummaryRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -168,11 +174,18 @@ func (tc *TransactionController) GetSummary(c *gin.Context) {
 
 	userID := userIDInterface.(uint)
 
-	startDate := c.Query("start_date")
-	endDate := c.Query("end_date")
+	var req models.SummaryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	summary, err := tc.transactionService.GetSummary(userID, startDate, endDate)
+	summary, err := tc.transactionService.GetSummary(userID, req.StartDate, req.EndDate)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidSummaryRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -181,3 +194,108 @@ func (tc *TransactionController) GetSummary(c *gin.Context) {
 		"summary": summary,
 	})
 }
+
+func (tc *TransactionController) GetTimeSeries(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.TimeSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timeSeries, err := tc.transactionService.GetTimeSeries(userID, req.Interval, req.StartDate, req.EndDate)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSummaryRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"timeseries": timeSeries,
+	})
+}
+
+func (tc *TransactionController) ExportQIF(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var filter models.TransactionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := tc.transactionService.ExportQIF(userID, &filter, &buf); err != nil {
+		if errors.Is(err, services.ErrInvalidSummaryRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="transactions.qif"`)
+	c.Data(http.StatusOK, "application/qif", buf.Bytes())
+}
+
+func (tc *TransactionController) ExportTransactions(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := userIDInterface.(uint)
+
+	var req models.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	columns, err := models.ParseExportColumns(req.Columns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = models.ExportFormatCSV
+	}
+	contentType, extension := services.ExportContentType(req.Format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="transactions.`+extension+`"`)
+	c.Status(http.StatusOK)
+
+	if err := tc.transactionService.ExportTransactions(userID, &req.TransactionFilter, req.Format, columns, c.Writer); err != nil {
+		// Once rows have been streamed the status line is gone; all we can do is cut the response short
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrInvalidSummaryRange) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.Error(err)
+		c.Abort()
+	}
+}
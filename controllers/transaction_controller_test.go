@@ -3,12 +3,16 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,7 +22,10 @@ type mockTransactionService struct {
 	GetByIDFn     func(id uint, userID uint) (*models.Transaction, error)
 	UpdateFn      func(id uint, userID uint, req *models.UpdateTransactionRequest) (*models.Transaction, error)
 	DeleteFn      func(id uint, userID uint) error
-	SummaryFn     func(userID uint, startDate, endDate string) (map[string]interface{}, error)
+	SummaryFn     func(userID uint, startDate, endDate string) (*models.Summary, error)
+	ExportQIFFn   func(userID uint, filter *models.TransactionFilter, w io.Writer) error
+	ExportFn      func(userID uint, filter *models.TransactionFilter, format models.ExportFormat, columns []string, w io.Writer) error
+	TimeSeriesFn  func(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.TimeSeriesSummary, error)
 }
 
 func (m *mockTransactionService) CreateTransaction(userID uint, req *models.CreateTransactionRequest) (*models.Transaction, error) {
@@ -34,10 +41,21 @@ func (m *mockTransactionService) UpdateTransaction(id uint, userID uint, req *mo
 	return m.UpdateFn(id, userID, req)
 }
 func (m *mockTransactionService) DeleteTransaction(id uint, userID uint) error { return m.DeleteFn(id, userID) }
-func (m *mockTransactionService) GetSummary(userID uint, startDate, endDate string) (map[string]interface{}, error) {
+func (m *mockTransactionService) GetSummary(userID uint, startDate, endDate string) (*models.Summary, error) {
 	return m.SummaryFn(userID, startDate, endDate)
 }
 
+func (m *mockTransactionService) GetTimeSeries(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.TimeSeriesSummary, error) {
+	return m.TimeSeriesFn(userID, interval, startDate, endDate)
+}
+
+func (m *mockTransactionService) ExportQIF(userID uint, filter *models.TransactionFilter, w io.Writer) error {
+	return m.ExportQIFFn(userID, filter, w)
+}
+func (m *mockTransactionService) ExportTransactions(userID uint, filter *models.TransactionFilter, format models.ExportFormat, columns []string, w io.Writer) error {
+	return m.ExportFn(userID, filter, format, columns, w)
+}
+
 func setupGinTxn() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -128,8 +146,8 @@ func TestTransactionController_Delete_Success(t *testing.T) {
 }
 
 func TestTransactionController_Summary_Success(t *testing.T) {
-	mockSvc := &mockTransactionService{ SummaryFn: func(userID uint, startDate, endDate string) (map[string]interface{}, error) {
-		return map[string]interface{}{"total_income": 1000.0, "total_expense": 200.0, "net": 800.0}, nil
+	mockSvc := &mockTransactionService{ SummaryFn: func(userID uint, startDate, endDate string) (*models.Summary, error) {
+		return &models.Summary{Timezone: "UTC", TotalIncome: 1000.0, TotalExpense: 200.0, NetBalance: 800.0}, nil
 	}}
 	ctrl := NewTransactionController(mockSvc)
 	r := setupGinTxn()
@@ -141,6 +159,20 @@ func TestTransactionController_Summary_Success(t *testing.T) {
 	}
 }
 
+func TestTransactionController_Summary_InvalidDate(t *testing.T) {
+	mockSvc := &mockTransactionService{ SummaryFn: func(userID uint, startDate, endDate string) (*models.Summary, error) {
+		return nil, fmt.Errorf("%w: invalid end_date %q", services.ErrInvalidSummaryRange, endDate)
+	}}
+	ctrl := NewTransactionController(mockSvc)
+	r := setupGinTxn()
+	r.GET("/api/transactions/summary", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetSummary(c) })
+
+	rec := performRequestTxn(r, http.MethodGet, "/api/transactions/summary?end_date=2025-02-30", nil, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
 func TestTransactionController_Unauthorized_When_No_User(t *testing.T) {
 	mockSvc := &mockTransactionService{}
 	ctrl := NewTransactionController(mockSvc)
@@ -152,3 +184,85 @@ func TestTransactionController_Unauthorized_When_No_User(t *testing.T) {
 		t.Fatalf("expected %d got %d, body=%s", http.StatusUnauthorized, rec.Code, rec.Body.String())
 	}
 }
+
+func TestTransactionController_ExportQIF(t *testing.T) {
+	mockSvc := &mockTransactionService{ ExportQIFFn: func(userID uint, filter *models.TransactionFilter, w io.Writer) error {
+		_, err := io.WriteString(w, "!Type:Bank\n^\n")
+		return err
+	}}
+	ctrl := NewTransactionController(mockSvc)
+	r := setupGinTxn()
+	r.GET("/api/transactions/export/qif", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.ExportQIF(c) })
+
+	rec := performRequestTxn(r, http.MethodGet, "/api/transactions/export/qif", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != "!Type:Bank\n^\n" || rec.Header().Get("Content-Disposition") == "" {
+		t.Fatalf("unexpected QIF response: %q %v", rec.Body.String(), rec.Header())
+	}
+}
+
+func TestTransactionController_ExportTransactions(t *testing.T) {
+	mockSvc := &mockTransactionService{ExportFn: func(userID uint, filter *models.TransactionFilter, format models.ExportFormat, columns []string, w io.Writer) error {
+		if format != models.ExportFormatNDJSON || len(columns) != 2 || columns[1] != "running_balance" || filter.Type != models.Expense {
+			t.Fatalf("unexpected export args: %s %v %+v", format, columns, filter)
+		}
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}}
+	ctrl := NewTransactionController(mockSvc)
+	r := setupGinTxn()
+	r.GET("/api/transactions/export", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.ExportTransactions(c) })
+
+	rec := performRequestTxn(r, http.MethodGet, "/api/transactions/export?format=ndjson&type=expense&columns=date,running_balance", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/x-ndjson" || rec.Header().Get("Content-Disposition") != `attachment; filename="transactions.ndjson"` {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+
+	for _, query := range []string{"format=pdf", "columns=date,balance"} {
+		rec = performRequestTxn(r, http.MethodGet, "/api/transactions/export?"+query, nil, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d for %s, got %d", http.StatusBadRequest, query, rec.Code)
+		}
+	}
+}
+
+func TestTransactionController_ExportTransactions_ErrorBeforeStreaming(t *testing.T) {
+	mockSvc := &mockTransactionService{ExportFn: func(userID uint, filter *models.TransactionFilter, format models.ExportFormat, columns []string, w io.Writer) error {
+		return errors.New("db down")
+	}}
+	ctrl := NewTransactionController(mockSvc)
+	r := setupGinTxn()
+	r.GET("/api/transactions/export", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.ExportTransactions(c) })
+
+	rec := performRequestTxn(r, http.MethodGet, "/api/transactions/export", nil, nil)
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("Content-Disposition") != "" {
+		t.Fatalf("expected a JSON error, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestTransactionController_GetTimeSeries(t *testing.T) {
+	mockSvc := &mockTransactionService{TimeSeriesFn: func(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.TimeSeriesSummary, error) {
+		if interval != models.IntervalWeek || startDate != "2025-09-01" { t.Fatalf("unexpected args %s %s", interval, startDate) }
+		if endDate == "bad" { return nil, fmt.Errorf("%w: invalid end_date", services.ErrInvalidSummaryRange) }
+		return &models.TimeSeriesSummary{Interval: interval, Buckets: []models.SummaryBucket{{Period: "2025-09-01"}}}, nil
+	}}
+	ctrl := NewTransactionController(mockSvc)
+	r := setupGinTxn()
+	r.GET("/api/transactions/summary/timeseries", func(c *gin.Context) { c.Set("user_id", uint(5)); ctrl.GetTimeSeries(c) })
+
+	rec := performRequestTxn(r, http.MethodGet, "/api/transactions/summary/timeseries?interval=week&start_date=2025-09-01", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	for _, query := range []string{"interval=week&start_date=2025-09-01&end_date=bad", "interval=quarter"} {
+		rec = performRequestTxn(r, http.MethodGet, "/api/transactions/summary/timeseries?"+query, nil, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d for %s, got %d", http.StatusBadRequest, query, rec.Code)
+		}
+	}
+}
@@ -50,9 +50,18 @@ func Connect() {
 }
 
 func Migrate() {
-	err := DB.AutoMigrate(&models.User{}, &models.Category{}, &models.Transaction{})
+	// Accounts from before email verification existed count as verified rather than losing their exports
+	backfillVerified := DB.Migrator().HasTable(&models.User{}) && !DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
+	err := DB.AutoMigrate(&models.User{}, &models.Category{}, &models.Transaction{}, &models.ImportMapping{}, &models.ImportBatch{}, &models.Asset{}, &models.AssetValuation{}, &models.NetWorthSnapshot{}, &models.Budget{}, &models.RecurringItem{}, &models.Bill{}, &models.ReportSubscription{}, &models.ReportDelivery{}, &models.MonthlyRollup{}, &models.Session{}, &models.RefreshToken{}, &models.OneTimeToken{}, &models.MFAFactor{}, &models.RecoveryCode{}, &models.LoginAttempt{}, &models.SecurityEvent{}, &models.APIKey{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	if backfillVerified {
+		err := DB.Model(&models.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at")).Error
+		if err != nil {
+			log.Fatal("Failed to mark existing users as verified:", err)
+		}
+	}
 	log.Println("Database migrated successfully")
 }
@@ -23,3 +23,24 @@ func TestMigrate_WithInMemorySQLite(t *testing.T) {
 		t.Fatalf("user create failed after migrate: %v", err)
 	}
 }
+
+func TestMigrate_BackfillsExistingUsersAsVerified(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil { t.Fatalf("open sqlite: %v", err) }
+	DB = db
+
+	// a users table from before email verification existed
+	if err := DB.Exec("CREATE TABLE users (id integer PRIMARY KEY, email text, password text, first_name text, last_name text, created_at datetime, updated_at datetime, deleted_at datetime)").Error; err != nil { t.Fatalf("create users: %v", err) }
+	if err := DB.Exec("INSERT INTO users (id, email, password, first_name, last_name, created_at, updated_at) VALUES (1, 'old@b.com', 'x', 'O', 'U', '2025-01-02 03:04:05', '2025-01-02 03:04:05')").Error; err != nil { t.Fatalf("insert user: %v", err) }
+
+	Migrate()
+	var old models.User
+	if err := DB.First(&old, 1).Error; err != nil { t.Fatalf("load user: %v", err) }
+	if old.EmailVerifiedAt == nil || !old.EmailVerifiedAt.Equal(old.CreatedAt) { t.Fatalf("expected an existing user to be verified, got %v", old.EmailVerifiedAt) }
+
+	// later migrations leave new, unverified users alone
+	fresh := &models.User{Email: "new@b.com", Password: "x", FirstName: "N", LastName: "U"}
+	if err := DB.Create(fresh).Error; err != nil { t.Fatalf("create user: %v", err) }
+	Migrate()
+	if err := DB.First(fresh, fresh.ID).Error; err != nil || fresh.EmailVerifiedAt != nil { t.Fatalf("expected a new user to stay unverified, got %v (%v)", fresh.EmailVerifiedAt, err) }
+}
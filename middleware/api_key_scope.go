@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope lets API keys through only with the area's scope: "<area>:read" for GET and HEAD
+// requests, "<area>:write" for the others. Requests with an access token are not limited.
+func RequireScope(area string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, ok := c.Get("api_key_scopes")
+		if !ok {
+			c.Next()
+			return
+		}
+
+		scope := area + ":write"
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = area + ":read"
+		}
+		if !slices.Contains(scopes.([]string), scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// SessionOnly refuses API keys on routes that manage the account itself, such as passwords, two-factor
+// authentication and the keys
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_key_id"); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys can't be used here, log in instead"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// mockAPIKeys knows the keys by hash
+type mockAPIKeys struct {
+	repository.APIKeyRepository
+	keys map[string]*models.APIKey
+}
+
+func (m *mockAPIKeys) Use(keyHash string, now time.Time) (*models.APIKey, error) {
+	if key, ok := m.keys[keyHash]; ok { return key, nil }
+	return nil, gorm.ErrRecordNotFound
+}
+
+func setupRouterWithScopes() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	apiKeys := &mockAPIKeys{keys: map[string]*models.APIKey{
+		utils.HashToken("bt_reader"): {ID: 1, UserID: 7, Scopes: "reports:read transactions:read"},
+	}}
+	r := gin.New()
+	r.Use(AuthMiddleware(&mockSessions{}, apiKeys))
+	ok := func(c *gin.Context) { uid, _ := c.Get("user_id"); c.JSON(http.StatusOK, gin.H{"user_id": uid}) }
+	r.GET("/transactions", RequireScope("transactions"), ok)
+	r.POST("/transactions", RequireScope("transactions"), ok)
+	r.GET("/budgets", RequireScope("budgets"), ok)
+	r.GET("/profile", SessionOnly(), ok)
+	return r
+}
+
+func TestAuthMiddleware_APIKeyScopes(t *testing.T) {
+	r := setupRouterWithScopes()
+	token, err := utils.GenerateToken(7, "jane@example.com", 1, time.Minute)
+	if err != nil { t.Fatalf("generate token: %v", err) }
+
+	cases := []struct{ bearer, method, path string; want int }{
+		{"bt_reader", http.MethodGet, "/transactions", http.StatusOK},
+		{"bt_reader", http.MethodPost, "/transactions", http.StatusForbidden},
+		{"bt_reader", http.MethodGet, "/budgets", http.StatusForbidden},
+		{"bt_reader", http.MethodGet, "/profile", http.StatusForbidden},
+		{"bt_unknown", http.MethodGet, "/transactions", http.StatusUnauthorized},
+		// access tokens aren't limited by scopes
+		{token, http.MethodPost, "/transactions", http.StatusOK},
+		{token, http.MethodGet, "/profile", http.StatusOK},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(c.method, c.path, nil)
+		req.Header.Set("Authorization", "Bearer "+c.bearer)
+		r.ServeHTTP(rec, req)
+		if rec.Code != c.want { t.Errorf("%s %s with %.10s: expected %d, got %d, body=%s", c.method, c.path, c.bearer, c.want, rec.Code, rec.Body.String()) }
+	}
+}
@@ -1,15 +1,23 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
 	"github.com/aditherevenger/Budget-Tracker-API/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware accepts access tokens whose session is still active, so logging out or a detected
+// refresh token replay locks out the session's access tokens before they expire. API keys are accepted
+// as bearer tokens too; RequireScope and SessionOnly decide which routes they reach.
+func AuthMiddleware(sessions repository.SessionRepository, apiKeys repository.APIKeyRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -25,17 +33,50 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(tokenString, models.APIKeyPrefix) {
+			key, err := apiKeys.Use(utils.HashToken(tokenString), time.Now())
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+				c.Abort()
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+
+			c.Set("user_id", key.UserID)
+			c.Set("api_key_id", key.ID)
+			c.Set("api_key_scopes", strings.Fields(key.Scopes))
+			c.Next()
+			return
+		}
+
 		claims, err := utils.ValidateToken(tokenString)
-		if err != nil {
+		if err != nil || claims.SessionID == 0 {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
+		active, err := sessions.IsActive(claims.SessionID, claims.UserID, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
 		fmt.Printf("Set user_id in context: value=%v, type=%T\n", claims.UserID, claims.UserID)
 
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
+		c.Set("session_id", claims.SessionID)
 		c.Next()
 	}
 }
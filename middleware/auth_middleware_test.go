@@ -4,15 +4,31 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
 	"github.com/aditherevenger/Budget-Tracker-API/utils"
 	"github.com/gin-gonic/gin"
 )
 
+// mockSessions treats every session as active except the revoked ones
+type mockSessions struct {
+	repository.SessionRepository
+	revoked map[uint]bool
+}
+
+func (m *mockSessions) IsActive(sessionID uint, userID uint, now time.Time) (bool, error) {
+	return !m.revoked[sessionID], nil
+}
+
 func setupRouterWithAuth() *gin.Engine {
+	return setupRouterWithSessions(&mockSessions{})
+}
+
+func setupRouterWithSessions(sessions repository.SessionRepository) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.Use(AuthMiddleware())
+	r.Use(AuthMiddleware(sessions, &mockAPIKeys{}))
 	r.GET("/protected", func(c *gin.Context) {
 		uid, _ := c.Get("user_id")
 		email, _ := c.Get("user_email")
@@ -56,7 +72,7 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 func TestAuthMiddleware_ValidToken(t *testing.T) {
 	r := setupRouterWithAuth()
 	rec := httptest.NewRecorder()
-	token, err := utils.GenerateToken(99, "valid@example.com")
+	token, err := utils.GenerateToken(99, "valid@example.com", 1, time.Minute)
 	if err != nil { t.Fatalf("generate token: %v", err) }
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -65,3 +81,18 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 		t.Fatalf("expected 200, got %d, body=%s", rec.Code, rec.Body.String())
 	}
 }
+
+func TestAuthMiddleware_RevokedSession(t *testing.T) {
+	r := setupRouterWithSessions(&mockSessions{revoked: map[uint]bool{4: true}})
+	for sessionID, want := range map[uint]int{4: http.StatusUnauthorized, 5: http.StatusOK, 0: http.StatusUnauthorized} {
+		token, err := utils.GenerateToken(99, "valid@example.com", sessionID, time.Minute)
+		if err != nil { t.Fatalf("generate token: %v", err) }
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("session %d: expected %d, got %d, body=%s", sessionID, want, rec.Code, rec.Body.String())
+		}
+	}
+}
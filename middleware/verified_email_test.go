@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type mockUsers struct {
+	repository.UserRepository
+	users map[uint]*models.User
+}
+
+func (m *mockUsers) GetByID(id uint) (*models.User, error) { return m.users[id], nil }
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	users := &mockUsers{users: map[uint]*models.User{1: {ID: 1}, 2: {ID: 2, EmailVerifiedAt: &verifiedAt}}}
+
+	gin.SetMode(gin.TestMode)
+	for userID, want := range map[uint]int{1: http.StatusForbidden, 2: http.StatusOK} {
+		r := gin.New()
+		r.Use(func(c *gin.Context) { c.Set("user_id", userID) })
+		r.GET("/export", RequireVerifiedEmail(users, true), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+		if rec.Code != want {
+			t.Fatalf("user %d: expected %d, got %d, body=%s", userID, want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRequireVerifiedEmail_NotRequired(t *testing.T) {
+	// without a mailer nobody can confirm an address, so unverified users keep their exports
+	users := &mockUsers{users: map[uint]*models.User{1: {ID: 1}}}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", uint(1)) })
+	r.GET("/export", RequireVerifiedEmail(users, false), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d, body=%s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,145 @@
+package models
+
+import "time"
+
+const (
+	AccountArchiveFormat = "budget-tracker-account"
+	// AccountArchiveVersion is bumped whenever the archive layout changes in a way older readers cannot handle
+	AccountArchiveVersion = 1
+)
+
+// AccountArchiveManifest is stored as manifest.json at the root of the archive. Attachments lists the
+// paths of binary files stored under attachments/ in the same archive.
+type AccountArchiveManifest struct {
+	Format      string         `json:"format"`
+	Version     int            `json:"version"`
+	ExportedAt  time.Time      `json:"exported_at"`
+	Counts      map[string]int `json:"counts"`
+	Attachments []string       `json:"attachments"`
+}
+
+// AccountArchive is stored as account.json. Records keep the IDs they had on the exporting instance;
+// they are only used to link records inside the archive and are replaced on import.
+type AccountArchive struct {
+	Profile        ArchivedProfile         `json:"profile"`
+	Categories     []ArchivedCategory      `json:"categories"`
+	Transactions   []ArchivedTransaction   `json:"transactions"`
+	ImportMappings []ImportMapping         `json:"import_mappings"`
+	ImportBatches  []ImportBatch           `json:"import_batches"`
+	Assets         []ArchivedAsset         `json:"assets"`
+	NetWorth       []NetWorthSnapshot      `json:"networth_snapshots"`
+	Budgets        []ArchivedBudget        `json:"budgets"`
+	RecurringItems []ArchivedRecurringItem `json:"recurring_items"`
+	Bills          []ArchivedBill          `json:"bills"`
+	// Unsubscribe tokens and the delivery log are not exported; restored subscriptions get new tokens
+	ReportSubscriptions []ArchivedReportSubscription `json:"report_subscriptions"`
+}
+
+type ArchivedProfile struct {
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArchivedCategory struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Color       string     `json:"color"`
+	TaxClass    string     `json:"tax_class,omitempty"`
+	Fixed       bool       `json:"fixed,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ArchivedTransaction struct {
+	ID            uint            `json:"id"`
+	CategoryID    uint            `json:"category_id"`
+	Amount        float64         `json:"amount"`
+	Type          TransactionType `json:"type"`
+	Description   string          `json:"description"`
+	Date          time.Time       `json:"date"`
+	ImportBatchID *uint           `json:"import_batch_id,omitempty"`
+	ExternalID    string          `json:"external_id,omitempty"`
+	TaxClass      *string         `json:"tax_class,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type ArchivedAsset struct {
+	ID          uint                `json:"id"`
+	Name        string              `json:"name"`
+	Kind        AssetKind           `json:"kind"`
+	Description string              `json:"description"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Valuations  []ArchivedValuation `json:"valuations"`
+}
+
+type ArchivedValuation struct {
+	Value     float64   `json:"value"`
+	Date      time.Time `json:"date"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArchivedBudget struct {
+	ID         uint            `json:"id"`
+	CategoryID uint            `json:"category_id"`
+	Type       TransactionType `json:"type"`
+	Amount     float64         `json:"amount"`
+	StartMonth string          `json:"start_month"`
+	EndMonth   string          `json:"end_month"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type ArchivedRecurringItem struct {
+	ID          uint               `json:"id"`
+	CategoryID  uint               `json:"category_id"`
+	Type        TransactionType    `json:"type"`
+	Amount      float64            `json:"amount"`
+	Description string             `json:"description"`
+	Frequency   RecurringFrequency `json:"frequency"`
+	StartDate   time.Time          `json:"start_date"`
+	EndDate     *time.Time         `json:"end_date,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type ArchivedBill struct {
+	ID          uint       `json:"id"`
+	CategoryID  uint       `json:"category_id"`
+	Amount      float64    `json:"amount"`
+	Description string     `json:"description"`
+	DueDate     time.Time  `json:"due_date"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type ArchivedReportSubscription struct {
+	Frequency ReportFrequency `json:"frequency"`
+	Active    bool            `json:"active"`
+	NextRunAt time.Time       `json:"next_run_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AccountImportResult reports how many records of each kind were restored
+type AccountImportResult struct {
+	Version int            `json:"version"`
+	Counts  map[string]int `json:"counts"`
+}
+
+// Counts returns the number of records per section, as recorded in the manifest
+func (a *AccountArchive) Counts() map[string]int {
+	return map[string]int{
+		"categories":           len(a.Categories),
+		"transactions":         len(a.Transactions),
+		"import_mappings":      len(a.ImportMappings),
+		"import_batches":       len(a.ImportBatches),
+		"assets":               len(a.Assets),
+		"networth":             len(a.NetWorth),
+		"budgets":              len(a.Budgets),
+		"recurring_items":      len(a.RecurringItems),
+		"bills":                len(a.Bills),
+		"report_subscriptions": len(a.ReportSubscriptions),
+	}
+}
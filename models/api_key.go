@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// APIKeyPrefix starts every API key, which tells them apart from access tokens
+const APIKeyPrefix = "bt_"
+
+// APIKey lets scripts call the API without a password. Only the SHA-256 hash of the key is stored;
+// Prefix keeps its first characters so the user can recognise it. Scopes is a space-separated list.
+type APIKey struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"size:100;not null"`
+	Prefix     string `gorm:"size:20;not null"`
+	KeyHash    string `gorm:"size:64;not null;uniqueIndex"`
+	Scopes     string `gorm:"size:255;not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Scopes of API keys. Each area has a read scope for GET requests and a write scope for the rest.
+const (
+	ScopeCategoriesRead    = "categories:read"
+	ScopeCategoriesWrite   = "categories:write"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeReportsRead       = "reports:read"
+	ScopeBudgetsRead       = "budgets:read"
+	ScopeBudgetsWrite      = "budgets:write"
+	ScopeForecastRead      = "forecast:read"
+	ScopeForecastWrite     = "forecast:write"
+	ScopeNetWorthRead      = "networth:read"
+	ScopeNetWorthWrite     = "networth:write"
+	ScopeImportsRead       = "imports:read"
+	ScopeImportsWrite      = "imports:write"
+)
+
+// APIKeyScopes lists every scope a key can be given
+var APIKeyScopes = []string{
+	ScopeCategoriesRead, ScopeCategoriesWrite,
+	ScopeTransactionsRead, ScopeTransactionsWrite,
+	ScopeReportsRead,
+	ScopeBudgetsRead, ScopeBudgetsWrite,
+	ScopeForecastRead, ScopeForecastWrite,
+	ScopeNetWorthRead, ScopeNetWorthWrite,
+	ScopeImportsRead, ScopeImportsWrite,
+}
+
+// CreateAPIKeyRequest creates a key that never expires unless ExpiresInDays is set
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKey is the only response that holds the key itself
+type CreatedAPIKey struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Budget plans a monthly amount for one category and transaction type. StartMonth and EndMonth are
+// YYYY-MM and inclusive; an empty EndMonth keeps the budget running.
+type Budget struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	UserID     uint            `json:"user_id" gorm:"not null;index"`
+	CategoryID uint            `json:"category_id" gorm:"not null"`
+	Type       TransactionType `json:"type" gorm:"not null;default:expense"`
+	Amount     float64         `json:"amount" gorm:"not null"`
+	StartMonth string          `json:"start_month" gorm:"not null"`
+	EndMonth   string          `json:"end_month"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt  `json:"-" gorm:"index"`
+
+	// Relationships
+	Category Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+type CreateBudgetRequest struct {
+	CategoryID uint            `json:"category_id" binding:"required"`
+	Type       TransactionType `json:"type" binding:"omitempty,oneof=income expense"`
+	Amount     float64         `json:"amount" binding:"required,gt=0"`
+	StartMonth string          `json:"start_month" binding:"required"`
+	EndMonth   string          `json:"end_month"`
+}
+
+type UpdateBudgetRequest struct {
+	CategoryID *uint            `json:"category_id,omitempty"`
+	Type       *TransactionType `json:"type,omitempty" binding:"omitempty,oneof=income expense"`
+	Amount     *float64         `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	StartMonth *string          `json:"start_month,omitempty"`
+	EndMonth   *string          `json:"end_month,omitempty"`
+}
+
+// Covers reports whether the budget applies to month (YYYY-MM)
+func (b *Budget) Covers(month string) bool {
+	return b.StartMonth <= month && (b.EndMonth == "" || b.EndMonth >= month)
+}
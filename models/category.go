@@ -5,12 +5,17 @@ import (
 	"time"
 )
 
+// Category groups transactions. A non-empty TaxClass, e.g. "office" or "self-employment", marks its
+// transactions as tax-relevant; Fixed marks commitments such as rent or insurance as opposed to
+// discretionary spending.
 type Category struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	UserID      uint           `json:"user_id" gorm:"not null"`
 	Name        string         `json:"name" gorm:"not null"`
 	Description string         `json:"description"`
 	Color       string         `json:"color" gorm:"default:#007bff"`
+	TaxClass    string         `json:"tax_class"`
+	Fixed       bool           `json:"fixed" gorm:"not null;default:false"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -24,10 +29,14 @@ type CreateCategoryRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
 	Color       string `json:"color"`
+	TaxClass    string `json:"tax_class" binding:"max=50"`
+	Fixed       bool   `json:"fixed"`
 }
 
 type UpdateCategoryRequest struct {
 	Name        *string `json:"name,omitempty"`
 	Description *string `json:"description,omitempty"`
 	Color       *string `json:"color,omitempty"`
+	TaxClass    *string `json:"tax_class,omitempty" binding:"omitempty,max=50"`
+	Fixed       *bool   `json:"fixed,omitempty"`
 }
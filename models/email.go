@@ -0,0 +1,108 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportFrequency string
+
+const (
+	ReportWeekly  ReportFrequency = "weekly"
+	ReportMonthly ReportFrequency = "monthly"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+// ReportSubscription asks for a summary email every week or month. NextRunAt is the end of the next
+// period to report on; the unsubscribe token is embedded in every email and never leaves the server otherwise.
+type ReportSubscription struct {
+	ID               uint            `json:"id" gorm:"primaryKey"`
+	UserID           uint            `json:"user_id" gorm:"not null;uniqueIndex:idx_report_subscription_user_frequency"`
+	Frequency        ReportFrequency `json:"frequency" gorm:"not null;uniqueIndex:idx_report_subscription_user_frequency"`
+	Active           bool            `json:"active" gorm:"not null"`
+	UnsubscribeToken string          `json:"-" gorm:"not null;uniqueIndex"`
+	NextRunAt        time.Time       `json:"next_run_at" gorm:"not null;index"`
+	LastSentAt       *time.Time      `json:"last_sent_at"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// BeforeCreate issues the unsubscribe token
+func (s *ReportSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.UnsubscribeToken != "" {
+		return nil
+	}
+	token, err := NewUnsubscribeToken()
+	if err != nil {
+		return err
+	}
+	s.UnsubscribeToken = token
+	return nil
+}
+
+// NewUnsubscribeToken returns 32 random bytes, hex encoded
+func NewUnsubscribeToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ReportDelivery records one attempt to deliver a period's summary. There is at most one delivery per
+// subscription and period, which keeps concurrent job runs from sending the same email twice.
+type ReportDelivery struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint            `json:"subscription_id" gorm:"not null;uniqueIndex:idx_report_delivery_period"`
+	UserID         uint            `json:"user_id" gorm:"not null;index"`
+	Frequency      ReportFrequency `json:"frequency" gorm:"not null"`
+	PeriodStart    time.Time       `json:"period_start" gorm:"not null;uniqueIndex:idx_report_delivery_period"`
+	PeriodEnd      time.Time       `json:"period_end" gorm:"not null"`
+	Status         DeliveryStatus  `json:"status" gorm:"not null"`
+	Attempts       int             `json:"attempts"`
+	Error          string          `json:"error,omitempty"`
+	SentAt         *time.Time      `json:"sent_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type SubscribeReportRequest struct {
+	Frequency ReportFrequency `json:"frequency" binding:"required,oneof=weekly monthly"`
+}
+
+// PeriodStart returns the start of the reporting period containing t, in loc. Weeks start on Monday.
+func (f ReportFrequency) PeriodStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	if f == ReportWeekly {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+}
+
+// Next returns the start of the period after the one beginning at start, in loc
+func (f ReportFrequency) Next(start time.Time, loc *time.Location) time.Time {
+	start = start.In(loc)
+	if f == ReportWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// ReportRunResult counts what one run of the summary email job did
+type ReportRunResult struct {
+	Due     int `json:"due"`
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatXLSX   ExportFormat = "xlsx"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ExportColumns lists every column an export can contain, in the default order
+var ExportColumns = []string{
+	"id",
+	"date",
+	"type",
+	"category",
+	"description",
+	"amount",
+	"signed_amount",
+	"running_balance",
+}
+
+type ExportRequest struct {
+	TransactionFilter
+	Format  ExportFormat `form:"format" binding:"omitempty,oneof=csv xlsx ndjson"`
+	Columns string       `form:"columns"`
+}
+
+// ParseExportColumns turns a comma separated column list into a validated slice; an empty list selects every column
+func ParseExportColumns(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return ExportColumns, nil
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if !isExportColumn(name) {
+			return nil, fmt.Errorf("unknown export column %q", name)
+		}
+		seen[name] = true
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return ExportColumns, nil
+	}
+	return columns, nil
+}
+
+func isExportColumn(name string) bool {
+	for _, column := range ExportColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
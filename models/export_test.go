@@ -0,0 +1,10 @@
+package models
+
+import "testing"
+
+func TestParseExportColumns(t *testing.T) {
+	columns, err := ParseExportColumns(" Date, amount,date ")
+	if err != nil || len(columns) != 2 || columns[0] != "date" { t.Fatalf("unexpected columns %v (%v)", columns, err) }
+	if _, err := ParseExportColumns("date,balance"); err == nil { t.Fatalf("expected unknown column error") }
+	if columns, _ := ParseExportColumns(""); len(columns) != len(ExportColumns) { t.Fatalf("expected every column by default") }
+}
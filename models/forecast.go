@@ -0,0 +1,127 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type RecurringFrequency string
+
+const (
+	FrequencyWeekly    RecurringFrequency = "weekly"
+	FrequencyBiweekly  RecurringFrequency = "biweekly"
+	FrequencyMonthly   RecurringFrequency = "monthly"
+	FrequencyQuarterly RecurringFrequency = "quarterly"
+	FrequencyYearly    RecurringFrequency = "yearly"
+)
+
+// RecurringItem is an income or expense that repeats on a schedule, such as a salary or a subscription.
+// It occurs on StartDate and every Frequency after it, up to and including EndDate when set.
+type RecurringItem struct {
+	ID          uint               `json:"id" gorm:"primaryKey"`
+	UserID      uint               `json:"user_id" gorm:"not null;index"`
+	CategoryID  uint               `json:"category_id" gorm:"not null"`
+	Type        TransactionType    `json:"type" gorm:"not null"`
+	Amount      float64            `json:"amount" gorm:"not null"`
+	Description string             `json:"description"`
+	Frequency   RecurringFrequency `json:"frequency" gorm:"not null"`
+	StartDate   time.Time          `json:"start_date" gorm:"not null"`
+	EndDate     *time.Time         `json:"end_date,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt     `json:"-" gorm:"index"`
+}
+
+// Bill is a known one-off payment that is due on a date. Paid bills no longer count towards forecasts.
+type Bill struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"not null;index"`
+	CategoryID  uint           `json:"category_id" gorm:"not null"`
+	Amount      float64        `json:"amount" gorm:"not null"`
+	Description string         `json:"description"`
+	DueDate     time.Time      `json:"due_date" gorm:"not null"`
+	PaidAt      *time.Time     `json:"paid_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type CreateRecurringItemRequest struct {
+	CategoryID  uint               `json:"category_id" binding:"required"`
+	Type        TransactionType    `json:"type" binding:"required,oneof=income expense"`
+	Amount      float64            `json:"amount" binding:"required,gt=0"`
+	Description string             `json:"description"`
+	Frequency   RecurringFrequency `json:"frequency" binding:"required,oneof=weekly biweekly monthly quarterly yearly"`
+	StartDate   time.Time          `json:"start_date" binding:"required"`
+	EndDate     *time.Time         `json:"end_date,omitempty"`
+}
+
+type UpdateRecurringItemRequest struct {
+	CategoryID  *uint               `json:"category_id,omitempty"`
+	Type        *TransactionType    `json:"type,omitempty" binding:"omitempty,oneof=income expense"`
+	Amount      *float64            `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Description *string             `json:"description,omitempty"`
+	Frequency   *RecurringFrequency `json:"frequency,omitempty" binding:"omitempty,oneof=weekly biweekly monthly quarterly yearly"`
+	StartDate   *time.Time          `json:"start_date,omitempty"`
+	EndDate     *time.Time          `json:"end_date,omitempty"`
+	// ClearEndDate removes the end date so the item repeats indefinitely
+	ClearEndDate bool `json:"clear_end_date,omitempty"`
+}
+
+type CreateBillRequest struct {
+	CategoryID  uint      `json:"category_id" binding:"required"`
+	Amount      float64   `json:"amount" binding:"required,gt=0"`
+	Description string    `json:"description"`
+	DueDate     time.Time `json:"due_date" binding:"required"`
+}
+
+type UpdateBillRequest struct {
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	Amount      *float64   `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Description *string    `json:"description,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Paid        *bool      `json:"paid,omitempty"`
+}
+
+type ForecastRequest struct {
+	Days         int `form:"days" binding:"omitempty,min=1,max=365"`
+	LookbackDays int `form:"lookback_days" binding:"omitempty,min=7,max=730"`
+}
+
+// ForecastItem is a scheduled amount that lands on a forecast day
+type ForecastItem struct {
+	Source      string          `json:"source"`
+	ID          uint            `json:"id"`
+	CategoryID  uint            `json:"category_id"`
+	Type        TransactionType `json:"type"`
+	Amount      float64         `json:"amount"`
+	Description string          `json:"description"`
+}
+
+// ForecastDay is the projected end-of-day balance. Lower and Upper bound the balance within the
+// forecast's confidence level; they only widen with the uncertainty of the variable estimate.
+type ForecastDay struct {
+	Date      time.Time      `json:"date"`
+	Scheduled []ForecastItem `json:"scheduled"`
+	Variable  float64        `json:"variable"`
+	Balance   float64        `json:"balance"`
+	Lower     float64        `json:"lower"`
+	Upper     float64        `json:"upper"`
+}
+
+type Forecast struct {
+	AsOf            time.Time `json:"as_of"`
+	StartingBalance float64   `json:"starting_balance"`
+	Days            int       `json:"days"`
+	LookbackDays    int       `json:"lookback_days"`
+	Confidence      float64   `json:"confidence"`
+	// VariableDailyNet is the trailing average net flow per day of categories without a recurring item
+	VariableDailyNet    float64       `json:"variable_daily_net"`
+	VariableDailyStdDev float64       `json:"variable_daily_stddev"`
+	Daily               []ForecastDay `json:"daily"`
+	FirstNegativeDate   *time.Time    `json:"first_negative_date"`
+	// FirstNegativeLowerDate is when the pessimistic band first drops below zero
+	FirstNegativeLowerDate *time.Time `json:"first_negative_lower_date"`
+	LowestBalance          float64    `json:"lowest_balance"`
+	LowestBalanceDate      *time.Time `json:"lowest_balance_date"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type ImportSource string
+
+const (
+	ImportSourceCSV ImportSource = "csv"
+	ImportSourceOFX ImportSource = "ofx"
+	ImportSourceQIF ImportSource = "qif"
+)
+
+type ImportBatchStatus string
+
+const (
+	ImportBatchCommitted ImportBatchStatus = "committed"
+	ImportBatchUndone    ImportBatchStatus = "undone"
+)
+
+// Amount sign conventions for mappings that use a single amount column
+const (
+	AmountSignNegativeExpense = "negative_expense"
+	AmountSignPositiveExpense = "positive_expense"
+)
+
+type ImportMapping struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	UserID            uint           `json:"user_id" gorm:"not null;index"`
+	Name              string         `json:"name" gorm:"not null"`
+	Delimiter         string         `json:"delimiter"`
+	HasHeader         bool           `json:"has_header"`
+	DateColumn        string         `json:"date_column" gorm:"not null"`
+	DateFormat        string         `json:"date_format"`
+	AmountColumn      string         `json:"amount_column"`
+	DebitColumn       string         `json:"debit_column"`
+	CreditColumn      string         `json:"credit_column"`
+	AmountSign        string         `json:"amount_sign"`
+	DecimalComma      bool           `json:"decimal_comma"`
+	DescriptionColumn string         `json:"description_column"`
+	CategoryColumn    string         `json:"category_column"`
+	DefaultCategoryID uint           `json:"default_category_id"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type ImportBatch struct {
+	ID           uint              `json:"id" gorm:"primaryKey"`
+	UserID       uint              `json:"user_id" gorm:"not null;index"`
+	Source       ImportSource      `json:"source" gorm:"not null"`
+	FileName     string            `json:"file_name"`
+	MappingID    *uint             `json:"mapping_id,omitempty"`
+	RowCount     int               `json:"row_count"`
+	SkippedCount int               `json:"skipped_count"`
+	Status       ImportBatchStatus `json:"status" gorm:"not null"`
+	UndoneAt     *time.Time        `json:"undone_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+type CreateImportMappingRequest struct {
+	Name              string `json:"name" binding:"required"`
+	Delimiter         string `json:"delimiter" binding:"omitempty,len=1"`
+	HasHeader         bool   `json:"has_header"`
+	DateColumn        string `json:"date_column" binding:"required"`
+	DateFormat        string `json:"date_format"`
+	AmountColumn      string `json:"amount_column"`
+	DebitColumn       string `json:"debit_column"`
+	CreditColumn      string `json:"credit_column"`
+	AmountSign        string `json:"amount_sign" binding:"omitempty,oneof=negative_expense positive_expense"`
+	DecimalComma      bool   `json:"decimal_comma"`
+	DescriptionColumn string `json:"description_column"`
+	CategoryColumn    string `json:"category_column"`
+	DefaultCategoryID uint   `json:"default_category_id"`
+}
+
+// ImportRow is a single parsed line of an import file, before or after commit
+type ImportRow struct {
+	Row          int             `json:"row"`
+	Date         time.Time       `json:"date"`
+	Amount       float64         `json:"amount"`
+	Type         TransactionType `json:"type"`
+	Description  string          `json:"description"`
+	CategoryName string          `json:"category_name,omitempty"`
+	CategoryID   uint            `json:"category_id,omitempty"`
+	ExternalID   string          `json:"external_id,omitempty"`
+	Duplicate    bool            `json:"duplicate"`
+	Errors       []string        `json:"errors,omitempty"`
+}
+
+type ImportPreview struct {
+	Rows       []ImportRow `json:"rows"`
+	Total      int         `json:"total"`
+	Valid      int         `json:"valid"`
+	Invalid    int         `json:"invalid"`
+	Duplicates int         `json:"duplicates"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestImportPreviewJSON_ContainsExpectedKeys(t *testing.T) {
+	p := ImportPreview{Rows: []ImportRow{{Row: 2, Amount: 10, Type: Expense, Errors: []string{"invalid date"}}}, Total: 1, Invalid: 1}
+	b, err := json.Marshal(p)
+	if err != nil { t.Fatalf("marshal error: %v", err) }
+	js := string(b)
+	for _, key := range []string{"\"rows\"","\"row\"","\"duplicate\"","\"errors\"","\"total\"","\"valid\"","\"invalid\"","\"duplicates\""} {
+		if !strings.Contains(js, key) {
+			t.Fatalf("expected JSON to contain %s, got: %s", key, js)
+		}
+	}
+}
+
+func TestImportMappingJSON_OmitsDeletedAt(t *testing.T) {
+	m := ImportMapping{ID: 1, UserID: 2, Name: "Bank", DateColumn: "Date", AmountColumn: "Amount"}
+	b, err := json.Marshal(m)
+	if err != nil { t.Fatalf("marshal error: %v", err) }
+	js := string(b)
+	if strings.Contains(js, "deleted_at") {
+		t.Fatalf("expected deleted_at to be omitted, got: %s", js)
+	}
+	for _, key := range []string{"\"date_column\"","\"amount_column\"","\"amount_sign\"","\"default_category_id\""} {
+		if !strings.Contains(js, key) {
+			t.Fatalf("expected JSON to contain %s, got: %s", key, js)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+type AnomalyBasis string
+
+const (
+	AnomalyByCategory AnomalyBasis = "category"
+	AnomalyByPayee    AnomalyBasis = "payee"
+)
+
+// TransactionAnomaly explains why an expense was flagged: its amount lies Score robust standard
+// deviations above Typical, the median of Samples earlier expenses in the same category or to the same payee.
+type TransactionAnomaly struct {
+	Basis   AnomalyBasis `json:"basis"`
+	Typical float64      `json:"typical"`
+	Score   float64      `json:"score"`
+	Samples int          `json:"samples"`
+}
+
+// CategorySpike flags a month whose spending in a category is far above the months before it
+type CategorySpike struct {
+	CategoryID   uint    `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Month        string  `json:"month"`
+	Total        float64 `json:"total"`
+	Typical      float64 `json:"typical"`
+	Score        float64 `json:"score"`
+	Samples      int     `json:"samples"`
+}
+
+type AnomalyReport struct {
+	StartDate      time.Time       `json:"start_date"`
+	EndDate        time.Time       `json:"end_date"`
+	LookbackMonths int             `json:"lookback_months"`
+	Threshold      float64         `json:"threshold"`
+	Transactions   []Transaction   `json:"transactions"`
+	CategorySpikes []CategorySpike `json:"category_spikes"`
+}
+
+type KPITrend string
+
+const (
+	TrendUp   KPITrend = "up"
+	TrendDown KPITrend = "down"
+	TrendFlat KPITrend = "flat"
+)
+
+// KPIValue holds an indicator for the period and the one before it. Values are null when they cannot be
+// computed, e.g. a savings rate without income; Trend is then omitted. Improved tells whether the move
+// is good news, as a rising savings rate is but a rising burn rate is not.
+type KPIValue struct {
+	Current  *float64 `json:"current"`
+	Previous *float64 `json:"previous"`
+	Delta    *float64 `json:"delta"`
+	Trend    KPITrend `json:"trend,omitempty"`
+	Improved *bool    `json:"improved,omitempty"`
+}
+
+// FinancialKPIs summarizes financial health over a period. Income and expense are the GetSummary totals;
+// percentages are 0-100 and monthly figures use an average month of 30.44 days.
+type FinancialKPIs struct {
+	Current              ReportPeriod `json:"current"`
+	Previous             ReportPeriod `json:"previous"`
+	Days                 int          `json:"days"`
+	Income               float64      `json:"income"`
+	Expense              float64      `json:"expense"`
+	FixedExpense         float64      `json:"fixed_expense"`
+	DiscretionaryExpense float64      `json:"discretionary_expense"`
+	CashBalance          float64      `json:"cash_balance"`
+	SavingsRate          KPIValue     `json:"savings_rate"`
+	AverageDailySpend    KPIValue     `json:"average_daily_spend"`
+	BurnRate             KPIValue     `json:"burn_rate"`
+	RunwayMonths         KPIValue     `json:"runway_months"`
+	FixedRatio           KPIValue     `json:"fixed_ratio"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// LoginAttempt counts the consecutive failed logins of one key: an account ("account:<email>") or a
+// client address ("ip:<address>"). Logins under the key are refused until LockedUntil.
+type LoginAttempt struct {
+	Key           string `gorm:"primaryKey;size:330"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+	UpdatedAt     time.Time
+}
+
+// SecurityEvent records something that happened to an account's security, such as a lockout
+type SecurityEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Type      string    `json:"type" gorm:"size:30;not null"`
+	IPAddress string    `json:"ip_address,omitempty" gorm:"size:45"`
+	Detail    string    `json:"detail,omitempty" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Types of security events
+const (
+	SecurityAccountLocked   = "account_locked"
+	SecurityAccountUnlocked = "account_unlocked"
+)
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// MFAFactor is a user's TOTP authenticator. It only counts once ConfirmedAt is set, which happens when
+// the user proves the authenticator works by entering a first code.
+type MFAFactor struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"not null;uniqueIndex"`
+	// Secret is the base32 TOTP key; codes can't be checked without it, so it can't be hashed
+	Secret      string `gorm:"size:64;not null"`
+	ConfirmedAt *time.Time
+	// LastUsedStep is the time step of the last accepted code, so a code can't be replayed
+	LastUsedStep int64 `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecoveryCode stands in for the authenticator once. Only the SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// MFAEnrollment is shown while setting up the authenticator: scan the QR code of OTPAuthURI or type in
+// Secret, then confirm with a code
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCodeURL  string `json:"qr_code_url"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFADisableRequest needs the password and either a current code or a recovery code
+type MFADisableRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFAVerifyRequest completes a login that returned a challenge, with a code or a recovery code
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFAChallenge is returned by login instead of tokens when the user has two-factor authentication
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// LoginResult holds either Tokens or, when a second factor is needed, Challenge
+type LoginResult struct {
+	Tokens    *AuthTokens
+	Challenge *MFAChallenge
+	User      *UserResponse
+}
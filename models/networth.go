@@ -0,0 +1,97 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type AssetKind string
+
+const (
+	AssetKindAsset     AssetKind = "asset"
+	AssetKindLiability AssetKind = "liability"
+)
+
+// Asset is something tracked outside of transactions, such as a house, a car or a loan.
+// Its value over time comes from dated valuations; liabilities are stored as positive values.
+type Asset struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"not null;index"`
+	Name        string         `json:"name" gorm:"not null"`
+	Kind        AssetKind      `json:"kind" gorm:"not null"`
+	Description string         `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Valuations []AssetValuation `json:"valuations,omitempty" gorm:"foreignKey:AssetID"`
+}
+
+type AssetValuation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	AssetID   uint      `json:"asset_id" gorm:"not null;index"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Value     float64   `json:"value" gorm:"not null"`
+	Date      time.Time `json:"date" gorm:"not null"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NetWorthSnapshot freezes the net worth of a closed period so later edits do not rewrite history
+type NetWorthSnapshot struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	UserID      uint            `json:"user_id" gorm:"not null;uniqueIndex:idx_networth_snapshot"`
+	Interval    SummaryInterval `json:"interval" gorm:"column:period_interval;not null;uniqueIndex:idx_networth_snapshot"`
+	Period      string          `json:"period" gorm:"not null;uniqueIndex:idx_networth_snapshot"`
+	Cash        float64         `json:"cash"`
+	Assets      float64         `json:"assets"`
+	Liabilities float64         `json:"liabilities"`
+	NetWorth    float64         `json:"net_worth"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type CreateAssetRequest struct {
+	Name        string    `json:"name" binding:"required"`
+	Kind        AssetKind `json:"kind" binding:"required,oneof=asset liability"`
+	Description string    `json:"description"`
+	// Value and Date optionally record the first valuation
+	Value *float64   `json:"value,omitempty" binding:"omitempty,gte=0"`
+	Date  *time.Time `json:"date,omitempty"`
+}
+
+type UpdateAssetRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Kind        *AssetKind `json:"kind,omitempty" binding:"omitempty,oneof=asset liability"`
+	Description *string    `json:"description,omitempty"`
+}
+
+type CreateValuationRequest struct {
+	Value *float64  `json:"value" binding:"required,gte=0"`
+	Date  time.Time `json:"date" binding:"required"`
+	Note  string    `json:"note"`
+}
+
+type NetWorthRequest struct {
+	Interval  SummaryInterval `form:"interval" binding:"omitempty,oneof=day week month year"`
+	StartDate string          `form:"start_date"`
+	EndDate   string          `form:"end_date"`
+}
+
+// NetWorthPoint is the net worth at the end of a period. Cash is the running balance of all transactions.
+// Snapshot is true when the figures come from a stored snapshot rather than a fresh calculation.
+type NetWorthPoint struct {
+	Period      string  `json:"period"`
+	Cash        float64 `json:"cash"`
+	Assets      float64 `json:"assets"`
+	Liabilities float64 `json:"liabilities"`
+	NetWorth    float64 `json:"net_worth"`
+	Snapshot    bool    `json:"snapshot"`
+}
+
+type NetWorthSeries struct {
+	Interval  SummaryInterval `json:"interval"`
+	StartDate time.Time       `json:"start_date"`
+	EndDate   time.Time       `json:"end_date"`
+	Points    []NetWorthPoint `json:"points"`
+}
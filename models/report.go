@@ -0,0 +1,212 @@
+package models
+
+import "time"
+
+// ReportPeriodRequest is the date range accepted by the report endpoints; dates are YYYY-MM-DD or RFC 3339
+type ReportPeriodRequest struct {
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+}
+
+// CategoryTotal is one row of the per-category aggregation for a single transaction type
+type CategoryTotal struct {
+	CategoryID    uint            `json:"category_id"`
+	CategoryName  string          `json:"category_name"`
+	CategoryColor string          `json:"category_color"`
+	Type          TransactionType `json:"type"`
+	Fixed         bool            `json:"fixed"`
+	Total         float64         `json:"total"`
+	Count         int             `json:"count"`
+}
+
+type CategoryBreakdownRow struct {
+	CategoryID    uint    `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	CategoryColor string  `json:"category_color"`
+	Total         float64 `json:"total"`
+	Count         int     `json:"count"`
+	Share         float64 `json:"share"`
+	Average       float64 `json:"average"`
+}
+
+// CategoryBreakdownSection groups the rows of one transaction type, largest total first.
+// Share is each row's percentage of the section total.
+type CategoryBreakdownSection struct {
+	Total      float64                `json:"total"`
+	Count      int                    `json:"count"`
+	Categories []CategoryBreakdownRow `json:"categories"`
+}
+
+type CategoryBreakdown struct {
+	StartDate time.Time                `json:"start_date"`
+	EndDate   time.Time                `json:"end_date"`
+	Income    CategoryBreakdownSection `json:"income"`
+	Expense   CategoryBreakdownSection `json:"expense"`
+}
+
+// CompareRequest selects two periods. Explicit ranges win; otherwise period (month, quarter or year) compares
+// the period containing date (default today) with the one before it.
+type CompareRequest struct {
+	Period        string `form:"period" binding:"omitempty,oneof=month quarter year"`
+	Date          string `form:"date"`
+	CurrentStart  string `form:"current_start"`
+	CurrentEnd    string `form:"current_end"`
+	PreviousStart string `form:"previous_start"`
+	PreviousEnd   string `form:"previous_end"`
+}
+
+type ReportPeriod struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// ComparisonValue holds a figure for both periods. DeltaPercent is relative to the previous period and
+// is null when the previous value is zero.
+type ComparisonValue struct {
+	Current      float64  `json:"current"`
+	Previous     float64  `json:"previous"`
+	Delta        float64  `json:"delta"`
+	DeltaPercent *float64 `json:"delta_percent"`
+}
+
+type CategoryComparison struct {
+	CategoryID    uint            `json:"category_id"`
+	CategoryName  string          `json:"category_name"`
+	CategoryColor string          `json:"category_color"`
+	Type          TransactionType `json:"type"`
+	ComparisonValue
+}
+
+type PeriodComparison struct {
+	Current       ReportPeriod         `json:"current"`
+	Previous      ReportPeriod         `json:"previous"`
+	Income        ComparisonValue      `json:"income"`
+	Expense       ComparisonValue      `json:"expense"`
+	Net           ComparisonValue      `json:"net"`
+	Categories    []CategoryComparison `json:"categories"`
+	BiggestMovers []CategoryComparison `json:"biggest_movers"`
+}
+
+type VarianceFormat string
+
+const (
+	VarianceFormatJSON VarianceFormat = "json"
+	VarianceFormatCSV  VarianceFormat = "csv"
+)
+
+// VarianceRequest selects the month (YYYY-MM) of a budget variance report; year to date runs from January
+type VarianceRequest struct {
+	Month  string         `form:"month"`
+	Format VarianceFormat `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// VarianceValues compares planned with actual. Variance is actual minus planned, so a positive variance
+// means more was spent (or earned) than planned; VariancePercent is nil when nothing was planned.
+type VarianceValues struct {
+	Planned         float64  `json:"planned"`
+	Actual          float64  `json:"actual"`
+	Variance        float64  `json:"variance"`
+	VariancePercent *float64 `json:"variance_percent"`
+	Over            bool     `json:"over"`
+	Under           bool     `json:"under"`
+}
+
+type VarianceRow struct {
+	CategoryID   uint            `json:"category_id"`
+	CategoryName string          `json:"category_name"`
+	Type         TransactionType `json:"type"`
+	Budgeted     bool            `json:"budgeted"`
+	Month        VarianceValues  `json:"month"`
+	YearToDate   VarianceValues  `json:"year_to_date"`
+}
+
+type VarianceTotal struct {
+	Month      VarianceValues `json:"month"`
+	YearToDate VarianceValues `json:"year_to_date"`
+}
+
+type VarianceReport struct {
+	Month      string        `json:"month"`
+	StartDate  time.Time     `json:"start_date"`
+	EndDate    time.Time     `json:"end_date"`
+	YearStart  time.Time     `json:"year_start"`
+	Categories []VarianceRow `json:"categories"`
+	Income     VarianceTotal `json:"income"`
+	Expense    VarianceTotal `json:"expense"`
+}
+
+// TaxReportRequest selects a tax year by the calendar year it starts in and its first day (MM-DD,
+// default 01-01): year 2025 with start 04-06 runs from 6 April 2025 to 5 April 2026
+type TaxReportRequest struct {
+	Year   int            `form:"year" binding:"omitempty,min=1900,max=9999"`
+	Start  string         `form:"start"`
+	Format VarianceFormat `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// TaxItem is a tax-relevant transaction with its effective tax class
+type TaxItem struct {
+	TransactionID uint            `json:"transaction_id"`
+	Date          time.Time       `json:"date"`
+	Description   string          `json:"description"`
+	CategoryName  string          `json:"category_name"`
+	TaxClass      string          `json:"tax_class"`
+	Type          TransactionType `json:"type"`
+	Amount        float64         `json:"amount"`
+}
+
+type TaxClassTotal struct {
+	TaxClass string  `json:"tax_class"`
+	Total    float64 `json:"total"`
+	Count    int     `json:"count"`
+}
+
+// TaxReport totals taxable income and deductible expenses by tax class; EndDate is exclusive
+type TaxReport struct {
+	TaxYear            string          `json:"tax_year"`
+	StartDate          time.Time       `json:"start_date"`
+	EndDate            time.Time       `json:"end_date"`
+	TaxableIncome      []TaxClassTotal `json:"taxable_income"`
+	DeductibleExpenses []TaxClassTotal `json:"deductible_expenses"`
+	TotalTaxableIncome float64         `json:"total_taxable_income"`
+	TotalDeductible    float64         `json:"total_deductible"`
+	Items              []TaxItem       `json:"items"`
+}
+
+type StatementRequest struct {
+	Month string `form:"month"`
+}
+
+type StatementCategory struct {
+	CategoryName string          `json:"category_name"`
+	Type         TransactionType `json:"type"`
+	Count        int             `json:"count"`
+	Total        float64         `json:"total"`
+	Share        float64         `json:"share"`
+}
+
+// StatementLine is one transaction on a statement; Amount is signed and Balance is the running balance after it
+type StatementLine struct {
+	Date         time.Time       `json:"date"`
+	Description  string          `json:"description"`
+	CategoryName string          `json:"category_name"`
+	Type         TransactionType `json:"type"`
+	Amount       float64         `json:"amount"`
+	Balance      float64         `json:"balance"`
+}
+
+// Statement holds everything printed on a monthly statement
+type Statement struct {
+	Month          string              `json:"month"`
+	StartDate      time.Time           `json:"start_date"`
+	EndDate        time.Time           `json:"end_date"`
+	AccountHolder  string              `json:"account_holder"`
+	Email          string              `json:"email"`
+	GeneratedAt    time.Time           `json:"generated_at"`
+	OpeningBalance float64             `json:"opening_balance"`
+	Income         float64             `json:"income"`
+	Expense        float64             `json:"expense"`
+	Net            float64             `json:"net"`
+	ClosingBalance float64             `json:"closing_balance"`
+	Categories     []StatementCategory `json:"categories"`
+	Transactions   []StatementLine     `json:"transactions"`
+}
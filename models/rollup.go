@@ -0,0 +1,13 @@
+package models
+
+// MonthlyRollup holds the total and number of a user's transactions per UTC month (YYYY-MM), category
+// and type. It is kept in step with the transactions table on every write, so summaries and reports
+// read whole months from here instead of scanning transactions.
+type MonthlyRollup struct {
+	UserID     uint            `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Month      string          `json:"month" gorm:"primaryKey;size:7"`
+	CategoryID uint            `json:"category_id" gorm:"primaryKey;autoIncrement:false"`
+	Type       TransactionType `json:"type" gorm:"primaryKey"`
+	Total      float64         `json:"total" gorm:"not null"`
+	Count      int             `json:"count" gorm:"not null"`
+}
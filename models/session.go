@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Session is one login. Access tokens carry the session ID, so revoking the session shuts out every
+// access token issued for it. ExpiresAt moves forward with each refresh.
+type Session struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"not null"`
+	LastUsedAt    time.Time  `json:"last_used_at" gorm:"not null"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RevokedReason string     `json:"revoked_reason,omitempty" gorm:"size:20"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Reasons a session was revoked
+const (
+	SessionLogout        = "logout"
+	SessionReuse         = "reuse"
+	SessionPasswordReset = "password_reset"
+)
+
+// RefreshToken is one link in a session's rotation chain. Only the SHA-256 hash of the token is stored.
+// A token is used once; presenting a used token again revokes the whole session.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	SessionID uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	Session Session `gorm:"foreignKey:SessionID"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest revokes the session of the refresh token, or every session of its user with All
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	All          bool   `json:"all"`
+}
+
+// AuthTokens is returned by login and refresh. Token is the access token, valid for ExpiresIn seconds.
+type AuthTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
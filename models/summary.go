@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+type SummaryInterval string
+
+const (
+	IntervalDay   SummaryInterval = "day"
+	IntervalWeek  SummaryInterval = "week"
+	IntervalMonth SummaryInterval = "month"
+	IntervalYear  SummaryInterval = "year"
+)
+
+type SummaryRequest struct {
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+}
+
+// Summary holds the income and expense totals of [StartDate, EndDate). The bounds are the resolved
+// instants in Timezone; a bound that was not given is omitted and leaves that side open.
+type Summary struct {
+	StartDate    *time.Time `json:"start_date,omitempty"`
+	EndDate      *time.Time `json:"end_date,omitempty"`
+	Timezone     string     `json:"timezone"`
+	TotalIncome  float64    `json:"total_income"`
+	TotalExpense float64    `json:"total_expense"`
+	NetBalance   float64    `json:"net_balance"`
+}
+
+type TimeSeriesRequest struct {
+	Interval  SummaryInterval `form:"interval" binding:"omitempty,oneof=day week month year"`
+	StartDate string          `form:"start_date"`
+	EndDate   string          `form:"end_date"`
+}
+
+// SummaryBucket holds the totals of one interval. Period is the first day of the bucket (weeks start on Monday).
+type SummaryBucket struct {
+	Period  string  `json:"period"`
+	Income  float64 `json:"income"`
+	Expense float64 `json:"expense"`
+	Net     float64 `json:"net"`
+	Count   int     `json:"count"`
+}
+
+type TimeSeriesSummary struct {
+	Interval  SummaryInterval `json:"interval"`
+	StartDate time.Time       `json:"start_date"`
+	EndDate   time.Time       `json:"end_date"`
+	Buckets   []SummaryBucket `json:"buckets"`
+}
@@ -12,21 +12,29 @@ const (
 	Expense TransactionType = "expense"
 )
 
+// Transaction is a single income or expense. TaxClass overrides the category's tax class: nil inherits
+// it, an empty string marks the transaction as not tax-relevant.
 type Transaction struct {
-	ID          uint            `json:"id" gorm:"primaryKey"`
-	UserID      uint            `json:"user_id" gorm:"not null"`
-	CategoryID  uint            `json:"category_id" gorm:"not null"`
-	Amount      float64         `json:"amount" gorm:"not null"`
-	Type        TransactionType `json:"type" gorm:"not null"`
-	Description string          `json:"description"`
-	Date        time.Time       `json:"date" gorm:"not null"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt  `json:"-" gorm:"index"`
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	UserID        uint            `json:"user_id" gorm:"not null"`
+	CategoryID    uint            `json:"category_id" gorm:"not null"`
+	Amount        float64         `json:"amount" gorm:"not null"`
+	Type          TransactionType `json:"type" gorm:"not null"`
+	Description   string          `json:"description"`
+	Date          time.Time       `json:"date" gorm:"not null"`
+	ImportBatchID *uint           `json:"import_batch_id,omitempty" gorm:"index"`
+	ExternalID    string          `json:"external_id,omitempty" gorm:"index"`
+	TaxClass      *string         `json:"tax_class,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt  `json:"-" gorm:"index"`
 
 	// Relationships
 	User     User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Category Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+
+	// Anomaly is set on listed expenses that are unusual for the user's history; it is not stored
+	Anomaly *TransactionAnomaly `json:"anomaly,omitempty" gorm:"-"`
 }
 
 type CreateTransactionRequest struct {
@@ -35,21 +43,30 @@ type CreateTransactionRequest struct {
 	Type        TransactionType `json:"type" binding:"required,oneof=income expense"`
 	Description string          `json:"description"`
 	Date        time.Time       `json:"date" binding:"required"`
+	TaxClass    *string         `json:"tax_class,omitempty" binding:"omitempty,max=50"`
 }
 
+// UpdateTransactionRequest changes only the fields that are set. ClearTaxClass drops the tax class
+// override so the transaction inherits its category's class again.
 type UpdateTransactionRequest struct {
-	CategoryID  *uint            `json:"category_id,omitempty"`
-	Amount      *float64         `json:"amount,omitempty" binding:"omitempty,gt=0"`
-	Type        *TransactionType `json:"type,omitempty" binding:"omitempty,oneof=income expense"`
-	Description *string          `json:"description,omitempty"`
-	Date        *time.Time       `json:"date,omitempty"`
+	CategoryID    *uint            `json:"category_id,omitempty"`
+	Amount        *float64         `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Type          *TransactionType `json:"type,omitempty" binding:"omitempty,oneof=income expense"`
+	Description   *string          `json:"description,omitempty"`
+	Date          *time.Time       `json:"date,omitempty"`
+	TaxClass      *string          `json:"tax_class,omitempty" binding:"omitempty,max=50"`
+	ClearTaxClass bool             `json:"clear_tax_class,omitempty"`
 }
 
+// TransactionFilter selects transactions between StartDate and EndDate, both inclusive. Requests bind the
+// raw start_date and end_date to From and To, which the service resolves in the user's time zone.
 type TransactionFilter struct {
 	Type       TransactionType `form:"type"`
 	CategoryID uint            `form:"category_id"`
-	StartDate  time.Time       `form:"start_date"`
-	EndDate    time.Time       `form:"end_date"`
+	From       string          `form:"start_date"`
+	To         string          `form:"end_date"`
+	StartDate  time.Time       `form:"-"`
+	EndDate    time.Time       `form:"-"`
 	Limit      int             `form:"limit"`
 	Offset     int             `form:"offset"`
 }
@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// AccountRepository reads and writes everything a user owns as a single unit
+type AccountRepository interface {
+	Export(userID uint) (*models.AccountArchive, error)
+	HasData(userID uint) (bool, error)
+	Restore(userID uint, archive *models.AccountArchive) error
+}
+
+type accountRepository struct{}
+
+func NewAccountRepository() AccountRepository {
+	return &accountRepository{}
+}
+
+func (r *accountRepository) Export(userID uint) (*models.AccountArchive, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	archive := &models.AccountArchive{
+		Profile: models.ArchivedProfile{Email: user.Email, FirstName: user.FirstName, LastName: user.LastName, Timezone: user.Timezone, CreatedAt: user.CreatedAt},
+	}
+
+	// Deleted categories are kept when live records still point at them, so the archive stays self-consistent
+	var categories []models.Category
+	query := database.DB.Unscoped().Where("user_id = ?", userID)
+	referenced := database.DB.Where("deleted_at IS NULL")
+	for _, model := range []interface{}{&models.Transaction{}, &models.Budget{}, &models.RecurringItem{}, &models.Bill{}} {
+		referenced = referenced.Or("id IN (?)", database.DB.Model(model).Select("category_id").Where("user_id = ?", userID))
+	}
+	err := query.Where(referenced).Order("id").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		archived := models.ArchivedCategory{ID: category.ID, Name: category.Name, Description: category.Description, Color: category.Color, TaxClass: category.TaxClass, Fixed: category.Fixed, CreatedAt: category.CreatedAt}
+		if category.DeletedAt.Valid {
+			deletedAt := category.DeletedAt.Time
+			archived.DeletedAt = &deletedAt
+		}
+		archive.Categories = append(archive.Categories, archived)
+	}
+
+	var transactions []models.Transaction
+	if err := database.DB.Where("user_id = ?", userID).Order("date, id").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		archive.Transactions = append(archive.Transactions, models.ArchivedTransaction{
+			ID:            transaction.ID,
+			CategoryID:    transaction.CategoryID,
+			Amount:        transaction.Amount,
+			Type:          transaction.Type,
+			Description:   transaction.Description,
+			Date:          transaction.Date,
+			ImportBatchID: transaction.ImportBatchID,
+			ExternalID:    transaction.ExternalID,
+			TaxClass:      transaction.TaxClass,
+			CreatedAt:     transaction.CreatedAt,
+		})
+	}
+
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&archive.ImportMappings).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&archive.ImportBatches).Error; err != nil {
+		return nil, err
+	}
+
+	var assets []models.Asset
+	err = database.DB.Preload("Valuations", func(db *gorm.DB) *gorm.DB { return db.Order("date, id") }).
+		Where("user_id = ?", userID).Order("id").Find(&assets).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, asset := range assets {
+		archived := models.ArchivedAsset{ID: asset.ID, Name: asset.Name, Kind: asset.Kind, Description: asset.Description, CreatedAt: asset.CreatedAt, Valuations: []models.ArchivedValuation{}}
+		for _, valuation := range asset.Valuations {
+			archived.Valuations = append(archived.Valuations, models.ArchivedValuation{Value: valuation.Value, Date: valuation.Date, Note: valuation.Note, CreatedAt: valuation.CreatedAt})
+		}
+		archive.Assets = append(archive.Assets, archived)
+	}
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&archive.NetWorth).Error; err != nil {
+		return nil, err
+	}
+
+	var budgets []models.Budget
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	for _, budget := range budgets {
+		archive.Budgets = append(archive.Budgets, models.ArchivedBudget{
+			ID:         budget.ID,
+			CategoryID: budget.CategoryID,
+			Type:       budget.Type,
+			Amount:     budget.Amount,
+			StartMonth: budget.StartMonth,
+			EndMonth:   budget.EndMonth,
+			CreatedAt:  budget.CreatedAt,
+		})
+	}
+
+	var items []models.RecurringItem
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		archive.RecurringItems = append(archive.RecurringItems, models.ArchivedRecurringItem{
+			ID:          item.ID,
+			CategoryID:  item.CategoryID,
+			Type:        item.Type,
+			Amount:      item.Amount,
+			Description: item.Description,
+			Frequency:   item.Frequency,
+			StartDate:   item.StartDate,
+			EndDate:     item.EndDate,
+			CreatedAt:   item.CreatedAt,
+		})
+	}
+
+	var bills []models.Bill
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&bills).Error; err != nil {
+		return nil, err
+	}
+	for _, bill := range bills {
+		archive.Bills = append(archive.Bills, models.ArchivedBill{
+			ID:          bill.ID,
+			CategoryID:  bill.CategoryID,
+			Amount:      bill.Amount,
+			Description: bill.Description,
+			DueDate:     bill.DueDate,
+			PaidAt:      bill.PaidAt,
+			CreatedAt:   bill.CreatedAt,
+		})
+	}
+
+	var subscriptions []models.ReportSubscription
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	for _, subscription := range subscriptions {
+		archive.ReportSubscriptions = append(archive.ReportSubscriptions, models.ArchivedReportSubscription{
+			Frequency: subscription.Frequency,
+			Active:    subscription.Active,
+			NextRunAt: subscription.NextRunAt,
+			CreatedAt: subscription.CreatedAt,
+		})
+	}
+
+	return archive, nil
+}
+
+// HasData reports whether the user owns any records that a restore would collide with
+func (r *accountRepository) HasData(userID uint) (bool, error) {
+	for _, model := range []interface{}{&models.Category{}, &models.Transaction{}, &models.ImportMapping{}, &models.ImportBatch{}, &models.Asset{}, &models.NetWorthSnapshot{}, &models.Budget{}, &models.RecurringItem{}, &models.Bill{}, &models.ReportSubscription{}} {
+		var count int64
+		if err := database.DB.Model(model).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Restore inserts every record of the archive for the user in a single database transaction.
+// Archive IDs are only used to resolve references; each record gets a fresh ID on insert.
+func (r *accountRepository) Restore(userID uint, archive *models.AccountArchive) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		profile := map[string]interface{}{
+			"first_name": archive.Profile.FirstName,
+			"last_name":  archive.Profile.LastName,
+		}
+		// archives written before time zones were added keep the account's current zone
+		if archive.Profile.Timezone != "" {
+			profile["timezone"] = archive.Profile.Timezone
+		}
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(profile).Error
+		if err != nil {
+			return err
+		}
+
+		categoryIDs := make(map[uint]uint)
+		for _, archived := range archive.Categories {
+			category := models.Category{UserID: userID, Name: archived.Name, Description: archived.Description, Color: archived.Color, TaxClass: archived.TaxClass, Fixed: archived.Fixed, CreatedAt: archived.CreatedAt}
+			if archived.DeletedAt != nil {
+				category.DeletedAt = gorm.DeletedAt{Time: *archived.DeletedAt, Valid: true}
+			}
+			if err := tx.Create(&category).Error; err != nil {
+				return err
+			}
+			categoryIDs[archived.ID] = category.ID
+		}
+
+		mappingIDs := make(map[uint]uint)
+		for _, mapping := range archive.ImportMappings {
+			oldID := mapping.ID
+			mapping.ID = 0
+			mapping.UserID = userID
+			mapping.DefaultCategoryID = categoryIDs[mapping.DefaultCategoryID]
+			if err := tx.Create(&mapping).Error; err != nil {
+				return err
+			}
+			mappingIDs[oldID] = mapping.ID
+		}
+
+		batchIDs := make(map[uint]uint)
+		for _, batch := range archive.ImportBatches {
+			oldID := batch.ID
+			batch.ID = 0
+			batch.UserID = userID
+			if batch.MappingID != nil {
+				if newID, ok := mappingIDs[*batch.MappingID]; ok {
+					batch.MappingID = &newID
+				} else {
+					batch.MappingID = nil
+				}
+			}
+			if err := tx.Create(&batch).Error; err != nil {
+				return err
+			}
+			batchIDs[oldID] = batch.ID
+		}
+
+		for _, archived := range archive.Assets {
+			asset := models.Asset{UserID: userID, Name: archived.Name, Kind: archived.Kind, Description: archived.Description, CreatedAt: archived.CreatedAt}
+			if err := tx.Create(&asset).Error; err != nil {
+				return err
+			}
+			for _, valuation := range archived.Valuations {
+				record := models.AssetValuation{AssetID: asset.ID, UserID: userID, Value: valuation.Value, Date: valuation.Date, Note: valuation.Note, CreatedAt: valuation.CreatedAt}
+				if err := tx.Create(&record).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, snapshot := range archive.NetWorth {
+			snapshot.ID = 0
+			snapshot.UserID = userID
+			if err := tx.Create(&snapshot).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, archived := range archive.Budgets {
+			categoryID, ok := categoryIDs[archived.CategoryID]
+			if !ok {
+				return fmt.Errorf("budget %d references unknown category %d", archived.ID, archived.CategoryID)
+			}
+			budget := models.Budget{
+				UserID:     userID,
+				CategoryID: categoryID,
+				Type:       archived.Type,
+				Amount:     archived.Amount,
+				StartMonth: archived.StartMonth,
+				EndMonth:   archived.EndMonth,
+				CreatedAt:  archived.CreatedAt,
+			}
+			if err := tx.Create(&budget).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, archived := range archive.RecurringItems {
+			categoryID, ok := categoryIDs[archived.CategoryID]
+			if !ok {
+				return fmt.Errorf("recurring item %d references unknown category %d", archived.ID, archived.CategoryID)
+			}
+			item := models.RecurringItem{
+				UserID:      userID,
+				CategoryID:  categoryID,
+				Type:        archived.Type,
+				Amount:      archived.Amount,
+				Description: archived.Description,
+				Frequency:   archived.Frequency,
+				StartDate:   archived.StartDate,
+				EndDate:     archived.EndDate,
+				CreatedAt:   archived.CreatedAt,
+			}
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, archived := range archive.Bills {
+			categoryID, ok := categoryIDs[archived.CategoryID]
+			if !ok {
+				return fmt.Errorf("bill %d references unknown category %d", archived.ID, archived.CategoryID)
+			}
+			bill := models.Bill{
+				UserID:      userID,
+				CategoryID:  categoryID,
+				Amount:      archived.Amount,
+				Description: archived.Description,
+				DueDate:     archived.DueDate,
+				PaidAt:      archived.PaidAt,
+				CreatedAt:   archived.CreatedAt,
+			}
+			if err := tx.Create(&bill).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, archived := range archive.ReportSubscriptions {
+			subscription := models.ReportSubscription{
+				UserID:    userID,
+				Frequency: archived.Frequency,
+				Active:    archived.Active,
+				NextRunAt: archived.NextRunAt,
+				CreatedAt: archived.CreatedAt,
+			}
+			if err := tx.Create(&subscription).Error; err != nil {
+				return err
+			}
+		}
+
+		transactions := make([]models.Transaction, 0, len(archive.Transactions))
+		for _, archived := range archive.Transactions {
+			categoryID, ok := categoryIDs[archived.CategoryID]
+			if !ok {
+				return fmt.Errorf("transaction %d references unknown category %d", archived.ID, archived.CategoryID)
+			}
+			transaction := models.Transaction{
+				UserID:      userID,
+				CategoryID:  categoryID,
+				Amount:      archived.Amount,
+				Type:        archived.Type,
+				Description: archived.Description,
+				Date:        archived.Date,
+				ExternalID:  archived.ExternalID,
+				TaxClass:    archived.TaxClass,
+				CreatedAt:   archived.CreatedAt,
+			}
+			if archived.ImportBatchID != nil {
+				if newID, ok := batchIDs[*archived.ImportBatchID]; ok {
+					transaction.ImportBatchID = &newID
+				}
+			}
+			transactions = append(transactions, transaction)
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(transactions, 100).Error; err != nil {
+			return err
+		}
+		delta := rollupDelta{}
+		for i := range transactions {
+			delta.add(&transactions[i], 1)
+		}
+		return delta.apply(tx)
+	})
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestAccountRepository_ExportAndRestore(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewAccountRepository()
+	urepo := NewUserRepository()
+	crepo := NewCategoryRepository()
+	trepo := NewTransactionRepository()
+
+	src := &models.User{Email: "src@example.com", Password: "hash", FirstName: "Source", LastName: "User", Timezone: "Europe/Berlin"}
+	if err := urepo.Create(src); err != nil { t.Fatalf("create user: %v", err) }
+	food := &models.Category{UserID: src.ID, Name: "Food", Color: "#ff0000", TaxClass: "business", Fixed: true}
+	old := &models.Category{UserID: src.ID, Name: "Old"}
+	unused := &models.Category{UserID: src.ID, Name: "Unused"}
+	for _, c := range []*models.Category{food, old, unused} {
+		if err := crepo.Create(c); err != nil { t.Fatalf("create category: %v", err) }
+	}
+	mapping := &models.ImportMapping{UserID: src.ID, Name: "Bank", DateColumn: "1", AmountColumn: "2", DefaultCategoryID: food.ID}
+	if err := NewImportRepository().CreateMapping(mapping); err != nil { t.Fatalf("create mapping: %v", err) }
+	batch := &models.ImportBatch{UserID: src.ID, Source: models.ImportSourceCSV, MappingID: &mapping.ID, Status: models.ImportBatchCommitted, RowCount: 1}
+	imported := []models.Transaction{{CategoryID: food.ID, Amount: 12, Type: models.Expense, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), ExternalID: "ofx:1:A"}}
+	if err := trepo.CreateImport(batch, imported); err != nil { t.Fatalf("create import: %v", err) }
+	salary := "salary"
+	if err := trepo.Create(&models.Transaction{UserID: src.ID, CategoryID: old.ID, Amount: 5, Type: models.Income, Date: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC), TaxClass: &salary}); err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	if err := crepo.Delete(old.ID, src.ID); err != nil { t.Fatalf("delete category: %v", err) }
+	if err := crepo.Delete(unused.ID, src.ID); err != nil { t.Fatalf("delete category: %v", err) }
+	nwrepo := NewNetWorthRepository()
+	car := &models.Asset{UserID: src.ID, Name: "Car", Kind: models.AssetKindAsset}
+	if err := nwrepo.CreateAsset(car); err != nil { t.Fatalf("create asset: %v", err) }
+	if err := nwrepo.CreateValuation(&models.AssetValuation{AssetID: car.ID, UserID: src.ID, Value: 9000, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)}); err != nil { t.Fatalf("create valuation: %v", err) }
+	if err := NewBudgetRepository().Create(&models.Budget{UserID: src.ID, CategoryID: food.ID, Type: models.Expense, Amount: 300, StartMonth: "2025-01"}); err != nil { t.Fatalf("create budget: %v", err) }
+	srepo := NewScheduleRepository()
+	if err := srepo.CreateRecurring(&models.RecurringItem{UserID: src.ID, CategoryID: food.ID, Type: models.Expense, Amount: 50, Frequency: models.FrequencyWeekly, StartDate: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)}); err != nil { t.Fatalf("create recurring: %v", err) }
+	if err := srepo.CreateBill(&models.Bill{UserID: src.ID, CategoryID: old.ID, Amount: 80, DueDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)}); err != nil { t.Fatalf("create bill: %v", err) }
+	if err := nwrepo.SaveSnapshots([]models.NetWorthSnapshot{{UserID: src.ID, Interval: models.IntervalMonth, Period: "2025-08-01", NetWorth: 9000}}); err != nil { t.Fatalf("save snapshot: %v", err) }
+	erepo := NewReportEmailRepository()
+	subscription := &models.ReportSubscription{UserID: src.ID, Frequency: models.ReportWeekly, Active: true, NextRunAt: time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)}
+	if err := erepo.CreateSubscription(subscription); err != nil { t.Fatalf("create subscription: %v", err) }
+
+	archive, err := repo.Export(src.ID)
+	if err != nil { t.Fatalf("export: %v", err) }
+	if len(archive.Categories) != 2 || archive.Categories[1].DeletedAt == nil { t.Fatalf("expected live and referenced deleted categories only, got %+v", archive.Categories) }
+	if len(archive.Transactions) != 2 || len(archive.ImportMappings) != 1 || len(archive.ImportBatches) != 1 || len(archive.Assets) != 1 || len(archive.NetWorth) != 1 || len(archive.Budgets) != 1 || len(archive.RecurringItems) != 1 || len(archive.Bills) != 1 || len(archive.ReportSubscriptions) != 1 { t.Fatalf("unexpected archive counts %v", archive.Counts()) }
+
+	// occupy a few IDs so the restored records cannot keep their original ones by accident
+	if err := crepo.Create(&models.Category{UserID: 99, Name: "Filler"}); err != nil { t.Fatalf("create filler: %v", err) }
+
+	dst := &models.User{Email: "dst@example.com", Password: "hash", FirstName: "New", LastName: "Account"}
+	if err := urepo.Create(dst); err != nil { t.Fatalf("create user: %v", err) }
+	if hasData, err := repo.HasData(dst.ID); err != nil || hasData { t.Fatalf("expected fresh account to be empty: %v %v", hasData, err) }
+	if err := repo.Restore(dst.ID, archive); err != nil { t.Fatalf("restore: %v", err) }
+	if hasData, _ := repo.HasData(dst.ID); !hasData { t.Fatalf("expected restored account to have data") }
+
+	profile, _ := urepo.GetByID(dst.ID)
+	if profile.FirstName != "Source" || profile.Timezone != "Europe/Berlin" || profile.Email != "dst@example.com" { t.Fatalf("expected names restored and email kept, got %+v", profile) }
+
+	categories, _ := crepo.GetByUserID(dst.ID, nil)
+	if len(categories) != 1 || categories[0].Name != "Food" || categories[0].ID == food.ID || categories[0].TaxClass != "business" || !categories[0].Fixed { t.Fatalf("unexpected restored categories %+v", categories) }
+
+	var restored []models.Transaction
+	database.DB.Where("user_id = ?", dst.ID).Order("date").Find(&restored)
+	if len(restored) != 2 { t.Fatalf("expected 2 restored transactions, got %d", len(restored)) }
+	if restored[0].CategoryID != categories[0].ID || restored[0].ImportBatchID == nil || restored[0].ExternalID != "ofx:1:A" {
+		t.Fatalf("expected remapped category and batch, got %+v", restored[0])
+	}
+	if summary, _ := trepo.GetSummary(dst.ID, time.Time{}, time.Time{}); summary.TotalIncome != 5.0 || summary.TotalExpense != 12.0 { t.Fatalf("expected restored transactions in the rollup, got %v", summary) }
+	if restored[0].TaxClass != nil || restored[1].TaxClass == nil || *restored[1].TaxClass != "salary" { t.Fatalf("expected tax class override restored, got %+v", restored[1]) }
+
+	batches, _ := NewImportRepository().GetBatchesByUserID(dst.ID)
+	mappings, _ := NewImportRepository().GetMappingsByUserID(dst.ID)
+	if len(batches) != 1 || *restored[0].ImportBatchID != batches[0].ID || batches[0].MappingID == nil || *batches[0].MappingID != mappings[0].ID {
+		t.Fatalf("expected batch and mapping links remapped: %+v %+v", batches, mappings)
+	}
+	if mappings[0].DefaultCategoryID != categories[0].ID { t.Fatalf("expected mapping default category remapped, got %d", mappings[0].DefaultCategoryID) }
+
+	assets, _ := nwrepo.GetAssetsByUserID(dst.ID)
+	if len(assets) != 1 || assets[0].ID == car.ID || len(assets[0].Valuations) != 1 || assets[0].Valuations[0].AssetID != assets[0].ID { t.Fatalf("expected asset and valuation restored under new IDs, got %+v", assets) }
+	if snapshots, _ := nwrepo.GetSnapshots(dst.ID, models.IntervalMonth, []string{"2025-08-01"}); len(snapshots) != 1 { t.Fatalf("expected net worth snapshot restored") }
+	if budgets, _ := NewBudgetRepository().GetByUserID(dst.ID); len(budgets) != 1 || budgets[0].CategoryID != categories[0].ID { t.Fatalf("expected budget restored against the new category, got %+v", budgets) }
+	if items, _ := srepo.GetRecurringByUserID(dst.ID); len(items) != 1 || items[0].CategoryID != categories[0].ID { t.Fatalf("expected recurring item restored against the new category, got %+v", items) }
+	if bills, _ := srepo.GetBillsByUserID(dst.ID, true); len(bills) != 1 || bills[0].CategoryID == old.ID { t.Fatalf("expected bill restored against the restored deleted category, got %+v", bills) }
+	if subscriptions, _ := erepo.GetSubscriptionsByUserID(dst.ID); len(subscriptions) != 1 || !subscriptions[0].Active || subscriptions[0].UnsubscribeToken == "" || subscriptions[0].UnsubscribeToken == subscription.UnsubscribeToken { t.Fatalf("expected subscription restored with a fresh token, got %+v", subscriptions) }
+}
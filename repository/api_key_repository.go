@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// apiKeyTouchInterval is how stale last_used_at may get before a request updates it, so a busy script
+// doesn't write on every call
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	GetByUserID(userID uint) ([]models.APIKey, error)
+	Delete(id uint, userID uint) (bool, error)
+	DeleteByUserID(userID uint) error
+	Use(keyHash string, now time.Time) (*models.APIKey, error)
+}
+
+type apiKeyRepository struct{}
+
+func NewAPIKeyRepository() APIKeyRepository {
+	return &apiKeyRepository{}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	return database.DB.Create(key).Error
+}
+
+func (r *apiKeyRepository) GetByUserID(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := database.DB.Where("user_id = ?", userID).Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// Delete revokes the key and reports whether the user had it
+func (r *apiKeyRepository) Delete(id uint, userID uint) (bool, error) {
+	result := database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteByUserID revokes every key of the user, as a password reset does
+func (r *apiKeyRepository) DeleteByUserID(userID uint) error {
+	return database.DB.Where("user_id = ?", userID).Delete(&models.APIKey{}).Error
+}
+
+// Use returns the unexpired key with the hash and records that it was used. Unknown and expired keys
+// yield gorm.ErrRecordNotFound.
+func (r *apiKeyRepository) Use(keyHash string, now time.Time) (*models.APIKey, error) {
+	var key models.APIKey
+	err := database.DB.Where("key_hash = ? AND (expires_at IS NULL OR expires_at > ?)", keyHash, now.UTC()).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		err := database.DB.Model(&models.APIKey{}).Where("id = ?", key.ID).Update("last_used_at", now.UTC()).Error
+		if err != nil {
+			return nil, err
+		}
+		used := now.UTC()
+		key.LastUsedAt = &used
+	}
+	return &key, nil
+}
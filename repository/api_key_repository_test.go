@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestAPIKeyRepository_Use(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewAPIKeyRepository()
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+
+	active := &models.APIKey{UserID: 1, Name: "sync", Prefix: "bt_aaaaaaaa", KeyHash: "active", Scopes: "reports:read"}
+	expired := &models.APIKey{UserID: 1, Name: "old", Prefix: "bt_bbbbbbbb", KeyHash: "expired", Scopes: "reports:read", ExpiresAt: &past}
+	for _, key := range []*models.APIKey{active, expired} {
+		if err := repo.Create(key); err != nil { t.Fatalf("create: %v", err) }
+	}
+
+	key, err := repo.Use("active", now)
+	if err != nil || key.ID != active.ID || key.LastUsedAt == nil { t.Fatalf("use: %v %+v", err, key) }
+	// a second request within the minute doesn't move last_used_at
+	if key, _ = repo.Use("active", now.Add(30*time.Second)); !key.LastUsedAt.Equal(now) { t.Fatalf("expected last_used_at to stay, got %v", key.LastUsedAt) }
+	if key, _ = repo.Use("active", now.Add(2*time.Minute)); !key.LastUsedAt.Equal(now.Add(2*time.Minute)) { t.Fatalf("expected last_used_at to move, got %v", key.LastUsedAt) }
+
+	if _, err := repo.Use("expired", now); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected an expired key to be refused, got %v", err) }
+	if _, err := repo.Use("unknown", now); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected an unknown key to be refused, got %v", err) }
+
+	if deleted, err := repo.Delete(active.ID, 2); err != nil || deleted { t.Fatalf("expected another user's delete to miss, got %v %v", deleted, err) }
+	if deleted, err := repo.Delete(active.ID, 1); err != nil || !deleted { t.Fatalf("delete: %v %v", deleted, err) }
+	if _, err := repo.Use("active", now); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected a deleted key to be refused, got %v", err) }
+	if keys, _ := repo.GetByUserID(1); len(keys) != 1 || keys[0].ID != expired.ID { t.Fatalf("unexpected keys %+v", keys) }
+}
+
+func TestAPIKeyRepository_DeleteByUserID(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewAPIKeyRepository()
+	for _, key := range []*models.APIKey{
+		{UserID: 1, Name: "a", Prefix: "bt_aaaaaaaa", KeyHash: "a", Scopes: "reports:read"},
+		{UserID: 1, Name: "b", Prefix: "bt_bbbbbbbb", KeyHash: "b", Scopes: "reports:read"},
+		{UserID: 2, Name: "c", Prefix: "bt_cccccccc", KeyHash: "c", Scopes: "reports:read"},
+	} {
+		if err := repo.Create(key); err != nil { t.Fatalf("create: %v", err) }
+	}
+
+	if err := repo.DeleteByUserID(1); err != nil { t.Fatalf("delete by user: %v", err) }
+	if keys, _ := repo.GetByUserID(1); len(keys) != 0 { t.Fatalf("expected the user's keys to be gone, got %+v", keys) }
+	if _, err := repo.Use("a", time.Now()); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected a revoked key to be refused, got %v", err) }
+	if _, err := repo.Use("c", time.Now()); err != nil { t.Fatalf("expected another user's key to keep working, got %v", err) }
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type BudgetRepository interface {
+	Create(budget *models.Budget) error
+	GetByUserID(userID uint) ([]models.Budget, error)
+	GetByID(id uint, userID uint) (*models.Budget, error)
+	Update(budget *models.Budget) error
+	Delete(id uint, userID uint) error
+}
+
+type budgetRepository struct{}
+
+func NewBudgetRepository() BudgetRepository {
+	return &budgetRepository{}
+}
+
+func (r *budgetRepository) Create(budget *models.Budget) error {
+	return database.DB.Create(budget).Error
+}
+
+// GetByUserID returns the user's budgets oldest first. Categories are loaded even when deleted so
+// reports can still label budgets that outlived their category.
+func (r *budgetRepository) GetByUserID(userID uint) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := database.DB.Preload("Category", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("user_id = ?", userID).Order("start_month, id").Find(&budgets).Error
+	return budgets, err
+}
+
+func (r *budgetRepository) GetByID(id uint, userID uint) (*models.Budget, error) {
+	var budget models.Budget
+	err := database.DB.Preload("Category").Where("id = ? AND user_id = ?", id, userID).First(&budget).Error
+	return &budget, err
+}
+
+func (r *budgetRepository) Update(budget *models.Budget) error {
+	return database.DB.Omit("Category").Save(budget).Error
+}
+
+func (r *budgetRepository) Delete(id uint, userID uint) error {
+	return database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Budget{}).Error
+}
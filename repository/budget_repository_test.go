@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestBudgetRepository_CRUD(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewBudgetRepository()
+	crepo := NewCategoryRepository()
+
+	food := &models.Category{UserID: 1, Name: "Food"}
+	if err := crepo.Create(food); err != nil { t.Fatalf("create category: %v", err) }
+	later := &models.Budget{UserID: 1, CategoryID: food.ID, Type: models.Expense, Amount: 400, StartMonth: "2025-08"}
+	earlier := &models.Budget{UserID: 1, CategoryID: food.ID, Type: models.Expense, Amount: 300, StartMonth: "2025-01", EndMonth: "2025-07"}
+	for _, b := range []*models.Budget{later, earlier, {UserID: 2, CategoryID: food.ID, Type: models.Expense, Amount: 1, StartMonth: "2025-01"}} {
+		if err := repo.Create(b); err != nil { t.Fatalf("create budget: %v", err) }
+	}
+	if err := crepo.Delete(food.ID, 1); err != nil { t.Fatalf("delete category: %v", err) }
+
+	budgets, err := repo.GetByUserID(1)
+	if err != nil || len(budgets) != 2 { t.Fatalf("list: %v len=%d", err, len(budgets)) }
+	if budgets[0].ID != earlier.ID || budgets[0].Category.Name != "Food" { t.Fatalf("expected oldest start first with deleted category loaded, got %+v", budgets[0]) }
+
+	if _, err := repo.GetByID(later.ID, 2); err == nil { t.Fatalf("expected budget scoped to owner") }
+	later.Amount = 450
+	if err := repo.Update(later); err != nil { t.Fatalf("update: %v", err) }
+	reloaded, err := repo.GetByID(later.ID, 1)
+	if err != nil || reloaded.Amount != 450 { t.Fatalf("unexpected budget after update %v %+v", err, reloaded) }
+
+	if err := repo.Delete(later.ID, 1); err != nil { t.Fatalf("delete: %v", err) }
+	if budgets, _ := repo.GetByUserID(1); len(budgets) != 1 { t.Fatalf("expected 1 budget left, got %d", len(budgets)) }
+}
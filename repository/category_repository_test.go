@@ -16,7 +16,7 @@ func setupTestDBCategory(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open sqlite in-memory: %v", err)
 	}
-	if err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Transaction{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Transaction{}, &models.MonthlyRollup{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	database.DB = db
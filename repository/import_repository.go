@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type ImportRepository interface {
+	CreateMapping(mapping *models.ImportMapping) error
+	GetMappingsByUserID(userID uint) ([]models.ImportMapping, error)
+	GetMappingByID(id uint, userID uint) (*models.ImportMapping, error)
+	DeleteMapping(id uint, userID uint) error
+	GetBatchesByUserID(userID uint) ([]models.ImportBatch, error)
+	GetBatchByID(id uint, userID uint) (*models.ImportBatch, error)
+}
+
+type importRepository struct{}
+
+func NewImportRepository() ImportRepository {
+	return &importRepository{}
+}
+
+func (r *importRepository) CreateMapping(mapping *models.ImportMapping) error {
+	return database.DB.Create(mapping).Error
+}
+
+func (r *importRepository) GetMappingsByUserID(userID uint) ([]models.ImportMapping, error) {
+	var mappings []models.ImportMapping
+	err := database.DB.Where("user_id = ?", userID).Order("name").Find(&mappings).Error
+	return mappings, err
+}
+
+func (r *importRepository) GetMappingByID(id uint, userID uint) (*models.ImportMapping, error) {
+	var mapping models.ImportMapping
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&mapping).Error
+	return &mapping, err
+}
+
+func (r *importRepository) DeleteMapping(id uint, userID uint) error {
+	return database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.ImportMapping{}).Error
+}
+
+func (r *importRepository) GetBatchesByUserID(userID uint) ([]models.ImportBatch, error) {
+	var batches []models.ImportBatch
+	err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&batches).Error
+	return batches, err
+}
+
+func (r *importRepository) GetBatchByID(id uint, userID uint) (*models.ImportBatch, error) {
+	var batch models.ImportBatch
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&batch).Error
+	return &batch, err
+}
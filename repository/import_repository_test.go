@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func setupTestDBImport(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Transaction{}, &models.ImportMapping{}, &models.ImportBatch{}, &models.Asset{}, &models.AssetValuation{}, &models.NetWorthSnapshot{}, &models.Budget{}, &models.RecurringItem{}, &models.Bill{}, &models.ReportSubscription{}, &models.ReportDelivery{}, &models.MonthlyRollup{}, &models.Session{}, &models.RefreshToken{}, &models.OneTimeToken{}, &models.MFAFactor{}, &models.RecoveryCode{}, &models.LoginAttempt{}, &models.SecurityEvent{}, &models.APIKey{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	database.DB = db
+	return db
+}
+
+func TestImportRepository_Mappings(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewImportRepository()
+
+	m := &models.ImportMapping{UserID: 1, Name: "Checking", DateColumn: "Date", AmountColumn: "Amount"}
+	if err := repo.CreateMapping(m); err != nil { t.Fatalf("create mapping: %v", err) }
+	if err := repo.CreateMapping(&models.ImportMapping{UserID: 2, Name: "Other user", DateColumn: "1", AmountColumn: "2"}); err != nil {
+		t.Fatalf("create mapping: %v", err)
+	}
+
+	mappings, err := repo.GetMappingsByUserID(1)
+	if err != nil || len(mappings) != 1 { t.Fatalf("list mappings: %v len=%d", err, len(mappings)) }
+
+	if _, err := repo.GetMappingByID(m.ID, 2); err == nil { t.Fatalf("expected mapping to be scoped to its owner") }
+
+	if err := repo.DeleteMapping(m.ID, 1); err != nil { t.Fatalf("delete mapping: %v", err) }
+	if _, err := repo.GetMappingByID(m.ID, 1); err == nil { t.Fatalf("expected deleted mapping to be gone") }
+}
+
+func TestTransactionRepository_CreateImport_And_Undo(t *testing.T) {
+	setupTestDBImport(t)
+	trepo := NewTransactionRepository()
+	irepo := NewImportRepository()
+
+	u := &models.User{Email: "importer@example.com", Password: "hash", FirstName: "Im", LastName: "Porter"}
+	if err := NewUserRepository().Create(u); err != nil { t.Fatalf("create user: %v", err) }
+	cat := &models.Category{UserID: u.ID, Name: "Food"}
+	if err := NewCategoryRepository().Create(cat); err != nil { t.Fatalf("create category: %v", err) }
+	manual := &models.Transaction{UserID: u.ID, CategoryID: cat.ID, Amount: 1, Type: models.Expense, Date: time.Now().UTC()}
+	if err := trepo.Create(manual); err != nil { t.Fatalf("create manual tx: %v", err) }
+
+	batch := &models.ImportBatch{UserID: u.ID, Source: models.ImportSourceCSV, FileName: "bank.csv", Status: models.ImportBatchCommitted, RowCount: 2}
+	txs := []models.Transaction{
+		{CategoryID: cat.ID, Amount: 10, Type: models.Expense, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{CategoryID: cat.ID, Amount: 20, Type: models.Income, Date: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := trepo.CreateImport(batch, txs); err != nil { t.Fatalf("create import: %v", err) }
+	if batch.ID == 0 || txs[0].ImportBatchID == nil || *txs[0].ImportBatchID != batch.ID { t.Fatalf("expected transactions linked to batch %+v", txs[0]) }
+
+	items, _ := trepo.GetByUserID(u.ID, &models.TransactionFilter{})
+	if len(items) != 3 { t.Fatalf("expected 3 transactions after import, got %d", len(items)) }
+
+	if err := trepo.UndoImport(batch.ID, u.ID+1); err == nil { t.Fatalf("expected undo to be scoped to owner") }
+	if err := trepo.UndoImport(batch.ID, u.ID); err != nil { t.Fatalf("undo import: %v", err) }
+	if err := trepo.UndoImport(batch.ID, u.ID); err == nil { t.Fatalf("expected second undo to fail") }
+
+	items, _ = trepo.GetByUserID(u.ID, &models.TransactionFilter{})
+	if len(items) != 1 || items[0].ID != manual.ID { t.Fatalf("expected only the manual transaction to remain, got %+v", items) }
+
+	reloaded, err := irepo.GetBatchByID(batch.ID, u.ID)
+	if err != nil { t.Fatalf("get batch: %v", err) }
+	if reloaded.Status != models.ImportBatchUndone || reloaded.UndoneAt == nil { t.Fatalf("expected batch marked undone, got %+v", reloaded) }
+
+	batches, err := irepo.GetBatchesByUserID(u.ID)
+	if err != nil || len(batches) != 1 { t.Fatalf("list batches: %v len=%d", err, len(batches)) }
+}
+
+func TestTransactionRepository_FindExternalIDs(t *testing.T) {
+	setupTestDBImport(t)
+	trepo := NewTransactionRepository()
+
+	if err := trepo.Create(&models.Transaction{UserID: 1, CategoryID: 1, Amount: 5, Type: models.Expense, Date: time.Now().UTC(), ExternalID: "ofx:1:A"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := trepo.Create(&models.Transaction{UserID: 2, CategoryID: 1, Amount: 5, Type: models.Expense, Date: time.Now().UTC(), ExternalID: "ofx:1:B"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	found, err := trepo.FindExternalIDs(1, []string{"ofx:1:A", "ofx:1:B", "ofx:1:C"})
+	if err != nil { t.Fatalf("find: %v", err) }
+	if len(found) != 1 || found[0] != "ofx:1:A" { t.Fatalf("expected only the user's own id, got %v", found) }
+
+	found, err = trepo.FindExternalIDs(1, nil)
+	if err != nil || len(found) != 0 { t.Fatalf("expected empty result for no ids: %v %v", found, err) }
+}
+
+func TestTransactionRepository_FindExternalIDs_Chunked(t *testing.T) {
+	setupTestDBImport(t)
+	trepo := NewTransactionRepository()
+
+	ids := make([]string, 0, 2*externalIDChunkSize+5)
+	for i := 0; i < cap(ids); i++ {
+		ids = append(ids, fmt.Sprintf("csv:%d", i))
+	}
+	for _, id := range []string{ids[0], ids[externalIDChunkSize], ids[len(ids)-1]} {
+		if err := trepo.Create(&models.Transaction{UserID: 1, CategoryID: 1, Amount: 5, Type: models.Expense, Date: time.Now().UTC(), ExternalID: id}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	found, err := trepo.FindExternalIDs(1, ids)
+	if err != nil { t.Fatalf("find: %v", err) }
+	if len(found) != 3 { t.Fatalf("expected a match from every chunk, got %v", found) }
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// Login attempt stores
+const (
+	LoginAttemptsInMemory = "memory"
+	LoginAttemptsDatabase = "database"
+)
+
+// loginAttemptRetention is how long the memory store keeps a key after its last failure once it is
+// no longer locked
+const loginAttemptRetention = 24 * time.Hour
+
+// LoginAttemptStore counts failed logins per key. The memory store only sees the logins of its own
+// process; instances behind a load balancer share the database store.
+type LoginAttemptStore interface {
+	// Get returns the key's attempts, with no failures if none were recorded
+	Get(key string) (*models.LoginAttempt, error)
+	// RecordFailure applies update to the key's attempts atomically and returns the result
+	RecordFailure(key string, update func(attempt *models.LoginAttempt)) (*models.LoginAttempt, error)
+	Reset(key string) error
+}
+
+// NewLoginAttemptStore returns the store named by kind
+func NewLoginAttemptStore(kind string) (LoginAttemptStore, error) {
+	switch kind {
+	case "", LoginAttemptsInMemory:
+		return NewMemoryLoginAttemptStore(), nil
+	case LoginAttemptsDatabase:
+		return NewDatabaseLoginAttemptStore(), nil
+	}
+	return nil, fmt.Errorf("unknown login attempt store %q, use %s or %s", kind, LoginAttemptsInMemory, LoginAttemptsDatabase)
+}
+
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+	prunedAt time.Time
+}
+
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: map[string]models.LoginAttempt{}}
+}
+
+func (s *memoryLoginAttemptStore) Get(key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok {
+		attempt = models.LoginAttempt{Key: key}
+	}
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) RecordFailure(key string, update func(attempt *models.LoginAttempt)) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.prune(now)
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		attempt = models.LoginAttempt{Key: key}
+	}
+	update(&attempt)
+	attempt.UpdatedAt = now
+	s.attempts[key] = attempt
+	return &attempt, nil
+}
+
+// prune drops keys that are unlocked and quiet, at most once a minute
+func (s *memoryLoginAttemptStore) prune(now time.Time) {
+	if now.Sub(s.prunedAt) < time.Minute {
+		return
+	}
+	s.prunedAt = now
+	for key, attempt := range s.attempts {
+		if now.After(attempt.LockedUntil) && now.Sub(attempt.LastFailureAt) > loginAttemptRetention {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+func (s *memoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+type databaseLoginAttemptStore struct{}
+
+func NewDatabaseLoginAttemptStore() LoginAttemptStore {
+	return &databaseLoginAttemptStore{}
+}
+
+func (s *databaseLoginAttemptStore) Get(key string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := database.DB.Where("key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.LoginAttempt{Key: key}, nil
+	}
+	return &attempt, err
+}
+
+// RecordFailure locks the key's row, so concurrent failures from several instances all count
+func (s *databaseLoginAttemptStore) RecordFailure(key string, update func(attempt *models.LoginAttempt)) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LoginAttempt{Key: key}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&attempt).Error; err != nil {
+			return err
+		}
+		update(&attempt)
+		attempt.LastFailureAt = attempt.LastFailureAt.UTC()
+		attempt.LockedUntil = attempt.LockedUntil.UTC()
+		return tx.Save(&attempt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (s *databaseLoginAttemptStore) Reset(key string) error {
+	return database.DB.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func failOnce(now time.Time) func(attempt *models.LoginAttempt) {
+	return func(attempt *models.LoginAttempt) {
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		attempt.LockedUntil = now.Add(time.Duration(attempt.Failures) * time.Minute)
+	}
+}
+
+func TestLoginAttemptStores(t *testing.T) {
+	setupTestDBImport(t)
+	for _, kind := range []string{LoginAttemptsInMemory, LoginAttemptsDatabase} {
+		store, err := NewLoginAttemptStore(kind)
+		if err != nil { t.Fatalf("%s: %v", kind, err) }
+		now := time.Now().UTC().Truncate(time.Second)
+
+		attempt, err := store.Get("account:jane@example.com")
+		if err != nil || attempt.Failures != 0 || attempt.Key != "account:jane@example.com" { t.Fatalf("%s: expected no failures, got %+v %v", kind, attempt, err) }
+
+		store.RecordFailure("account:jane@example.com", failOnce(now))
+		attempt, err = store.RecordFailure("account:jane@example.com", failOnce(now))
+		if err != nil || attempt.Failures != 2 { t.Fatalf("%s: expected the failures to add up, got %+v %v", kind, attempt, err) }
+		store.RecordFailure("ip:10.0.0.1", failOnce(now))
+
+		attempt, _ = store.Get("account:jane@example.com")
+		if attempt.Failures != 2 || !attempt.LockedUntil.Equal(now.Add(2*time.Minute)) { t.Fatalf("%s: unexpected attempt %+v", kind, attempt) }
+
+		if err := store.Reset("account:jane@example.com"); err != nil { t.Fatalf("%s: reset: %v", kind, err) }
+		if attempt, _ = store.Get("account:jane@example.com"); attempt.Failures != 0 { t.Fatalf("%s: expected the reset to clear the failures, got %+v", kind, attempt) }
+		if attempt, _ = store.Get("ip:10.0.0.1"); attempt.Failures != 1 { t.Fatalf("%s: expected other keys to be kept, got %+v", kind, attempt) }
+	}
+
+	if _, err := NewLoginAttemptStore("redis"); err == nil { t.Fatalf("expected an unknown store to be rejected") }
+}
+
+func TestSecurityEventRepository(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewSecurityEventRepository()
+	now := time.Now().UTC()
+	repo.Create(&models.SecurityEvent{UserID: 1, Type: models.SecurityAccountLocked, IPAddress: "10.0.0.1", CreatedAt: now.Add(-time.Hour)})
+	repo.Create(&models.SecurityEvent{UserID: 1, Type: models.SecurityAccountUnlocked, CreatedAt: now})
+	repo.Create(&models.SecurityEvent{UserID: 2, Type: models.SecurityAccountLocked, CreatedAt: now})
+
+	events, err := repo.GetByUserID(1, 10)
+	if err != nil || len(events) != 2 || events[0].Type != models.SecurityAccountUnlocked { t.Fatalf("expected the user's events newest first, got %+v %v", events, err) }
+	if events, _ = repo.GetByUserID(1, 1); len(events) != 1 { t.Fatalf("expected the limit to apply, got %d", len(events)) }
+}
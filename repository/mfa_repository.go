@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type MFARepository interface {
+	GetFactor(userID uint) (*models.MFAFactor, error)
+	SaveFactor(factor *models.MFAFactor) error
+	Confirm(factor *models.MFAFactor, step int64, codes []models.RecoveryCode, now time.Time) error
+	UseStep(factorID uint, step int64) (bool, error)
+	UseRecoveryCode(userID uint, codeHash string, now time.Time) (bool, error)
+	Delete(userID uint) error
+}
+
+type mfaRepository struct{}
+
+func NewMFARepository() MFARepository {
+	return &mfaRepository{}
+}
+
+func (r *mfaRepository) GetFactor(userID uint) (*models.MFAFactor, error) {
+	var factor models.MFAFactor
+	err := database.DB.Where("user_id = ?", userID).First(&factor).Error
+	return &factor, err
+}
+
+func (r *mfaRepository) SaveFactor(factor *models.MFAFactor) error {
+	return database.DB.Save(factor).Error
+}
+
+// Confirm activates the factor and replaces the user's recovery codes
+func (r *mfaRepository) Confirm(factor *models.MFAFactor, step int64, codes []models.RecoveryCode, now time.Time) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.MFAFactor{}).Where("id = ?", factor.ID).
+			Updates(map[string]interface{}{"confirmed_at": now, "last_used_step": step}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", factor.UserID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseStep records step as the factor's last accepted step. It reports false when that step or a later
+// one was already used, which makes replaying a code fail even between concurrent requests.
+func (r *mfaRepository) UseStep(factorID uint, step int64) (bool, error) {
+	result := database.DB.Model(&models.MFAFactor{}).Where("id = ? AND last_used_step < ?", factorID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseRecoveryCode spends an unused recovery code; it reports false when there is none with that hash
+func (r *mfaRepository) UseRecoveryCode(userID uint, codeHash string, now time.Time) (bool, error) {
+	result := database.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete removes the factor and the recovery codes
+func (r *mfaRepository) Delete(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.MFAFactor{}).Error
+	})
+}
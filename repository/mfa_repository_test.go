@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestMFARepository_ConfirmAndUse(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewMFARepository()
+	now := time.Now().UTC()
+
+	factor := &models.MFAFactor{UserID: 1, Secret: "SECRET"}
+	if err := repo.SaveFactor(factor); err != nil { t.Fatalf("save: %v", err) }
+	if err := repo.Confirm(factor, 100, []models.RecoveryCode{{UserID: 1, CodeHash: "old"}}, now); err != nil { t.Fatalf("confirm: %v", err) }
+	if err := repo.Confirm(factor, 100, []models.RecoveryCode{{UserID: 1, CodeHash: "a"}, {UserID: 1, CodeHash: "b"}}, now); err != nil { t.Fatalf("confirm again: %v", err) }
+
+	stored, err := repo.GetFactor(1)
+	if err != nil || stored.ConfirmedAt == nil || stored.LastUsedStep != 100 { t.Fatalf("unexpected factor %+v (%v)", stored, err) }
+
+	if used, err := repo.UseStep(factor.ID, 100); err != nil || used { t.Fatalf("expected the confirmed step to be spent: %v", err) }
+	if used, _ := repo.UseStep(factor.ID, 101); !used { t.Fatalf("expected a later step to be accepted") }
+	if used, _ := repo.UseStep(factor.ID, 101); used { t.Fatalf("expected a step to work once") }
+
+	if used, _ := repo.UseRecoveryCode(1, "old", now); used { t.Fatalf("expected confirming again to replace the recovery codes") }
+	if used, _ := repo.UseRecoveryCode(2, "a", now); used { t.Fatalf("expected another user's code to be refused") }
+	if used, err := repo.UseRecoveryCode(1, "a", now); err != nil || !used { t.Fatalf("use recovery code: %v", err) }
+	if used, _ := repo.UseRecoveryCode(1, "a", now); used { t.Fatalf("expected a recovery code to work once") }
+
+	if err := repo.Delete(1); err != nil { t.Fatalf("delete: %v", err) }
+	if _, err := repo.GetFactor(1); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected the factor to be gone, got %v", err) }
+	if used, _ := repo.UseRecoveryCode(1, "b", now); used { t.Fatalf("expected the recovery codes to be gone") }
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type NetWorthRepository interface {
+	CreateAsset(asset *models.Asset) error
+	GetAssetsByUserID(userID uint) ([]models.Asset, error)
+	GetAssetByID(id uint, userID uint) (*models.Asset, error)
+	UpdateAsset(asset *models.Asset) error
+	DeleteAsset(id uint, userID uint) error
+	CreateValuation(valuation *models.AssetValuation) error
+	DeleteValuation(id uint, assetID uint, userID uint) error
+	GetSnapshots(userID uint, interval models.SummaryInterval, periods []string) ([]models.NetWorthSnapshot, error)
+	SaveSnapshots(snapshots []models.NetWorthSnapshot) error
+	DeleteSnapshots(userID uint) error
+}
+
+type netWorthRepository struct{}
+
+func NewNetWorthRepository() NetWorthRepository {
+	return &netWorthRepository{}
+}
+
+func (r *netWorthRepository) CreateAsset(asset *models.Asset) error {
+	return database.DB.Create(asset).Error
+}
+
+// GetAssetsByUserID returns the user's assets with their valuations, oldest valuation first
+func (r *netWorthRepository) GetAssetsByUserID(userID uint) ([]models.Asset, error) {
+	var assets []models.Asset
+	err := database.DB.Preload("Valuations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC, id ASC")
+	}).Where("user_id = ?", userID).Order("name").Find(&assets).Error
+	return assets, err
+}
+
+func (r *netWorthRepository) GetAssetByID(id uint, userID uint) (*models.Asset, error) {
+	var asset models.Asset
+	err := database.DB.Preload("Valuations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC, id ASC")
+	}).Where("id = ? AND user_id = ?", id, userID).First(&asset).Error
+	return &asset, err
+}
+
+func (r *netWorthRepository) UpdateAsset(asset *models.Asset) error {
+	return database.DB.Omit("Valuations").Save(asset).Error
+}
+
+func (r *netWorthRepository) DeleteAsset(id uint, userID uint) error {
+	return database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Asset{}).Error
+}
+
+func (r *netWorthRepository) CreateValuation(valuation *models.AssetValuation) error {
+	return database.DB.Create(valuation).Error
+}
+
+func (r *netWorthRepository) DeleteValuation(id uint, assetID uint, userID uint) error {
+	result := database.DB.Where("id = ? AND asset_id = ? AND user_id = ?", id, assetID, userID).Delete(&models.AssetValuation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *netWorthRepository) GetSnapshots(userID uint, interval models.SummaryInterval, periods []string) ([]models.NetWorthSnapshot, error) {
+	var snapshots []models.NetWorthSnapshot
+	if len(periods) == 0 {
+		return snapshots, nil
+	}
+	err := database.DB.Where("user_id = ? AND period_interval = ? AND period IN ?", userID, interval, periods).Find(&snapshots).Error
+	return snapshots, err
+}
+
+// SaveSnapshots stores new snapshots; a snapshot that already exists for the same period is left untouched
+func (r *netWorthRepository) SaveSnapshots(snapshots []models.NetWorthSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return database.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(snapshots, 100).Error
+}
+
+func (r *netWorthRepository) DeleteSnapshots(userID uint) error {
+	return database.DB.Where("user_id = ?", userID).Delete(&models.NetWorthSnapshot{}).Error
+}
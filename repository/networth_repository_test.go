@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestNetWorthRepository_AssetsAndValuations(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewNetWorthRepository()
+
+	house := &models.Asset{UserID: 1, Name: "House", Kind: models.AssetKindAsset}
+	if err := repo.CreateAsset(house); err != nil { t.Fatalf("create asset: %v", err) }
+	if err := repo.CreateAsset(&models.Asset{UserID: 2, Name: "Other", Kind: models.AssetKindAsset}); err != nil { t.Fatalf("create asset: %v", err) }
+	for _, v := range []models.AssetValuation{
+		{AssetID: house.ID, UserID: 1, Value: 310000, Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{AssetID: house.ID, UserID: 1, Value: 300000, Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		v := v
+		if err := repo.CreateValuation(&v); err != nil { t.Fatalf("create valuation: %v", err) }
+	}
+
+	assets, err := repo.GetAssetsByUserID(1)
+	if err != nil || len(assets) != 1 { t.Fatalf("list assets: %v len=%d", err, len(assets)) }
+	if len(assets[0].Valuations) != 2 || assets[0].Valuations[0].Value != 300000 { t.Fatalf("expected valuations oldest first, got %+v", assets[0].Valuations) }
+
+	if _, err := repo.GetAssetByID(house.ID, 2); err == nil { t.Fatalf("expected asset scoped to owner") }
+	if err := repo.DeleteValuation(assets[0].Valuations[0].ID, house.ID, 2); err == nil { t.Fatalf("expected valuation delete scoped to owner") }
+	if err := repo.DeleteValuation(assets[0].Valuations[0].ID, house.ID, 1); err != nil { t.Fatalf("delete valuation: %v", err) }
+
+	house.Name = "Home"
+	if err := repo.UpdateAsset(house); err != nil { t.Fatalf("update asset: %v", err) }
+	reloaded, _ := repo.GetAssetByID(house.ID, 1)
+	if reloaded.Name != "Home" || len(reloaded.Valuations) != 1 { t.Fatalf("unexpected asset after update %+v", reloaded) }
+}
+
+func TestNetWorthRepository_SnapshotsAreWriteOnce(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewNetWorthRepository()
+
+	first := []models.NetWorthSnapshot{{UserID: 1, Interval: models.IntervalMonth, Period: "2025-08-01", NetWorth: 100}}
+	if err := repo.SaveSnapshots(first); err != nil { t.Fatalf("save: %v", err) }
+	again := []models.NetWorthSnapshot{
+		{UserID: 1, Interval: models.IntervalMonth, Period: "2025-08-01", NetWorth: 999},
+		{UserID: 1, Interval: models.IntervalMonth, Period: "2025-09-01", NetWorth: 200},
+	}
+	if err := repo.SaveSnapshots(again); err != nil { t.Fatalf("save again: %v", err) }
+
+	snapshots, err := repo.GetSnapshots(1, models.IntervalMonth, []string{"2025-08-01", "2025-09-01"})
+	if err != nil || len(snapshots) != 2 { t.Fatalf("get snapshots: %v len=%d", err, len(snapshots)) }
+	for _, s := range snapshots {
+		if s.Period == "2025-08-01" && s.NetWorth != 100 { t.Fatalf("expected existing snapshot to stay unchanged, got %v", s.NetWorth) }
+	}
+	if weekly, _ := repo.GetSnapshots(1, models.IntervalWeek, []string{"2025-08-01"}); len(weekly) != 0 { t.Fatalf("expected snapshots scoped by interval") }
+
+	if err := repo.DeleteSnapshots(1); err != nil { t.Fatalf("delete: %v", err) }
+	if left, _ := repo.GetSnapshots(1, models.IntervalMonth, []string{"2025-08-01"}); len(left) != 0 { t.Fatalf("expected snapshots removed") }
+}
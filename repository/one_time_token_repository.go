@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type OneTimeTokenRepository interface {
+	Create(token *models.OneTimeToken) error
+	Consume(tokenHash string, purpose string, now time.Time) (*models.OneTimeToken, error)
+	CountSince(userID uint, purpose string, since time.Time) (int64, error)
+}
+
+type oneTimeTokenRepository struct{}
+
+func NewOneTimeTokenRepository() OneTimeTokenRepository {
+	return &oneTimeTokenRepository{}
+}
+
+// Create stores the token and retires the user's unused tokens for the same purpose
+func (r *oneTimeTokenRepository) Create(token *models.OneTimeToken) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.OneTimeToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now().UTC()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// Consume marks an unused, unexpired token as used and returns it. Any other token, including one
+// that a concurrent request consumed first, yields gorm.ErrRecordNotFound.
+func (r *oneTimeTokenRepository) Consume(tokenHash string, purpose string, now time.Time) (*models.OneTimeToken, error) {
+	var token models.OneTimeToken
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now.UTC()).
+			First(&token).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&models.OneTimeToken{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CountSince counts the tokens issued to the user for the purpose since the given time, used or not
+func (r *oneTimeTokenRepository) CountSince(userID uint, purpose string, since time.Time) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", userID, purpose, since.UTC()).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestOneTimeTokenRepository_Consume(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewOneTimeTokenRepository()
+	now := time.Now().UTC()
+
+	first := &models.OneTimeToken{UserID: 1, Purpose: models.TokenPasswordReset, TokenHash: "first", ExpiresAt: now.Add(time.Hour)}
+	second := &models.OneTimeToken{UserID: 1, Purpose: models.TokenPasswordReset, TokenHash: "second", ExpiresAt: now.Add(time.Hour)}
+	other := &models.OneTimeToken{UserID: 2, Purpose: models.TokenPasswordReset, TokenHash: "other", ExpiresAt: now.Add(time.Hour)}
+	for _, token := range []*models.OneTimeToken{first, second, other} {
+		if err := repo.Create(token); err != nil { t.Fatalf("create: %v", err) }
+	}
+
+	if _, err := repo.Consume("first", models.TokenPasswordReset, now); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected the superseded token to be retired, got %v", err) }
+	if _, err := repo.Consume("second", "other_purpose", now); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected the purpose to be checked, got %v", err) }
+	if _, err := repo.Consume("second", models.TokenPasswordReset, now.Add(2*time.Hour)); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected an expired token to be rejected, got %v", err) }
+
+	token, err := repo.Consume("second", models.TokenPasswordReset, now)
+	if err != nil || token.UserID != 1 { t.Fatalf("consume: %v %+v", err, token) }
+	if _, err := repo.Consume("second", models.TokenPasswordReset, now); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected a token to work once, got %v", err) }
+
+	// another user's token is unaffected
+	if _, err := repo.Consume("other", models.TokenPasswordReset, now); err != nil { t.Fatalf("consume other: %v", err) }
+}
+
+func TestOneTimeTokenRepository_CountSince(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewOneTimeTokenRepository()
+	now := time.Now().UTC()
+
+	for i, purpose := range []string{models.TokenEmailVerification, models.TokenEmailVerification, models.TokenPasswordReset} {
+		token := &models.OneTimeToken{UserID: 1, Purpose: purpose, TokenHash: string(rune('a' + i)), ExpiresAt: now.Add(time.Hour)}
+		if err := repo.Create(token); err != nil { t.Fatalf("create: %v", err) }
+	}
+
+	if count, err := repo.CountSince(1, models.TokenEmailVerification, now.Add(-time.Minute)); err != nil || count != 2 { t.Fatalf("expected 2 verification tokens, got %d %v", count, err) }
+	if count, _ := repo.CountSince(1, models.TokenEmailVerification, now.Add(time.Minute)); count != 0 { t.Fatalf("expected no tokens after now, got %d", count) }
+	if count, _ := repo.CountSince(2, models.TokenEmailVerification, now.Add(-time.Minute)); count != 0 { t.Fatalf("expected no tokens for another user, got %d", count) }
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// ReportEmailRepository stores summary email subscriptions and the delivery log
+type ReportEmailRepository interface {
+	CreateSubscription(subscription *models.ReportSubscription) error
+	GetSubscriptionsByUserID(userID uint) ([]models.ReportSubscription, error)
+	GetSubscription(userID uint, frequency models.ReportFrequency) (*models.ReportSubscription, error)
+	GetSubscriptionByID(id uint, userID uint) (*models.ReportSubscription, error)
+	GetSubscriptionByToken(token string) (*models.ReportSubscription, error)
+	GetDueSubscriptions(now time.Time) ([]models.ReportSubscription, error)
+	UpdateSubscription(subscription *models.ReportSubscription) error
+	ScheduleNextRun(id uint, nextRunAt time.Time, sentAt *time.Time) error
+	DeleteSubscription(id uint, userID uint) error
+	ClaimDelivery(delivery *models.ReportDelivery, maxAttempts int) (bool, error)
+	UpdateDelivery(delivery *models.ReportDelivery) error
+	GetDeliveriesByUserID(userID uint, limit int) ([]models.ReportDelivery, error)
+}
+
+type reportEmailRepository struct{}
+
+func NewReportEmailRepository() ReportEmailRepository {
+	return &reportEmailRepository{}
+}
+
+func (r *reportEmailRepository) CreateSubscription(subscription *models.ReportSubscription) error {
+	return database.DB.Create(subscription).Error
+}
+
+func (r *reportEmailRepository) GetSubscriptionsByUserID(userID uint) ([]models.ReportSubscription, error) {
+	var subscriptions []models.ReportSubscription
+	err := database.DB.Where("user_id = ?", userID).Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *reportEmailRepository) GetSubscription(userID uint, frequency models.ReportFrequency) (*models.ReportSubscription, error) {
+	var subscription models.ReportSubscription
+	err := database.DB.Where("user_id = ? AND frequency = ?", userID, frequency).First(&subscription).Error
+	return &subscription, err
+}
+
+func (r *reportEmailRepository) GetSubscriptionByID(id uint, userID uint) (*models.ReportSubscription, error) {
+	var subscription models.ReportSubscription
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&subscription).Error
+	return &subscription, err
+}
+
+func (r *reportEmailRepository) GetSubscriptionByToken(token string) (*models.ReportSubscription, error) {
+	var subscription models.ReportSubscription
+	err := database.DB.Where("unsubscribe_token = ?", token).First(&subscription).Error
+	return &subscription, err
+}
+
+// GetDueSubscriptions returns active subscriptions whose next run is at or before now
+func (r *reportEmailRepository) GetDueSubscriptions(now time.Time) ([]models.ReportSubscription, error) {
+	var subscriptions []models.ReportSubscription
+	err := database.DB.Where("active = ? AND next_run_at <= ?", true, now).Order("next_run_at, id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *reportEmailRepository) UpdateSubscription(subscription *models.ReportSubscription) error {
+	return database.DB.Save(subscription).Error
+}
+
+// ScheduleNextRun moves the subscription to its next period, recording sentAt when an email went out.
+// Only the schedule columns are written so a concurrent unsubscribe is not undone.
+func (r *reportEmailRepository) ScheduleNextRun(id uint, nextRunAt time.Time, sentAt *time.Time) error {
+	updates := map[string]interface{}{"next_run_at": nextRunAt}
+	if sentAt != nil {
+		updates["last_sent_at"] = *sentAt
+	}
+	return database.DB.Model(&models.ReportSubscription{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteSubscription removes the subscription together with its delivery log
+func (r *reportEmailRepository) DeleteSubscription(id uint, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.ReportSubscription{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("subscription_id = ?", id).Delete(&models.ReportDelivery{}).Error
+	})
+}
+
+// ClaimDelivery reserves the delivery for its subscription and period. It returns false when another
+// run already owns it: the delivery was sent, is in progress, or failed maxAttempts times. A failed
+// delivery is claimed again by flipping it back to pending; delivery is then loaded with the stored row.
+func (r *reportEmailRepository) ClaimDelivery(delivery *models.ReportDelivery, maxAttempts int) (bool, error) {
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	var existing models.ReportDelivery
+	err := database.DB.Where("subscription_id = ? AND period_start = ?", delivery.SubscriptionID, delivery.PeriodStart).First(&existing).Error
+	if err != nil {
+		return false, err
+	}
+	*delivery = existing
+	if existing.Status != models.DeliveryFailed || existing.Attempts >= maxAttempts {
+		return false, nil
+	}
+
+	result = database.DB.Model(&models.ReportDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", existing.ID, models.DeliveryFailed, existing.Attempts).
+		Update("status", models.DeliveryPending)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	delivery.Status = models.DeliveryPending
+	return result.RowsAffected == 1, nil
+}
+
+func (r *reportEmailRepository) UpdateDelivery(delivery *models.ReportDelivery) error {
+	return database.DB.Save(delivery).Error
+}
+
+// GetDeliveriesByUserID returns the most recent deliveries first
+func (r *reportEmailRepository) GetDeliveriesByUserID(userID uint, limit int) ([]models.ReportDelivery, error) {
+	var deliveries []models.ReportDelivery
+	err := database.DB.Where("user_id = ?", userID).Order("period_start DESC, id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestReportEmailRepository_Subscriptions(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewReportEmailRepository()
+
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	weekly := &models.ReportSubscription{UserID: 1, Frequency: models.ReportWeekly, Active: true, NextRunAt: monday}
+	monthly := &models.ReportSubscription{UserID: 1, Frequency: models.ReportMonthly, Active: true, NextRunAt: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)}
+	for _, subscription := range []*models.ReportSubscription{weekly, monthly} {
+		if err := repo.CreateSubscription(subscription); err != nil { t.Fatalf("create: %v", err) }
+	}
+	if len(weekly.UnsubscribeToken) != 64 || weekly.UnsubscribeToken == monthly.UnsubscribeToken { t.Fatalf("expected distinct tokens, got %q %q", weekly.UnsubscribeToken, monthly.UnsubscribeToken) }
+	if err := repo.CreateSubscription(&models.ReportSubscription{UserID: 1, Frequency: models.ReportWeekly, NextRunAt: monday}); err == nil { t.Fatalf("expected one subscription per frequency") }
+
+	if got, err := repo.GetSubscriptionByToken(weekly.UnsubscribeToken); err != nil || got.ID != weekly.ID { t.Fatalf("lookup by token: %v %+v", err, got) }
+	if _, err := repo.GetSubscriptionByID(weekly.ID, 2); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected subscription scoped to owner") }
+
+	due, err := repo.GetDueSubscriptions(monday.Add(time.Hour))
+	if err != nil || len(due) != 1 || due[0].ID != weekly.ID { t.Fatalf("expected only the weekly subscription due: %v %+v", err, due) }
+
+	// an unsubscribe racing the job must survive the job moving the schedule on
+	weekly.Active = false
+	if err := repo.UpdateSubscription(weekly); err != nil { t.Fatalf("update: %v", err) }
+	sentAt := monday.Add(time.Hour)
+	if err := repo.ScheduleNextRun(weekly.ID, monday.AddDate(0, 0, 7), &sentAt); err != nil { t.Fatalf("schedule: %v", err) }
+	got, _ := repo.GetSubscription(1, models.ReportWeekly)
+	if got.Active || !got.NextRunAt.Equal(monday.AddDate(0, 0, 7)) || got.LastSentAt == nil { t.Fatalf("unexpected subscription %+v", got) }
+
+	if err := repo.DeleteSubscription(monthly.ID, 2); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected not found deleting another user's subscription, got %v", err) }
+	if err := repo.DeleteSubscription(monthly.ID, 1); err != nil { t.Fatalf("delete: %v", err) }
+	if subscriptions, _ := repo.GetSubscriptionsByUserID(1); len(subscriptions) != 1 { t.Fatalf("expected 1 subscription left, got %d", len(subscriptions)) }
+}
+
+func TestReportEmailRepository_ClaimDelivery(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewReportEmailRepository()
+
+	start := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	newDelivery := func() *models.ReportDelivery {
+		return &models.ReportDelivery{SubscriptionID: 1, UserID: 1, Frequency: models.ReportWeekly, PeriodStart: start, PeriodEnd: start.AddDate(0, 0, 7), Status: models.DeliveryPending}
+	}
+
+	first := newDelivery()
+	if claimed, err := repo.ClaimDelivery(first, 2); err != nil || !claimed { t.Fatalf("expected first claim to win: %v %v", claimed, err) }
+	if claimed, _ := repo.ClaimDelivery(newDelivery(), 2); claimed { t.Fatalf("expected a pending delivery not to be claimed twice") }
+
+	first.Status, first.Attempts, first.Error = models.DeliveryFailed, 1, "timeout"
+	if err := repo.UpdateDelivery(first); err != nil { t.Fatalf("update: %v", err) }
+	retry := newDelivery()
+	if claimed, err := repo.ClaimDelivery(retry, 2); err != nil || !claimed || retry.ID != first.ID || retry.Attempts != 1 || retry.Status != models.DeliveryPending { t.Fatalf("expected failed delivery to be reclaimed: %v %v %+v", claimed, err, retry) }
+	if claimed, _ := repo.ClaimDelivery(newDelivery(), 2); claimed { t.Fatalf("expected a reclaimed delivery not to be claimed twice") }
+
+	retry.Status, retry.Attempts = models.DeliveryFailed, 2
+	repo.UpdateDelivery(retry)
+	if claimed, _ := repo.ClaimDelivery(newDelivery(), 2); claimed { t.Fatalf("expected delivery to be given up after max attempts") }
+
+	deliveries, err := repo.GetDeliveriesByUserID(1, 10)
+	if err != nil || len(deliveries) != 1 || deliveries[0].Attempts != 2 { t.Fatalf("expected a single delivery row: %v %+v", err, deliveries) }
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// RollupRepository rebuilds the monthly rollup from the transactions table. Transaction writes keep it
+// up to date; a rebuild is only needed after the table is created or when it was written around.
+type RollupRepository interface {
+	Rebuild(userID uint) error
+	RebuildAll() (int, error)
+	Missing() (bool, error)
+}
+
+type rollupRepository struct{}
+
+func NewRollupRepository() RollupRepository {
+	return &rollupRepository{}
+}
+
+// Rebuild replaces the user's rollup rows with a fresh aggregation of their transactions
+func (r *rollupRepository) Rebuild(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MonthlyRollup{}).Error; err != nil {
+			return err
+		}
+
+		month, err := periodExpression(tx, "date", models.IntervalMonth)
+		if err != nil {
+			return err
+		}
+		var rows []models.MonthlyRollup
+		err = tx.Model(&models.Transaction{}).
+			Select("user_id, "+month+" AS month, category_id, type, SUM(amount) AS total, COUNT(*) AS count").
+			Where("user_id = ?", userID).
+			Group("user_id, " + month + ", category_id, type").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		// the period expression yields the first day of the month; the rollup keys on YYYY-MM
+		for i := range rows {
+			rows[i].Month = rows[i].Month[:7]
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// RebuildAll rebuilds every user with transactions or rollup rows, one user per database transaction,
+// and returns the number of users rebuilt
+func (r *rollupRepository) RebuildAll() (int, error) {
+	var withTransactions, withRollups []uint
+	if err := database.DB.Model(&models.Transaction{}).Distinct().Pluck("user_id", &withTransactions).Error; err != nil {
+		return 0, err
+	}
+	if err := database.DB.Model(&models.MonthlyRollup{}).Distinct().Pluck("user_id", &withRollups).Error; err != nil {
+		return 0, err
+	}
+
+	seen := make(map[uint]bool)
+	var userIDs []uint
+	for _, userID := range append(withTransactions, withRollups...) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	for i, userID := range userIDs {
+		if err := r.Rebuild(userID); err != nil {
+			return i, err
+		}
+	}
+	return len(userIDs), nil
+}
+
+// Missing reports whether there are transactions but no rollup rows at all, as right after the rollup
+// table has been added to an existing database
+func (r *rollupRepository) Missing() (bool, error) {
+	var rollups, transactions int64
+	if err := database.DB.Model(&models.MonthlyRollup{}).Limit(1).Count(&rollups).Error; err != nil {
+		return false, err
+	}
+	if rollups > 0 {
+		return false, nil
+	}
+	if err := database.DB.Model(&models.Transaction{}).Limit(1).Count(&transactions).Error; err != nil {
+		return false, err
+	}
+	return transactions > 0, nil
+}
+
+type rollupKey struct {
+	userID          uint
+	month           string
+	categoryID      uint
+	transactionType models.TransactionType
+}
+
+// rollupDelta collects the change a write makes to the rollup, so that it can be applied in the same
+// database transaction as the write itself
+type rollupDelta map[rollupKey]*models.MonthlyRollup
+
+func (d rollupDelta) add(transaction *models.Transaction, sign int) {
+	key := rollupKey{transaction.UserID, transaction.Date.UTC().Format("2006-01"), transaction.CategoryID, transaction.Type}
+	row, ok := d[key]
+	if !ok {
+		row = &models.MonthlyRollup{UserID: key.userID, Month: key.month, CategoryID: key.categoryID, Type: key.transactionType}
+		d[key] = row
+	}
+	row.Total += float64(sign) * transaction.Amount
+	row.Count += sign
+}
+
+// apply adds the delta to the stored rows, creating missing ones and removing those left without
+// transactions. Rows are written in key order so concurrent writers lock them in the same order.
+func (d rollupDelta) apply(tx *gorm.DB) error {
+	keys := make([]rollupKey, 0, len(d))
+	for key, row := range d {
+		if row.Count != 0 || row.Total != 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.userID != b.userID {
+			return a.userID < b.userID
+		}
+		if a.month != b.month {
+			return a.month < b.month
+		}
+		if a.categoryID != b.categoryID {
+			return a.categoryID < b.categoryID
+		}
+		return a.transactionType < b.transactionType
+	})
+
+	for _, key := range keys {
+		row := d[key]
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "month"}, {Name: "category_id"}, {Name: "type"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"total": gorm.Expr("monthly_rollups.total + excluded.total"),
+				"count": gorm.Expr("monthly_rollups.count + excluded.count"),
+			}),
+		}).Create(row).Error
+		if err != nil {
+			return err
+		}
+		if row.Count < 0 {
+			err := tx.Where("user_id = ? AND month = ? AND category_id = ? AND type = ? AND count <= 0", key.userID, key.month, key.categoryID, key.transactionType).
+				Delete(&models.MonthlyRollup{}).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteTransactions soft deletes the transactions matched by query and takes them out of the rollup
+func deleteTransactions(tx *gorm.DB, query *gorm.DB) error {
+	var transactions []models.Transaction
+	err := query.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, user_id, category_id, type, amount, date").
+		Find(&transactions).Error
+	if err != nil || len(transactions) == 0 {
+		return err
+	}
+
+	ids := make([]uint, len(transactions))
+	delta := rollupDelta{}
+	for i := range transactions {
+		ids[i] = transactions[i].ID
+		delta.add(&transactions[i], -1)
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&models.Transaction{}).Error; err != nil {
+		return err
+	}
+	return delta.apply(tx)
+}
+
+// rollupTotals sums the user's transactions in [start, end) per category and type. Whole months are
+// read from the rollup and the partial months at either end from transactions. A zero start or end
+// leaves that side of the range open.
+func rollupTotals(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+	wholeFrom, wholeTo := start, end
+	if !start.IsZero() {
+		wholeFrom = time.Date(start.UTC().Year(), start.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+		if wholeFrom.Before(start) {
+			wholeFrom = wholeFrom.AddDate(0, 1, 0)
+		}
+	}
+	if !end.IsZero() {
+		wholeTo = time.Date(end.UTC().Year(), end.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if !start.IsZero() && !end.IsZero() && !wholeFrom.Before(wholeTo) {
+		totals, err := transactionTotals(userID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		return mergeCategoryTotals(totals), nil
+	}
+
+	var totals []models.CategoryTotal
+	query := database.DB.Model(&models.MonthlyRollup{}).
+		Select("category_id, type, SUM(total) AS total, SUM(count) AS count").
+		Where("user_id = ?", userID)
+	if !start.IsZero() {
+		query = query.Where("month >= ?", wholeFrom.Format("2006-01"))
+	}
+	if !end.IsZero() {
+		query = query.Where("month < ?", wholeTo.Format("2006-01"))
+	}
+	if err := query.Group("category_id, type").Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+
+	if !start.IsZero() && start.Before(wholeFrom) {
+		head, err := transactionTotals(userID, start, wholeFrom)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, head...)
+	}
+	if !end.IsZero() && wholeTo.Before(end) {
+		tail, err := transactionTotals(userID, wholeTo, end)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, tail...)
+	}
+	return mergeCategoryTotals(totals), nil
+}
+
+func transactionTotals(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+	var totals []models.CategoryTotal
+	err := database.DB.Model(&models.Transaction{}).
+		Select("category_id, type, SUM(amount) AS total, COUNT(*) AS count").
+		Where("user_id = ? AND date >= ? AND date < ?", userID, start.UTC(), end.UTC()).
+		Group("category_id, type").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// mergeCategoryTotals adds up rows for the same category and type, ordered by category and type
+func mergeCategoryTotals(totals []models.CategoryTotal) []models.CategoryTotal {
+	type key struct {
+		categoryID      uint
+		transactionType models.TransactionType
+	}
+	merged := make(map[key]*models.CategoryTotal)
+	var keys []key
+	for _, total := range totals {
+		k := key{total.CategoryID, total.Type}
+		if row, ok := merged[k]; ok {
+			row.Total += total.Total
+			row.Count += total.Count
+			continue
+		}
+		row := total
+		merged[k] = &row
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].categoryID != keys[j].categoryID {
+			return keys[i].categoryID < keys[j].categoryID
+		}
+		return keys[i].transactionType < keys[j].transactionType
+	})
+
+	result := make([]models.CategoryTotal, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, *merged[k])
+	}
+	return result
+}
+
+// labelCategoryTotals fills in category names, colors and the fixed flag. Categories are read without
+// the soft-delete scope so totals for deleted categories keep their labels.
+func labelCategoryTotals(totals []models.CategoryTotal) error {
+	if len(totals) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(totals))
+	for i, total := range totals {
+		ids[i] = total.CategoryID
+	}
+	var categories []models.Category
+	if err := database.DB.Unscoped().Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	for i := range totals {
+		category := byID[totals[i].CategoryID]
+		totals[i].CategoryName = category.Name
+		totals[i].CategoryColor = category.Color
+		totals[i].Fixed = category.Fixed
+	}
+	return nil
+}
+
+func isMonthStart(t time.Time) bool {
+	t = t.UTC()
+	return t.Equal(time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC))
+}
+
+// rollupTimeSeries answers a monthly or yearly series whose bounds fall on month starts from the rollup
+func rollupTimeSeries(userID uint, interval models.SummaryInterval, start, end time.Time) ([]models.SummaryBucket, error) {
+	var rows []models.MonthlyRollup
+	err := database.DB.Model(&models.MonthlyRollup{}).
+		Select("month, type, SUM(total) AS total, SUM(count) AS count").
+		Where("user_id = ? AND month >= ? AND month < ?", userID, start.UTC().Format("2006-01"), end.UTC().Format("2006-01")).
+		Group("month, type").
+		Order("month").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []models.SummaryBucket
+	for _, row := range rows {
+		period := row.Month + "-01"
+		if interval == models.IntervalYear {
+			period = row.Month[:4] + "-01-01"
+		}
+		if len(buckets) == 0 || buckets[len(buckets)-1].Period != period {
+			buckets = append(buckets, models.SummaryBucket{Period: period})
+		}
+		bucket := &buckets[len(buckets)-1]
+		if row.Type == models.Income {
+			bucket.Income += row.Total
+		} else {
+			bucket.Expense += row.Total
+		}
+		bucket.Count += row.Count
+	}
+	return buckets, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// rawRollup aggregates the live transactions the way the rollup should hold them
+func rawRollup(t *testing.T) map[string]models.MonthlyRollup {
+	t.Helper()
+	var transactions []models.Transaction
+	if err := database.DB.Find(&transactions).Error; err != nil { t.Fatalf("load transactions: %v", err) }
+	rows := map[string]models.MonthlyRollup{}
+	for _, tx := range transactions {
+		key := fmt.Sprintf("%d/%s/%d/%s", tx.UserID, tx.Date.UTC().Format("2006-01"), tx.CategoryID, tx.Type)
+		row := rows[key]
+		row.Total += tx.Amount
+		row.Count++
+		rows[key] = row
+	}
+	return rows
+}
+
+func assertRollupConsistent(t *testing.T, stage string) {
+	t.Helper()
+	want := rawRollup(t)
+	var stored []models.MonthlyRollup
+	if err := database.DB.Find(&stored).Error; err != nil { t.Fatalf("load rollup: %v", err) }
+	if len(stored) != len(want) { t.Fatalf("%s: expected %d rollup rows, got %d: %+v", stage, len(want), len(stored), stored) }
+	for _, row := range stored {
+		key := fmt.Sprintf("%d/%s/%d/%s", row.UserID, row.Month, row.CategoryID, row.Type)
+		if raw, ok := want[key]; !ok || raw.Count != row.Count || math.Abs(raw.Total-row.Total) > 1e-9 {
+			t.Fatalf("%s: rollup row %s is %v/%d, transactions say %v/%d", stage, key, row.Total, row.Count, raw.Total, raw.Count)
+		}
+	}
+}
+
+func rollupFixture(t *testing.T) (TransactionRepository, []*models.Transaction) {
+	t.Helper()
+	setupTestDBImport(t)
+	trepo := NewTransactionRepository()
+	crepo := NewCategoryRepository()
+	food := &models.Category{UserID: 1, Name: "Food"}
+	rent := &models.Category{UserID: 1, Name: "Rent"}
+	for _, c := range []*models.Category{food, rent} {
+		if err := crepo.Create(c); err != nil { t.Fatalf("create category: %v", err) }
+	}
+
+	var created []*models.Transaction
+	for i, tx := range []models.Transaction{
+		{UserID: 1, CategoryID: food.ID, Amount: 10.10, Type: models.Expense, Date: time.Date(2025, 7, 31, 23, 30, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 20.20, Type: models.Expense, Date: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 30.30, Type: models.Expense, Date: time.Date(2025, 8, 15, 12, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: rent.ID, Amount: 900, Type: models.Expense, Date: time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 3000, Type: models.Income, Date: time.Date(2025, 8, 25, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 40.40, Type: models.Expense, Date: time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: rent.ID, Amount: 900, Type: models.Expense, Date: time.Date(2025, 9, 30, 23, 59, 0, 0, time.UTC)},
+		// a non-UTC timestamp belongs to the month it falls in once converted to UTC
+		{UserID: 1, CategoryID: food.ID, Amount: 5, Type: models.Expense, Date: time.Date(2025, 10, 1, 1, 0, 0, 0, time.FixedZone("CEST", 2*3600))},
+		{UserID: 2, CategoryID: food.ID, Amount: 77, Type: models.Expense, Date: time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)},
+	} {
+		tx := tx
+		if err := trepo.Create(&tx); err != nil { t.Fatalf("create %d: %v", i, err) }
+		created = append(created, &tx)
+	}
+	return trepo, created
+}
+
+func TestRollup_MaintainedOnWrites(t *testing.T) {
+	trepo, created := rollupFixture(t)
+	assertRollupConsistent(t, "create")
+
+	// move an expense to another month, category and type
+	moved := created[2]
+	moved.Date = time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+	moved.CategoryID = created[3].CategoryID
+	moved.Type = models.Income
+	moved.Amount = 12.5
+	if err := trepo.Update(moved); err != nil { t.Fatalf("update: %v", err) }
+	assertRollupConsistent(t, "update")
+
+	if err := trepo.Delete(created[0].ID, 1); err != nil { t.Fatalf("delete: %v", err) }
+	if err := trepo.Delete(created[0].ID, 1); err != nil { t.Fatalf("repeated delete: %v", err) }
+	if err := trepo.Delete(created[8].ID, 1); err != nil { t.Fatalf("delete of another user's transaction: %v", err) }
+	assertRollupConsistent(t, "delete")
+	var left int64
+	database.DB.Model(&models.MonthlyRollup{}).Where("month = ?", "2025-07").Count(&left)
+	if left != 0 { t.Fatalf("expected the emptied July row to be removed") }
+
+	batch := &models.ImportBatch{UserID: 1, Source: models.ImportSourceCSV, Status: models.ImportBatchCommitted, RowCount: 2}
+	imported := []models.Transaction{
+		{CategoryID: created[1].CategoryID, Amount: 1.5, Type: models.Expense, Date: time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC)},
+		{CategoryID: created[1].CategoryID, Amount: 2.5, Type: models.Expense, Date: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := trepo.CreateImport(batch, imported); err != nil { t.Fatalf("import: %v", err) }
+	assertRollupConsistent(t, "import")
+	if err := trepo.UndoImport(batch.ID, 1); err != nil { t.Fatalf("undo import: %v", err) }
+	assertRollupConsistent(t, "undo import")
+}
+
+func TestRollup_SummaryAgreesWithRawAggregation(t *testing.T) {
+	trepo, _ := rollupFixture(t)
+
+	utc := func(m time.Month, d, h int) time.Time { return time.Date(2025, m, d, h, 0, 0, 0, time.UTC) }
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil { t.Skipf("no zone data: %v", err) }
+	ranges := [][2]time.Time{
+		{{}, {}},
+		{utc(8, 1, 0), utc(9, 1, 0)},
+		{utc(8, 1, 0), utc(10, 1, 0)},
+		{utc(7, 31, 0), utc(10, 1, 0)},
+		{utc(8, 2, 0), utc(9, 11, 0)},
+		{utc(8, 15, 12), utc(10, 1, 0)},
+		{{}, utc(9, 1, 0)},
+		{utc(9, 1, 0), {}},
+		{utc(8, 3, 0), utc(8, 4, 0)},
+		// month boundaries of a user ahead of UTC split every month
+		{time.Date(2025, 8, 1, 0, 0, 0, 0, berlin), time.Date(2025, 10, 1, 0, 0, 0, 0, berlin)},
+		{time.Date(2025, 8, 15, 0, 0, 0, 0, berlin), time.Date(2025, 9, 16, 0, 0, 0, 0, berlin)},
+	}
+	for _, r := range ranges {
+		start, end := r[0], r[1]
+		summary, err := trepo.GetSummary(1, start, end)
+		if err != nil { t.Fatalf("summary %v: %v", r, err) }
+
+		// reference: every live transaction compared by instant, so stored offsets do not matter
+		var transactions []models.Transaction
+		database.DB.Where("user_id = ?", 1).Find(&transactions)
+		var income, expense float64
+		for _, tx := range transactions {
+			if (!start.IsZero() && tx.Date.Before(start)) || (!end.IsZero() && !tx.Date.Before(end)) { continue }
+			if tx.Type == models.Income { income += tx.Amount } else { expense += tx.Amount }
+		}
+		if math.Abs(summary.TotalIncome-income) > 1e-9 || math.Abs(summary.TotalExpense-expense) > 1e-9 {
+			t.Fatalf("range %v: rollup summary %+v, raw %v/%v", r, summary, income, expense)
+		}
+	}
+
+	totals, err := trepo.GetCategoryTotals(1, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil { t.Fatalf("category totals: %v", err) }
+	if len(totals) != 3 || totals[0].Type != models.Income || totals[0].Total != 3000 || totals[1].CategoryName != "Rent" || totals[1].Total != 900 || math.Abs(totals[2].Total-101) > 1e-9 || totals[2].Count != 4 {
+		t.Fatalf("unexpected category totals %+v", totals)
+	}
+
+	series, err := trepo.GetTimeSeries(1, models.IntervalMonth, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+	if err != nil { t.Fatalf("series: %v", err) }
+	if len(series) != 3 || series[1].Period != "2025-08-01" || series[1].Income != 3000 || math.Abs(series[1].Expense-950.5) > 1e-9 || series[1].Count != 4 || series[2].Period != "2025-09-01" || math.Abs(series[2].Expense-945.4) > 1e-9 {
+		t.Fatalf("unexpected monthly series %+v", series)
+	}
+	yearly, err := trepo.GetTimeSeries(1, models.IntervalYear, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+	if err != nil || len(yearly) != 1 || yearly[0].Period != "2025-01-01" || yearly[0].Count != 8 { t.Fatalf("unexpected yearly series %+v %v", yearly, err) }
+}
+
+func TestRollupRepository_Rebuild(t *testing.T) {
+	rollupFixture(t)
+	repo := NewRollupRepository()
+	if missing, err := repo.Missing(); err != nil || missing { t.Fatalf("expected maintained rollup not to be missing: %v %v", missing, err) }
+
+	// writes that bypass the repository leave the rollup behind until it is rebuilt
+	database.DB.Where("1 = 1").Delete(&models.MonthlyRollup{})
+	database.DB.Create(&models.MonthlyRollup{UserID: 3, Month: "2020-01", CategoryID: 1, Type: models.Expense, Total: 1, Count: 1})
+	database.DB.Create(&models.Transaction{UserID: 1, CategoryID: 1, Amount: 8, Type: models.Expense, Date: time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC)})
+
+	users, err := repo.RebuildAll()
+	if err != nil || users != 3 { t.Fatalf("expected 3 users rebuilt, got %d: %v", users, err) }
+	assertRollupConsistent(t, "rebuild")
+
+	database.DB.Where("user_id = ?", 2).Delete(&models.MonthlyRollup{})
+	if err := repo.Rebuild(2); err != nil { t.Fatalf("rebuild user: %v", err) }
+	assertRollupConsistent(t, "rebuild user")
+
+	database.DB.Where("1 = 1").Delete(&models.MonthlyRollup{})
+	if missing, err := repo.Missing(); err != nil || !missing { t.Fatalf("expected empty rollup with transactions to be missing: %v %v", missing, err) }
+}
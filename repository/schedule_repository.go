@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// ScheduleRepository stores what is expected to happen: recurring items and upcoming bills
+type ScheduleRepository interface {
+	CreateRecurring(item *models.RecurringItem) error
+	GetRecurringByUserID(userID uint) ([]models.RecurringItem, error)
+	GetRecurringByID(id uint, userID uint) (*models.RecurringItem, error)
+	UpdateRecurring(item *models.RecurringItem) error
+	DeleteRecurring(id uint, userID uint) error
+	CreateBill(bill *models.Bill) error
+	GetBillsByUserID(userID uint, unpaidOnly bool) ([]models.Bill, error)
+	GetBillByID(id uint, userID uint) (*models.Bill, error)
+	UpdateBill(bill *models.Bill) error
+	DeleteBill(id uint, userID uint) error
+}
+
+type scheduleRepository struct{}
+
+func NewScheduleRepository() ScheduleRepository {
+	return &scheduleRepository{}
+}
+
+func (r *scheduleRepository) CreateRecurring(item *models.RecurringItem) error {
+	return database.DB.Create(item).Error
+}
+
+func (r *scheduleRepository) GetRecurringByUserID(userID uint) ([]models.RecurringItem, error) {
+	var items []models.RecurringItem
+	err := database.DB.Where("user_id = ?", userID).Order("start_date, id").Find(&items).Error
+	return items, err
+}
+
+func (r *scheduleRepository) GetRecurringByID(id uint, userID uint) (*models.RecurringItem, error) {
+	var item models.RecurringItem
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&item).Error
+	return &item, err
+}
+
+func (r *scheduleRepository) UpdateRecurring(item *models.RecurringItem) error {
+	return database.DB.Save(item).Error
+}
+
+func (r *scheduleRepository) DeleteRecurring(id uint, userID uint) error {
+	return database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.RecurringItem{}).Error
+}
+
+func (r *scheduleRepository) CreateBill(bill *models.Bill) error {
+	return database.DB.Create(bill).Error
+}
+
+// GetBillsByUserID returns bills ordered by due date, optionally only those not yet paid
+func (r *scheduleRepository) GetBillsByUserID(userID uint, unpaidOnly bool) ([]models.Bill, error) {
+	var bills []models.Bill
+	query := database.DB.Where("user_id = ?", userID)
+	if unpaidOnly {
+		query = query.Where("paid_at IS NULL")
+	}
+	err := query.Order("due_date, id").Find(&bills).Error
+	return bills, err
+}
+
+func (r *scheduleRepository) GetBillByID(id uint, userID uint) (*models.Bill, error) {
+	var bill models.Bill
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&bill).Error
+	return &bill, err
+}
+
+func (r *scheduleRepository) UpdateBill(bill *models.Bill) error {
+	return database.DB.Save(bill).Error
+}
+
+func (r *scheduleRepository) DeleteBill(id uint, userID uint) error {
+	return database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Bill{}).Error
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestScheduleRepository_RecurringItems(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewScheduleRepository()
+
+	rent := &models.RecurringItem{UserID: 1, CategoryID: 1, Type: models.Expense, Amount: 1200, Frequency: models.FrequencyMonthly, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	salary := &models.RecurringItem{UserID: 1, CategoryID: 2, Type: models.Income, Amount: 3000, Frequency: models.FrequencyMonthly, StartDate: time.Date(2024, 6, 25, 0, 0, 0, 0, time.UTC)}
+	for _, item := range []*models.RecurringItem{rent, salary, {UserID: 2, CategoryID: 3, Type: models.Expense, Amount: 1, Frequency: models.FrequencyWeekly, StartDate: time.Now()}} {
+		if err := repo.CreateRecurring(item); err != nil { t.Fatalf("create: %v", err) }
+	}
+
+	items, err := repo.GetRecurringByUserID(1)
+	if err != nil || len(items) != 2 || items[0].ID != salary.ID { t.Fatalf("expected own items oldest first: %v %+v", err, items) }
+	if _, err := repo.GetRecurringByID(rent.ID, 2); err == nil { t.Fatalf("expected item scoped to owner") }
+
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	rent.EndDate = &end
+	if err := repo.UpdateRecurring(rent); err != nil { t.Fatalf("update: %v", err) }
+	if got, _ := repo.GetRecurringByID(rent.ID, 1); got.EndDate == nil || !got.EndDate.Equal(end) { t.Fatalf("expected end date stored, got %+v", got) }
+
+	if err := repo.DeleteRecurring(rent.ID, 1); err != nil { t.Fatalf("delete: %v", err) }
+	if items, _ := repo.GetRecurringByUserID(1); len(items) != 1 { t.Fatalf("expected 1 item left, got %d", len(items)) }
+}
+
+func TestScheduleRepository_Bills(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewScheduleRepository()
+
+	paidAt := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+	insurance := &models.Bill{UserID: 1, CategoryID: 1, Amount: 600, DueDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)}
+	tax := &models.Bill{UserID: 1, CategoryID: 1, Amount: 900, DueDate: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), PaidAt: &paidAt}
+	for _, bill := range []*models.Bill{insurance, tax} {
+		if err := repo.CreateBill(bill); err != nil { t.Fatalf("create: %v", err) }
+	}
+
+	all, err := repo.GetBillsByUserID(1, false)
+	if err != nil || len(all) != 2 || all[0].ID != tax.ID { t.Fatalf("expected bills by due date: %v %+v", err, all) }
+	unpaid, _ := repo.GetBillsByUserID(1, true)
+	if len(unpaid) != 1 || unpaid[0].ID != insurance.ID { t.Fatalf("expected only unpaid bill, got %+v", unpaid) }
+
+	if err := repo.DeleteBill(insurance.ID, 2); err != nil { t.Fatalf("delete: %v", err) }
+	if _, err := repo.GetBillByID(insurance.ID, 1); err != nil { t.Fatalf("expected delete scoped to owner: %v", err) }
+}
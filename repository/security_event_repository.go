@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type SecurityEventRepository interface {
+	Create(event *models.SecurityEvent) error
+	GetByUserID(userID uint, limit int) ([]models.SecurityEvent, error)
+}
+
+type securityEventRepository struct{}
+
+func NewSecurityEventRepository() SecurityEventRepository {
+	return &securityEventRepository{}
+}
+
+func (r *securityEventRepository) Create(event *models.SecurityEvent) error {
+	return database.DB.Create(event).Error
+}
+
+// GetByUserID returns the user's most recent events first
+func (r *securityEventRepository) GetByUserID(userID uint, limit int) ([]models.SecurityEvent, error) {
+	var events []models.SecurityEvent
+	err := database.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/database"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// SessionRepository stores login sessions and the hashed refresh tokens that keep them alive
+type SessionRepository interface {
+	Create(session *models.Session, token *models.RefreshToken) error
+	GetRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	Rotate(used *models.RefreshToken, next *models.RefreshToken, now time.Time) (bool, error)
+	Revoke(sessionID uint, reason string, now time.Time) error
+	RevokeAll(userID uint, reason string, now time.Time) error
+	IsActive(sessionID uint, userID uint, now time.Time) (bool, error)
+}
+
+type sessionRepository struct{}
+
+func NewSessionRepository() SessionRepository {
+	return &sessionRepository{}
+}
+
+// Create stores a new session with its first refresh token
+func (r *sessionRepository) Create(session *models.Session, token *models.RefreshToken) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+// GetRefreshToken looks a token up by hash together with its session
+func (r *sessionRepository) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := database.DB.Preload("Session").Where("token_hash = ?", tokenHash).First(&token).Error
+	return &token, err
+}
+
+// Rotate marks used as spent and stores next in the same session, extending the session to next's
+// expiry. It reports false when used had already been spent, which means the token was replayed.
+func (r *sessionRepository) Rotate(used *models.RefreshToken, next *models.RefreshToken, now time.Time) (bool, error) {
+	rotated := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// the conditional update lets exactly one of two concurrent refreshes win
+		result := tx.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", used.ID).Update("used_at", now)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		next.SessionID = used.SessionID
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.Session{}).Where("id = ?", used.SessionID).
+			Updates(map[string]interface{}{"expires_at": next.ExpiresAt, "last_used_at": now}).Error
+		if err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// Revoke ends a session; revoking it again keeps the first reason
+func (r *sessionRepository) Revoke(sessionID uint, reason string, now time.Time) error {
+	return database.DB.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason}).Error
+}
+
+// RevokeAll ends every open session of the user
+func (r *sessionRepository) RevokeAll(userID uint, reason string, now time.Time) error {
+	return database.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason}).Error
+}
+
+// IsActive reports whether the session belongs to the user and is neither revoked nor expired
+func (r *sessionRepository) IsActive(sessionID uint, userID uint, now time.Time) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, now.UTC()).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestSessionRepository_RotateAndRevoke(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewSessionRepository()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	session := &models.Session{UserID: 1, ExpiresAt: now.Add(time.Hour), LastUsedAt: now}
+	first := &models.RefreshToken{TokenHash: "hash-1", ExpiresAt: now.Add(time.Hour)}
+	if err := repo.Create(session, first); err != nil { t.Fatalf("create: %v", err) }
+	if first.SessionID != session.ID { t.Fatalf("expected the token to join session %d, got %d", session.ID, first.SessionID) }
+
+	stored, err := repo.GetRefreshToken("hash-1")
+	if err != nil || stored.Session.UserID != 1 { t.Fatalf("get refresh token: %v %+v", err, stored) }
+
+	later := now.Add(30 * time.Minute)
+	second := &models.RefreshToken{TokenHash: "hash-2", ExpiresAt: later.Add(time.Hour)}
+	rotated, err := repo.Rotate(stored, second, later)
+	if err != nil || !rotated { t.Fatalf("rotate: %v rotated=%v", err, rotated) }
+	rotated, err = repo.Rotate(stored, &models.RefreshToken{TokenHash: "hash-3", ExpiresAt: later.Add(time.Hour)}, later)
+	if err != nil || rotated { t.Fatalf("expected a replayed token not to rotate: %v rotated=%v", err, rotated) }
+	if _, err := repo.GetRefreshToken("hash-3"); err == nil { t.Fatalf("expected no token stored for the failed rotation") }
+
+	// rotation extends the session, so it outlives the original expiry
+	if active, err := repo.IsActive(session.ID, 1, now.Add(80*time.Minute)); err != nil || !active { t.Fatalf("expected active session: %v", err) }
+	if active, _ := repo.IsActive(session.ID, 2, now); active { t.Fatalf("expected another user's session to be inactive") }
+	if active, _ := repo.IsActive(session.ID, 1, later.Add(2*time.Hour)); active { t.Fatalf("expected an expired session to be inactive") }
+
+	if err := repo.Revoke(session.ID, models.SessionReuse, later); err != nil { t.Fatalf("revoke: %v", err) }
+	if err := repo.Revoke(session.ID, models.SessionLogout, later); err != nil { t.Fatalf("revoke again: %v", err) }
+	stored, _ = repo.GetRefreshToken("hash-2")
+	if stored.Session.RevokedAt == nil || stored.Session.RevokedReason != models.SessionReuse { t.Fatalf("expected the first revocation to stick, got %+v", stored.Session) }
+	if active, _ := repo.IsActive(session.ID, 1, later); active { t.Fatalf("expected a revoked session to be inactive") }
+}
+
+func TestSessionRepository_RevokeAll(t *testing.T) {
+	setupTestDBImport(t)
+	repo := NewSessionRepository()
+	now := time.Now().UTC()
+
+	var ids []uint
+	for i, userID := range []uint{1, 1, 2} {
+		session := &models.Session{UserID: userID, ExpiresAt: now.Add(time.Hour), LastUsedAt: now}
+		if err := repo.Create(session, &models.RefreshToken{TokenHash: string(rune('a' + i)), ExpiresAt: now.Add(time.Hour)}); err != nil { t.Fatalf("create: %v", err) }
+		ids = append(ids, session.ID)
+	}
+
+	if err := repo.RevokeAll(1, models.SessionLogout, now); err != nil { t.Fatalf("revoke all: %v", err) }
+	for i, want := range []bool{false, false, true} {
+		userID := []uint{1, 1, 2}[i]
+		if active, _ := repo.IsActive(ids[i], userID, now); active != want { t.Fatalf("session %d: expected active=%v", ids[i], want) }
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// periodExpression returns SQL that truncates column to the first day of its interval, formatted as YYYY-MM-DD.
+// Timestamps are bucketed in UTC on both Postgres and SQLite; weeks start on Monday.
+func periodExpression(db *gorm.DB, column string, interval models.SummaryInterval) (string, error) {
+	switch db.Dialector.Name() {
+	case "postgres":
+		switch interval {
+		case models.IntervalDay, models.IntervalWeek, models.IntervalMonth, models.IntervalYear:
+			return fmt.Sprintf("to_char(date_trunc('%s', %s AT TIME ZONE 'UTC'), 'YYYY-MM-DD')", interval, column), nil
+		}
+	case "sqlite":
+		switch interval {
+		case models.IntervalDay:
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s)", column), nil
+		case models.IntervalWeek:
+			// %w is 0 for Sunday, so step back (weekday + 6) % 7 days to reach Monday
+			return fmt.Sprintf("date(%s, '-' || ((CAST(strftime('%%w', %s) AS INTEGER) + 6) %% 7) || ' days')", column, column), nil
+		case models.IntervalMonth:
+			return fmt.Sprintf("strftime('%%Y-%%m-01', %s)", column), nil
+		case models.IntervalYear:
+			return fmt.Sprintf("strftime('%%Y-01-01', %s)", column), nil
+		}
+	default:
+		return "", fmt.Errorf("time series are not supported on %s", db.Dialector.Name())
+	}
+	return "", fmt.Errorf("unsupported interval %q", interval)
+}
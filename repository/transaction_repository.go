@@ -1,6 +1,13 @@
 package repository
 
 import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
 	"github.com/aditherevenger/Budget-Tracker-API/database"
 	"github.com/aditherevenger/Budget-Tracker-API/models"
 )
@@ -11,7 +18,16 @@ type TransactionRepository interface {
 	GetByUserID(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error)
 	Update(transaction *models.Transaction) error
 	Delete(id uint, userID uint) error
-	GetSummary(userID uint, startDate, endDate string) (map[string]interface{}, error)
+	GetSummary(userID uint, start, end time.Time) (*models.Summary, error)
+	CreateImport(batch *models.ImportBatch, transactions []models.Transaction) error
+	UndoImport(batchID uint, userID uint) error
+	FindExternalIDs(userID uint, externalIDs []string) ([]string, error)
+	StreamByUserID(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error
+	GetBalanceBefore(userID uint, filter *models.TransactionFilter) (float64, error)
+	GetTimeSeries(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error)
+	GetCategoryTotals(userID uint, start, end time.Time) ([]models.CategoryTotal, error)
+	GetSummaryByCategory(userID uint, start, end time.Time) ([]models.CategoryTotal, error)
+	GetTaxItems(userID uint, start, end time.Time) ([]models.TaxItem, error)
 }
 
 type transactionRepository struct{}
@@ -20,8 +36,16 @@ func NewTransactionRepository() TransactionRepository {
 	return &transactionRepository{}
 }
 
+// Create stores the transaction and adds it to the monthly rollup in one database transaction
 func (r *transactionRepository) Create(transaction *models.Transaction) error {
-	return database.DB.Create(transaction).Error
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		delta := rollupDelta{}
+		delta.add(transaction, 1)
+		return delta.apply(tx)
+	})
 }
 
 func (r *transactionRepository) GetByID(id uint, userID uint) (*models.Transaction, error) {
@@ -43,11 +67,11 @@ func (r *transactionRepository) GetByUserID(userID uint, filter *models.Transact
 	}
 
 	if !filter.StartDate.IsZero() {
-		query = query.Where("date >= ?", filter.StartDate)
+		query = query.Where("date >= ?", filter.StartDate.UTC())
 	}
 
 	if !filter.EndDate.IsZero() {
-		query = query.Where("date <= ?", filter.EndDate)
+		query = query.Where("date <= ?", filter.EndDate.UTC())
 	}
 
 	if filter.Limit > 0 {
@@ -62,46 +86,294 @@ func (r *transactionRepository) GetByUserID(userID uint, filter *models.Transact
 	return transactions, err
 }
 
+// Update saves the transaction and moves its amount in the monthly rollup from the stored month,
+// category and type to the new ones
 func (r *transactionRepository) Update(transaction *models.Transaction) error {
-	return database.DB.Save(transaction).Error
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var stored models.Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, user_id, category_id, type, amount, date").
+			Where("id = ?", transaction.ID).
+			First(&stored).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(transaction).Error; err != nil {
+			return err
+		}
+		delta := rollupDelta{}
+		delta.add(&stored, -1)
+		delta.add(transaction, 1)
+		return delta.apply(tx)
+	})
 }
 
 func (r *transactionRepository) Delete(id uint, userID uint) error {
-	return database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Transaction{}).Error
-}
-
-func (r *transactionRepository) GetSummary(userID uint, startDate, endDate string) (map[string]interface{}, error) {
-    // Income
-    incomeQuery := database.DB.Model(&models.Transaction{}).Where("user_id = ? AND type = ?", userID, models.Income)
-    if startDate != "" {
-        incomeQuery = incomeQuery.Where("date >= ?", startDate)
-    }
-    if endDate != "" {
-        incomeQuery = incomeQuery.Where("date <= ?", endDate)
-    }
-    var totalIncome float64
-    if err := incomeQuery.Select("COALESCE(SUM(amount), 0)").Scan(&totalIncome).Error; err != nil {
-        return nil, err
-    }
-
-    // Expense
-    expenseQuery := database.DB.Model(&models.Transaction{}).Where("user_id = ? AND type = ?", userID, models.Expense)
-    if startDate != "" {
-        expenseQuery = expenseQuery.Where("date >= ?", startDate)
-    }
-    if endDate != "" {
-        expenseQuery = expenseQuery.Where("date <= ?", endDate)
-    }
-    var totalExpense float64
-    if err := expenseQuery.Select("COALESCE(SUM(amount), 0)").Scan(&totalExpense).Error; err != nil {
-        return nil, err
-    }
-
-    net := totalIncome - totalExpense
-
-    return map[string]interface{}{
-        "total_income":  totalIncome,
-        "total_expense": totalExpense,
-        "net_balance":   net,
-    }, nil
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteTransactions(tx, tx.Where("id = ? AND user_id = ?", id, userID))
+	})
+}
+
+// CreateImport stores the batch record and all of its transactions in a single database transaction
+func (r *transactionRepository) CreateImport(batch *models.ImportBatch, transactions []models.Transaction) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+		delta := rollupDelta{}
+		for i := range transactions {
+			transactions[i].UserID = batch.UserID
+			transactions[i].ImportBatchID = &batch.ID
+			delta.add(&transactions[i], 1)
+		}
+		if err := tx.CreateInBatches(transactions, 100).Error; err != nil {
+			return err
+		}
+		return delta.apply(tx)
+	})
+}
+
+// UndoImport removes every transaction created by the batch and marks the batch as undone
+func (r *transactionRepository) UndoImport(batchID uint, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var batch models.ImportBatch
+		if err := tx.Where("id = ? AND user_id = ?", batchID, userID).First(&batch).Error; err != nil {
+			return err
+		}
+		if batch.Status == models.ImportBatchUndone {
+			return errors.New("import has already been undone")
+		}
+
+		if err := deleteTransactions(tx, tx.Where("import_batch_id = ? AND user_id = ?", batchID, userID)); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		batch.Status = models.ImportBatchUndone
+		batch.UndoneAt = &now
+		return tx.Save(&batch).Error
+	})
+}
+
+// externalIDChunkSize keeps each IN list well below the database's bind parameter limit
+const externalIDChunkSize = 1000
+
+// FindExternalIDs returns the subset of externalIDs that already exist for the user
+func (r *transactionRepository) FindExternalIDs(userID uint, externalIDs []string) ([]string, error) {
+	var found []string
+	if len(externalIDs) == 0 {
+		return found, nil
+	}
+	for start := 0; start < len(externalIDs); start += externalIDChunkSize {
+		end := min(start+externalIDChunkSize, len(externalIDs))
+		var chunk []string
+		if err := database.DB.Model(&models.Transaction{}).
+			Where("user_id = ? AND external_id IN ?", userID, externalIDs[start:end]).
+			Pluck("external_id", &chunk).Error; err != nil {
+			return nil, err
+		}
+		found = append(found, chunk...)
+	}
+	return found, nil
+}
+
+// StreamByUserID walks the filtered transactions oldest first, handing them to fn one batch at a time.
+// Batches are fetched by keyset on (date, id) so memory use does not grow with the result size;
+// Limit and Offset are ignored.
+func (r *transactionRepository) StreamByUserID(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+	var lastDate time.Time
+	var lastID uint
+
+	for {
+		query := filteredTransactions(userID, filter).Preload("Category")
+		if lastID != 0 {
+			query = query.Where("(date > ? OR (date = ? AND id > ?))", lastDate, lastDate, lastID)
+		}
+
+		var batch []models.Transaction
+		if err := query.Order("date ASC").Order("id ASC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		lastDate, lastID = last.Date, last.ID
+	}
+}
+
+// GetBalanceBefore returns income minus expense for transactions matching the type and category
+// filters that fall before filter.StartDate; it is the opening balance of a filtered export.
+func (r *transactionRepository) GetBalanceBefore(userID uint, filter *models.TransactionFilter) (float64, error) {
+	if filter.StartDate.IsZero() {
+		return 0, nil
+	}
+
+	var balance float64
+	err := filteredTransactions(userID, &models.TransactionFilter{Type: filter.Type, CategoryID: filter.CategoryID}).
+		Where("date < ?", filter.StartDate.UTC()).
+		Select("COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE -amount END), 0)", models.Income).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// GetTimeSeries returns income and expense totals per interval of loc for transactions in [start, end).
+// Only buckets that contain transactions are returned, ordered by period. The rollup and the SQL grouping
+// are in UTC, so other zones are bucketed here.
+func (r *transactionRepository) GetTimeSeries(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+	if loc != time.UTC {
+		return zonedTimeSeries(userID, interval, start, end, loc)
+	}
+	if (interval == models.IntervalMonth || interval == models.IntervalYear) && isMonthStart(start) && isMonthStart(end) {
+		return rollupTimeSeries(userID, interval, start, end)
+	}
+
+	period, err := periodExpression(database.DB, "date", interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []models.SummaryBucket
+	err = database.DB.Model(&models.Transaction{}).
+		Select(period+" AS period, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) AS income, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) AS expense, "+
+			"COUNT(*) AS count", models.Income, models.Expense).
+		Where("user_id = ? AND date >= ? AND date < ?", userID, start, end).
+		Group("period").
+		Order("period").
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+func zonedTimeSeries(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+	var rows []struct {
+		Date   time.Time
+		Type   models.TransactionType
+		Amount float64
+	}
+	err := database.DB.Model(&models.Transaction{}).
+		Select("date, type, amount").
+		Where("user_id = ? AND date >= ? AND date < ?", userID, start.UTC(), end.UTC()).
+		Order("date").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []models.SummaryBucket
+	for _, row := range rows {
+		period := periodIn(row.Date, interval, loc)
+		if len(buckets) == 0 || buckets[len(buckets)-1].Period != period {
+			buckets = append(buckets, models.SummaryBucket{Period: period})
+		}
+		bucket := &buckets[len(buckets)-1]
+		if row.Type == models.Income {
+			bucket.Income += row.Amount
+		} else {
+			bucket.Expense += row.Amount
+		}
+		bucket.Count++
+	}
+	return buckets, nil
+}
+
+// periodIn formats the first day of t's interval in loc as YYYY-MM-DD; weeks start on Monday
+func periodIn(t time.Time, interval models.SummaryInterval, loc *time.Location) string {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch interval {
+	case models.IntervalWeek:
+		day = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.IntervalMonth:
+		day = day.AddDate(0, 0, 1-day.Day())
+	case models.IntervalYear:
+		day = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, loc)
+	}
+	return day.Format("2006-01-02")
+}
+
+// GetCategoryTotals sums transactions in [start, end) per category and type, largest first. Deleted
+// categories keep their names and colors.
+func (r *transactionRepository) GetCategoryTotals(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+	totals, err := rollupTotals(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if err := labelCategoryTotals(totals); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].Total > totals[j].Total })
+	return totals, nil
+}
+
+// GetTaxItems returns the tax-relevant transactions in [start, end) with their effective tax class: the
+// transaction's own class when set, otherwise its category's. Deleted categories still lend their class.
+func (r *transactionRepository) GetTaxItems(userID uint, start, end time.Time) ([]models.TaxItem, error) {
+	var items []models.TaxItem
+	err := database.DB.Model(&models.Transaction{}).
+		Select("transactions.id AS transaction_id, transactions.date, transactions.description, transactions.type, transactions.amount, "+
+			"COALESCE(categories.name, '') AS category_name, COALESCE(transactions.tax_class, categories.tax_class, '') AS tax_class").
+		Joins("LEFT JOIN categories ON categories.id = transactions.category_id").
+		Where("transactions.user_id = ? AND transactions.date >= ? AND transactions.date < ?", userID, start, end).
+		Where("COALESCE(transactions.tax_class, categories.tax_class, '') <> ''").
+		Order("tax_class, transactions.date, transactions.id").
+		Scan(&items).Error
+	return items, err
+}
+
+func filteredTransactions(userID uint, filter *models.TransactionFilter) *gorm.DB {
+	query := database.DB.Model(&models.Transaction{}).Where("user_id = ?", userID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.CategoryID != 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if !filter.StartDate.IsZero() {
+		query = query.Where("date >= ?", filter.StartDate.UTC())
+	}
+	if !filter.EndDate.IsZero() {
+		query = query.Where("date <= ?", filter.EndDate.UTC())
+	}
+	return query
+}
+
+// GetSummary totals income and expense in [start, end); a zero bound leaves that side open. Whole months
+// are read from the rollup, so every report that has to agree with the summary builds on rollupTotals.
+func (r *transactionRepository) GetSummary(userID uint, start, end time.Time) (*models.Summary, error) {
+	totals, err := rollupTotals(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.Summary{}
+	for _, total := range totals {
+		if total.Type == models.Income {
+			summary.TotalIncome += total.Total
+		} else {
+			summary.TotalExpense += total.Total
+		}
+	}
+	summary.NetBalance = summary.TotalIncome - summary.TotalExpense
+	return summary, nil
+}
+
+// GetSummaryByCategory splits the GetSummary totals per category and type
+func (r *transactionRepository) GetSummaryByCategory(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+	totals, err := rollupTotals(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return totals, labelCategoryTotals(totals)
 }
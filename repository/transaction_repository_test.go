@@ -17,7 +17,7 @@ func setupTestDBTransaction(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open sqlite in-memory: %v", err)
 	}
-	if err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Transaction{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Transaction{}, &models.MonthlyRollup{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	database.DB = db
@@ -85,12 +85,198 @@ func TestTransactionRepository_CRUD_List_Filters_Summary(t *testing.T) {
 	if err != nil { t.Fatalf("re-list: %v", err) }
 	if len(items) != 1 { t.Fatalf("expected 1 tx after delete, got %d", len(items)) }
 
-	// summary (no date filter; function takes strings)
-	sum, err := trepo.GetSummary(u.ID, "", "")
+	// summary (no date filter)
+	sum, err := trepo.GetSummary(u.ID, time.Time{}, time.Time{})
 	if err != nil { t.Fatalf("summary: %v", err) }
-	income := sum["total_income"].(float64)
-	expense := sum["total_expense"].(float64)
-	net := sum["net_balance"].(float64)
+	income, expense, net := sum.TotalIncome, sum.TotalExpense, sum.NetBalance
 	if income < 0 || expense < 0 { t.Fatalf("expected non-negative totals, got income=%v expense=%v", income, expense) }
 	if (income - expense) != net { t.Fatalf("expected net = income - expense, got income=%v expense=%v net=%v", income, expense, net) }
 }
+
+func TestTransactionRepository_StreamByUserID_And_BalanceBefore(t *testing.T) {
+	setupTestDBTransaction(t)
+	trepo := NewTransactionRepository()
+
+	cat := &models.Category{UserID: 1, Name: "Food"}
+	if err := NewCategoryRepository().Create(cat); err != nil { t.Fatalf("create category: %v", err) }
+	day := func(d int) time.Time { return time.Date(2025, 9, d, 0, 0, 0, 0, time.UTC) }
+	// several rows share a date so the keyset has to break ties on id
+	for i, d := range []int{3, 1, 2, 2, 2, 5} {
+		tx := &models.Transaction{UserID: 1, CategoryID: cat.ID, Amount: float64(i + 1), Type: models.Expense, Date: day(d)}
+		if d == 1 { tx.Type = models.Income; tx.Amount = 100 }
+		if err := trepo.Create(tx); err != nil { t.Fatalf("create: %v", err) }
+	}
+	if err := trepo.Create(&models.Transaction{UserID: 2, CategoryID: cat.ID, Amount: 9, Type: models.Expense, Date: day(2)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var seen []models.Transaction
+	batches := 0
+	err := trepo.StreamByUserID(1, &models.TransactionFilter{StartDate: day(2)}, 2, func(batch []models.Transaction) error {
+		batches++
+		seen = append(seen, batch...)
+		return nil
+	})
+	if err != nil { t.Fatalf("stream: %v", err) }
+	if len(seen) != 5 || batches != 3 { t.Fatalf("expected 5 rows in 3 batches, got %d in %d", len(seen), batches) }
+	for i := 1; i < len(seen); i++ {
+		prev, cur := seen[i-1], seen[i]
+		if cur.Date.Before(prev.Date) || (cur.Date.Equal(prev.Date) && cur.ID <= prev.ID) { t.Fatalf("rows out of order at %d: %+v", i, seen) }
+	}
+	if seen[0].Category.Name != "Food" { t.Fatalf("expected category preloaded") }
+
+	balance, err := trepo.GetBalanceBefore(1, &models.TransactionFilter{StartDate: day(2)})
+	if err != nil || balance != 100 { t.Fatalf("expected opening balance 100, got %v (%v)", balance, err) }
+	balance, err = trepo.GetBalanceBefore(1, &models.TransactionFilter{StartDate: day(4), Type: models.Expense})
+	if err != nil || balance != -(1+3+4+5) { t.Fatalf("expected expense-only opening balance, got %v (%v)", balance, err) }
+}
+
+func TestTransactionRepository_GetTimeSeries(t *testing.T) {
+	setupTestDBTransaction(t)
+	trepo := NewTransactionRepository()
+
+	add := func(userID uint, typ models.TransactionType, amount float64, date time.Time) {
+		if err := trepo.Create(&models.Transaction{UserID: userID, CategoryID: 1, Amount: amount, Type: typ, Date: date}); err != nil { t.Fatalf("create: %v", err) }
+	}
+	// 2025-09-07 is a Sunday and 2025-09-08 a Monday
+	add(1, models.Income, 100, time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC))
+	add(1, models.Expense, 30, time.Date(2025, 9, 7, 23, 0, 0, 0, time.UTC))
+	add(1, models.Expense, 20, time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC))
+	add(1, models.Expense, 5, time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC))
+	add(2, models.Expense, 999, time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC))
+
+	start, end := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	weeks, err := trepo.GetTimeSeries(1, models.IntervalWeek, start, end, time.UTC)
+	if err != nil { t.Fatalf("weekly: %v", err) }
+	if len(weeks) != 3 || weeks[0].Period != "2025-09-01" || weeks[0].Income != 100 || weeks[0].Expense != 30 || weeks[1].Period != "2025-09-08" || weeks[2].Period != "2025-09-29" {
+		t.Fatalf("unexpected weekly buckets %+v", weeks)
+	}
+
+	months, err := trepo.GetTimeSeries(1, models.IntervalMonth, start, end, time.UTC)
+	if err != nil { t.Fatalf("monthly: %v", err) }
+	if len(months) != 2 || months[0].Period != "2025-09-01" || months[0].Expense != 50 || months[0].Count != 3 || months[1].Period != "2025-10-01" {
+		t.Fatalf("unexpected monthly buckets %+v", months)
+	}
+
+	years, err := trepo.GetTimeSeries(1, models.IntervalYear, start, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+	if err != nil || len(years) != 1 || years[0].Period != "2025-01-01" || years[0].Count != 3 { t.Fatalf("unexpected yearly buckets %+v (%v)", years, err) }
+
+	days, err := trepo.GetTimeSeries(1, models.IntervalDay, start, end, time.UTC)
+	if err != nil || len(days) != 4 || days[1].Period != "2025-09-07" { t.Fatalf("unexpected daily buckets %+v (%v)", days, err) }
+
+	// in Berlin (UTC+2) the Sunday evening expense falls on Monday morning
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	start, end = time.Date(2025, 9, 1, 0, 0, 0, 0, berlin), time.Date(2025, 11, 1, 0, 0, 0, 0, berlin)
+	weeks, err = trepo.GetTimeSeries(1, models.IntervalWeek, start, end, berlin)
+	if err != nil { t.Fatalf("weekly in Berlin: %v", err) }
+	if len(weeks) != 3 || weeks[0].Period != "2025-09-01" || weeks[0].Expense != 0 || weeks[1].Period != "2025-09-08" || weeks[1].Expense != 50 || weeks[1].Count != 2 {
+		t.Fatalf("unexpected weekly buckets in Berlin %+v", weeks)
+	}
+	months, err = trepo.GetTimeSeries(1, models.IntervalMonth, start, end, berlin)
+	if err != nil || len(months) != 2 || months[0].Period != "2025-09-01" || months[0].Income != 100 || months[0].Expense != 50 || months[1].Expense != 5 { t.Fatalf("unexpected monthly buckets in Berlin %+v (%v)", months, err) }
+}
+
+func TestTransactionRepository_GetCategoryTotals(t *testing.T) {
+	setupTestDBTransaction(t)
+	trepo := NewTransactionRepository()
+	crepo := NewCategoryRepository()
+
+	food := &models.Category{UserID: 1, Name: "Food", Color: "#ff0000"}
+	gone := &models.Category{UserID: 1, Name: "Gone", Color: "#000000"}
+	for _, c := range []*models.Category{food, gone} {
+		if err := crepo.Create(c); err != nil { t.Fatalf("create category: %v", err) }
+	}
+	in := time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)
+	for _, tx := range []models.Transaction{
+		{UserID: 1, CategoryID: food.ID, Amount: 10, Type: models.Expense, Date: in},
+		{UserID: 1, CategoryID: food.ID, Amount: 15, Type: models.Expense, Date: in},
+		{UserID: 1, CategoryID: food.ID, Amount: 7, Type: models.Income, Date: in},
+		{UserID: 1, CategoryID: gone.ID, Amount: 40, Type: models.Expense, Date: in},
+		{UserID: 1, CategoryID: food.ID, Amount: 99, Type: models.Expense, Date: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{UserID: 2, CategoryID: food.ID, Amount: 99, Type: models.Expense, Date: in},
+	} {
+		tx := tx
+		if err := trepo.Create(&tx); err != nil { t.Fatalf("create: %v", err) }
+	}
+	if err := crepo.Delete(gone.ID, 1); err != nil { t.Fatalf("delete category: %v", err) }
+
+	totals, err := trepo.GetCategoryTotals(1, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil { t.Fatalf("totals: %v", err) }
+	if len(totals) != 3 { t.Fatalf("expected 3 category/type rows, got %+v", totals) }
+	if totals[0].CategoryName != "Gone" || totals[0].Total != 40 { t.Fatalf("expected deleted category to keep its name and sort first, got %+v", totals[0]) }
+	if totals[1].CategoryName != "Food" || totals[1].Type != models.Expense || totals[1].Total != 25 || totals[1].Count != 2 || totals[1].CategoryColor != "#ff0000" {
+		t.Fatalf("unexpected food expense row %+v", totals[1])
+	}
+}
+
+func TestTransactionRepository_GetSummaryByCategory_AgreesWithSummary(t *testing.T) {
+	setupTestDBTransaction(t)
+	trepo := NewTransactionRepository()
+	crepo := NewCategoryRepository()
+
+	food := &models.Category{UserID: 1, Name: "Food"}
+	rent := &models.Category{UserID: 1, Name: "Rent", Fixed: true}
+	for _, c := range []*models.Category{food, rent} {
+		if err := crepo.Create(c); err != nil { t.Fatalf("create category: %v", err) }
+	}
+	for _, tx := range []models.Transaction{
+		{UserID: 1, CategoryID: food.ID, Amount: 10, Type: models.Expense, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 5, Type: models.Expense, Date: time.Date(2025, 9, 30, 18, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: rent.ID, Amount: 900, Type: models.Expense, Date: time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 50, Type: models.Income, Date: time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 99, Type: models.Expense, Date: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, CategoryID: food.ID, Amount: 99, Type: models.Expense, Date: time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)},
+	} {
+		tx := tx
+		if err := trepo.Create(&tx); err != nil { t.Fatalf("create: %v", err) }
+	}
+
+	start, end := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	summary, err := trepo.GetSummary(1, start, end)
+	if err != nil { t.Fatalf("summary: %v", err) }
+	totals, err := trepo.GetSummaryByCategory(1, start, end)
+	if err != nil { t.Fatalf("by category: %v", err) }
+
+	sums := map[models.TransactionType]float64{}
+	for _, total := range totals {
+		sums[total.Type] += total.Total
+		if total.Fixed != (total.CategoryID == rent.ID) { t.Fatalf("expected only rent to be fixed, got %+v", total) }
+	}
+	if sums[models.Expense] != 915 || sums[models.Expense] != summary.TotalExpense { t.Fatalf("expense mismatch: %v vs %v", sums[models.Expense], summary.TotalExpense) }
+	if sums[models.Income] != 50 || sums[models.Income] != summary.TotalIncome { t.Fatalf("income mismatch: %v vs %v", sums[models.Income], summary.TotalIncome) }
+}
+
+func TestTransactionRepository_GetTaxItems(t *testing.T) {
+	setupTestDBTransaction(t)
+	trepo := NewTransactionRepository()
+	crepo := NewCategoryRepository()
+
+	office := &models.Category{UserID: 1, Name: "Office", TaxClass: "business"}
+	food := &models.Category{UserID: 1, Name: "Food"}
+	gone := &models.Category{UserID: 1, Name: "Gone", TaxClass: "charity"}
+	for _, c := range []*models.Category{office, food, gone} {
+		if err := crepo.Create(c); err != nil { t.Fatalf("create category: %v", err) }
+	}
+	none, medical := "", "medical"
+	in := time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)
+	for _, tx := range []models.Transaction{
+		{UserID: 1, CategoryID: office.ID, Amount: 10, Type: models.Expense, Date: in, Description: "Paper"},
+		{UserID: 1, CategoryID: office.ID, Amount: 15, Type: models.Expense, Date: in, Description: "Lunch", TaxClass: &none},
+		{UserID: 1, CategoryID: food.ID, Amount: 20, Type: models.Expense, Date: in, Description: "Groceries"},
+		{UserID: 1, CategoryID: food.ID, Amount: 30, Type: models.Expense, Date: in, Description: "Pharmacy", TaxClass: &medical},
+		{UserID: 1, CategoryID: gone.ID, Amount: 40, Type: models.Expense, Date: in, Description: "Donation"},
+		{UserID: 1, CategoryID: office.ID, Amount: 99, Type: models.Expense, Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{UserID: 2, CategoryID: office.ID, Amount: 99, Type: models.Expense, Date: in},
+	} {
+		tx := tx
+		if err := trepo.Create(&tx); err != nil { t.Fatalf("create: %v", err) }
+	}
+	if err := crepo.Delete(gone.ID, 1); err != nil { t.Fatalf("delete category: %v", err) }
+
+	items, err := trepo.GetTaxItems(1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil { t.Fatalf("tax items: %v", err) }
+	if len(items) != 3 { t.Fatalf("expected inherited, overridden and deleted-category items only, got %+v", items) }
+	if items[0].TaxClass != "business" || items[0].Description != "Paper" || items[0].CategoryName != "Office" { t.Fatalf("unexpected first item %+v", items[0]) }
+	if items[1].TaxClass != "charity" || items[1].CategoryName != "Gone" { t.Fatalf("expected deleted category to keep its class, got %+v", items[1]) }
+	if items[2].TaxClass != "medical" || items[2].Amount != 30 || items[2].TransactionID == 0 { t.Fatalf("expected override on an unclassified category, got %+v", items[2]) }
+}
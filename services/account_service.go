@@ -0,0 +1,204 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+var (
+	ErrAccountNotEmpty = errors.New("account already contains data; restore into a fresh account")
+	ErrInvalidArchive  = errors.New("invalid account archive")
+)
+
+const (
+	accountManifestFile = "manifest.json"
+	accountDataFile     = "account.json"
+	// maxArchiveEntrySize caps how much a single archive entry may inflate to
+	maxArchiveEntrySize = 256 << 20
+)
+
+type AccountService interface {
+	ExportAccount(userID uint, w io.Writer) error
+	ImportAccount(userID uint, data io.ReaderAt, size int64) (*models.AccountImportResult, error)
+}
+
+type accountService struct {
+	accountRepo repository.AccountRepository
+}
+
+func NewAccountService(accountRepo repository.AccountRepository) AccountService {
+	return &accountService{
+		accountRepo: accountRepo,
+	}
+}
+
+func (s *accountService) ExportAccount(userID uint, w io.Writer) error {
+	archive, err := s.accountRepo.Export(userID)
+	if err != nil {
+		return err
+	}
+
+	manifest := models.AccountArchiveManifest{
+		Format:      models.AccountArchiveFormat,
+		Version:     models.AccountArchiveVersion,
+		ExportedAt:  time.Now().UTC(),
+		Counts:      archive.Counts(),
+		Attachments: []string{},
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeZipJSON(zw, accountManifestFile, manifest); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, accountDataFile, archive); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *accountService) ImportAccount(userID uint, data io.ReaderAt, size int64) (*models.AccountImportResult, error) {
+	zr, err := zip.NewReader(data, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a zip file", ErrInvalidArchive)
+	}
+
+	var manifest models.AccountArchiveManifest
+	if err := readZipJSON(zr, accountManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != models.AccountArchiveFormat {
+		return nil, fmt.Errorf("%w: unrecognised format %q", ErrInvalidArchive, manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > models.AccountArchiveVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, manifest.Version)
+	}
+
+	var archive models.AccountArchive
+	if err := readZipJSON(zr, accountDataFile, &archive); err != nil {
+		return nil, err
+	}
+	if err := validateArchive(&archive); err != nil {
+		return nil, err
+	}
+
+	hasData, err := s.accountRepo.HasData(userID)
+	if err != nil {
+		return nil, err
+	}
+	if hasData {
+		return nil, ErrAccountNotEmpty
+	}
+
+	if err := s.accountRepo.Restore(userID, &archive); err != nil {
+		return nil, err
+	}
+	return &models.AccountImportResult{Version: manifest.Version, Counts: archive.Counts()}, nil
+}
+
+// validateArchive checks that every reference inside the archive resolves, so a restore never half-fails
+func validateArchive(archive *models.AccountArchive) error {
+	if _, err := loadTimezone(archive.Profile.Timezone); err != nil {
+		return fmt.Errorf("%w: profile has invalid timezone %q", ErrInvalidArchive, archive.Profile.Timezone)
+	}
+
+	categories := make(map[uint]bool)
+	for _, category := range archive.Categories {
+		if category.Name == "" {
+			return fmt.Errorf("%w: category %d has no name", ErrInvalidArchive, category.ID)
+		}
+		categories[category.ID] = true
+	}
+	batches := make(map[uint]bool)
+	for _, batch := range archive.ImportBatches {
+		batches[batch.ID] = true
+	}
+
+	for _, asset := range archive.Assets {
+		if asset.Kind != models.AssetKindAsset && asset.Kind != models.AssetKindLiability {
+			return fmt.Errorf("%w: asset %d has invalid kind %q", ErrInvalidArchive, asset.ID, asset.Kind)
+		}
+	}
+
+	for _, budget := range archive.Budgets {
+		if !categories[budget.CategoryID] {
+			return fmt.Errorf("%w: budget %d references unknown category %d", ErrInvalidArchive, budget.ID, budget.CategoryID)
+		}
+		if budget.Type != models.Income && budget.Type != models.Expense {
+			return fmt.Errorf("%w: budget %d has invalid type %q", ErrInvalidArchive, budget.ID, budget.Type)
+		}
+	}
+
+	for _, item := range archive.RecurringItems {
+		if !categories[item.CategoryID] {
+			return fmt.Errorf("%w: recurring item %d references unknown category %d", ErrInvalidArchive, item.ID, item.CategoryID)
+		}
+		if item.Type != models.Income && item.Type != models.Expense {
+			return fmt.Errorf("%w: recurring item %d has invalid type %q", ErrInvalidArchive, item.ID, item.Type)
+		}
+	}
+	for _, bill := range archive.Bills {
+		if !categories[bill.CategoryID] {
+			return fmt.Errorf("%w: bill %d references unknown category %d", ErrInvalidArchive, bill.ID, bill.CategoryID)
+		}
+	}
+
+	frequencies := make(map[models.ReportFrequency]bool)
+	for _, subscription := range archive.ReportSubscriptions {
+		if subscription.Frequency != models.ReportWeekly && subscription.Frequency != models.ReportMonthly {
+			return fmt.Errorf("%w: report subscription has invalid frequency %q", ErrInvalidArchive, subscription.Frequency)
+		}
+		if frequencies[subscription.Frequency] {
+			return fmt.Errorf("%w: duplicate %s report subscription", ErrInvalidArchive, subscription.Frequency)
+		}
+		frequencies[subscription.Frequency] = true
+	}
+
+	for _, transaction := range archive.Transactions {
+		if !categories[transaction.CategoryID] {
+			return fmt.Errorf("%w: transaction %d references unknown category %d", ErrInvalidArchive, transaction.ID, transaction.CategoryID)
+		}
+		if transaction.Type != models.Income && transaction.Type != models.Expense {
+			return fmt.Errorf("%w: transaction %d has invalid type %q", ErrInvalidArchive, transaction.ID, transaction.Type)
+		}
+		if transaction.ImportBatchID != nil && !batches[*transaction.ImportBatchID] {
+			return fmt.Errorf("%w: transaction %d references unknown import %d", ErrInvalidArchive, transaction.ID, *transaction.ImportBatchID)
+		}
+	}
+	return nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, value interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func readZipJSON(zr *zip.Reader, name string, value interface{}) error {
+	for _, f := range zr.File {
+		if path.Clean(f.Name) != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		defer rc.Close()
+		if err := json.NewDecoder(io.LimitReader(rc, maxArchiveEntrySize)).Decode(value); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: missing %s", ErrInvalidArchive, name)
+}
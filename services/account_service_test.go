@@ -0,0 +1,96 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type mockAccountRepo struct {
+	ExportFn  func(userID uint) (*models.AccountArchive, error)
+	HasDataFn func(userID uint) (bool, error)
+	RestoreFn func(userID uint, archive *models.AccountArchive) error
+}
+
+func (m *mockAccountRepo) Export(userID uint) (*models.AccountArchive, error) { return m.ExportFn(userID) }
+func (m *mockAccountRepo) HasData(userID uint) (bool, error)                  { return m.HasDataFn(userID) }
+func (m *mockAccountRepo) Restore(userID uint, archive *models.AccountArchive) error {
+	return m.RestoreFn(userID, archive)
+}
+
+func sampleArchive() *models.AccountArchive {
+	batchID := uint(7)
+	return &models.AccountArchive{
+		Profile:    models.ArchivedProfile{Email: "a@example.com", FirstName: "A", LastName: "B"},
+		Categories: []models.ArchivedCategory{{ID: 3, Name: "Food"}},
+		Transactions: []models.ArchivedTransaction{
+			{ID: 1, CategoryID: 3, Amount: 10, Type: models.Expense, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), ImportBatchID: &batchID},
+		},
+		ImportBatches: []models.ImportBatch{{ID: 7, Source: models.ImportSourceCSV, Status: models.ImportBatchCommitted}},
+	}
+}
+
+func exportArchive(t *testing.T, archive *models.AccountArchive) []byte {
+	t.Helper()
+	svc := NewAccountService(&mockAccountRepo{ExportFn: func(userID uint) (*models.AccountArchive, error) { return archive, nil }})
+	var buf bytes.Buffer
+	if err := svc.ExportAccount(1, &buf); err != nil { t.Fatalf("export: %v", err) }
+	return buf.Bytes()
+}
+
+func TestAccountService_ExportImportRoundTrip(t *testing.T) {
+	data := exportArchive(t, sampleArchive())
+
+	var restored *models.AccountArchive
+	svc := NewAccountService(&mockAccountRepo{
+		HasDataFn: func(userID uint) (bool, error) { return false, nil },
+		RestoreFn: func(userID uint, archive *models.AccountArchive) error {
+			if userID != 2 { t.Fatalf("expected restore into the caller's account, got %d", userID) }
+			restored = archive
+			return nil
+		},
+	})
+	result, err := svc.ImportAccount(2, bytes.NewReader(data), int64(len(data)))
+	if err != nil { t.Fatalf("import: %v", err) }
+	if result.Version != models.AccountArchiveVersion || result.Counts["transactions"] != 1 { t.Fatalf("unexpected result %+v", result) }
+	if restored.Profile.FirstName != "A" || restored.Transactions[0].CategoryID != 3 { t.Fatalf("unexpected restored archive %+v", restored) }
+}
+
+func TestAccountService_ImportRejectsNonEmptyAccount(t *testing.T) {
+	data := exportArchive(t, sampleArchive())
+	svc := NewAccountService(&mockAccountRepo{
+		HasDataFn: func(userID uint) (bool, error) { return true, nil },
+		RestoreFn: func(userID uint, archive *models.AccountArchive) error { t.Fatalf("restore should not run"); return nil },
+	})
+	if _, err := svc.ImportAccount(2, bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrAccountNotEmpty) {
+		t.Fatalf("expected ErrAccountNotEmpty, got %v", err)
+	}
+}
+
+func TestAccountService_ImportRejectsInvalidArchives(t *testing.T) {
+	svc := NewAccountService(&mockAccountRepo{HasDataFn: func(userID uint) (bool, error) { return false, nil }})
+
+	broken := sampleArchive()
+	broken.Transactions[0].CategoryID = 99
+	futureVersion := func() []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		writeZipJSON(zw, accountManifestFile, models.AccountArchiveManifest{Format: models.AccountArchiveFormat, Version: models.AccountArchiveVersion + 1})
+		zw.Close()
+		return buf.Bytes()
+	}()
+
+	for name, data := range map[string][]byte{
+		"not a zip":          []byte("hello"),
+		"future version":     futureVersion,
+		"dangling reference": exportArchive(t, broken),
+	} {
+		if _, err := svc.ImportAccount(2, bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrInvalidArchive) {
+			t.Fatalf("%s: expected ErrInvalidArchive, got %v", name, err)
+		}
+	}
+}
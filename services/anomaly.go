@@ -0,0 +1,271 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+const (
+	anomalyLookbackMonths = 12
+	anomalyMinSamples     = 5
+	anomalyMaxSamples     = 100
+	anomalySpikeMinMonths = 3
+	// anomalyThreshold is the robust z-score above which an amount is flagged, after Iglewicz and Hoaglin
+	anomalyThreshold = 3.5
+	// anomalyMinSpread keeps the scale from collapsing when history is (nearly) constant, such as a
+	// subscription billed the same amount every month; a 20% price jump then still scores above 3.5
+	anomalyMinSpread = 0.05
+	// madScale and meanADScale turn the median and mean absolute deviation into a standard deviation
+	// for normally distributed amounts
+	madScale    = 1.4826
+	meanADScale = 1.2533
+)
+
+// robustScore returns how many robust standard deviations value lies above the median of history,
+// together with that median. The scale is the median absolute deviation, falling back to the mean
+// absolute deviation when more than half of history is identical.
+func robustScore(value float64, history []float64) (float64, float64) {
+	sorted := append([]float64(nil), history...)
+	sort.Float64s(sorted)
+	median := sortedMedian(sorted)
+
+	deviations := make([]float64, len(sorted))
+	var total float64
+	for i, amount := range sorted {
+		deviations[i] = math.Abs(amount - median)
+		total += deviations[i]
+	}
+	sort.Float64s(deviations)
+
+	scale := sortedMedian(deviations) * madScale
+	if scale == 0 && len(deviations) > 0 {
+		scale = total / float64(len(deviations)) * meanADScale
+	}
+	if floor := math.Abs(median) * anomalyMinSpread; scale < floor {
+		scale = floor
+	}
+	if scale == 0 {
+		return 0, median
+	}
+	return (value - median) / scale, median
+}
+
+func sortedMedian(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// normalizePayee reduces a description to the words that identify the payee, dropping digits and
+// punctuation that differ between charges (card numbers, references, dates).
+func normalizePayee(description string) string {
+	fields := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+type anomalySample struct {
+	date   time.Time
+	amount float64
+}
+
+type anomalyKey struct {
+	basis models.AnomalyBasis
+	key   string
+}
+
+// anomalyDetector scores expenses against those seen before them. Transactions must be fed in date
+// order: Score only looks at history, Add then makes the transaction part of it. Monthly totals are
+// kept per calendar month in loc.
+type anomalyDetector struct {
+	history map[anomalyKey][]anomalySample
+	months  map[uint]map[string]float64
+	names   map[uint]string
+	loc     *time.Location
+}
+
+func newAnomalyDetector(loc *time.Location) *anomalyDetector {
+	return &anomalyDetector{
+		history: make(map[anomalyKey][]anomalySample),
+		months:  make(map[uint]map[string]float64),
+		names:   make(map[uint]string),
+		loc:     loc,
+	}
+}
+
+func anomalyKeys(transaction models.Transaction) []anomalyKey {
+	keys := []anomalyKey{{models.AnomalyByCategory, strconv.FormatUint(uint64(transaction.CategoryID), 10)}}
+	if payee := normalizePayee(transaction.Description); payee != "" {
+		keys = append(keys, anomalyKey{models.AnomalyByPayee, payee})
+	}
+	return keys
+}
+
+// Score flags the transaction when its amount is far above its category's or payee's history within
+// the lookback; the basis with the higher score wins. Unusually small amounts are not flagged.
+func (d *anomalyDetector) Score(transaction models.Transaction) *models.TransactionAnomaly {
+	cutoff := transaction.Date.AddDate(0, -anomalyLookbackMonths, 0)
+	var best *models.TransactionAnomaly
+	for _, key := range anomalyKeys(transaction) {
+		samples := d.history[key]
+		for len(samples) > 0 && samples[0].date.Before(cutoff) {
+			samples = samples[1:]
+		}
+		d.history[key] = samples
+		if len(samples) < anomalyMinSamples {
+			continue
+		}
+
+		amounts := make([]float64, len(samples))
+		for i, sample := range samples {
+			amounts[i] = sample.amount
+		}
+		score, median := robustScore(transaction.Amount, amounts)
+		if score <= anomalyThreshold || (best != nil && score <= best.Score) {
+			continue
+		}
+		best = &models.TransactionAnomaly{Basis: key.basis, Typical: roundCents(median), Score: roundCents(score), Samples: len(samples)}
+	}
+	return best
+}
+
+func (d *anomalyDetector) Add(transaction models.Transaction) {
+	for _, key := range anomalyKeys(transaction) {
+		samples := append(d.history[key], anomalySample{date: transaction.Date, amount: transaction.Amount})
+		if len(samples) > anomalyMaxSamples {
+			samples = samples[len(samples)-anomalyMaxSamples:]
+		}
+		d.history[key] = samples
+	}
+
+	month := transaction.Date.In(d.loc).Format("2006-01")
+	if d.months[transaction.CategoryID] == nil {
+		d.months[transaction.CategoryID] = make(map[string]float64)
+	}
+	d.months[transaction.CategoryID][month] += transaction.Amount
+	d.names[transaction.CategoryID] = transaction.Category.Name
+}
+
+// Spikes compares each category's spending in every month overlapping [start, end) with up to
+// anomalyLookbackMonths before it. Months without spending count as zero once the category has been
+// used, so a category that is normally quiet still spikes.
+func (d *anomalyDetector) Spikes(start, end time.Time) []models.CategorySpike {
+	spikes := []models.CategorySpike{}
+	for month := bucketStart(start, models.IntervalMonth, d.loc); month.Before(end); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		for categoryID, totals := range d.months {
+			total := totals[key]
+			if total <= 0 {
+				continue
+			}
+			first := key
+			for m := range totals {
+				if m < first {
+					first = m
+				}
+			}
+
+			var prior []float64
+			for i := anomalyLookbackMonths; i >= 1; i-- {
+				previous := month.AddDate(0, -i, 0).Format("2006-01")
+				if previous >= first {
+					prior = append(prior, totals[previous])
+				}
+			}
+			if len(prior) < anomalySpikeMinMonths {
+				continue
+			}
+			score, median := robustScore(total, prior)
+			if score <= anomalyThreshold {
+				continue
+			}
+			spikes = append(spikes, models.CategorySpike{
+				CategoryID:   categoryID,
+				CategoryName: d.names[categoryID],
+				Month:        key,
+				Total:        roundCents(total),
+				Typical:      roundCents(median),
+				Score:        roundCents(score),
+				Samples:      len(prior),
+			})
+		}
+	}
+	sort.SliceStable(spikes, func(i, j int) bool {
+		if spikes[i].Month != spikes[j].Month {
+			return spikes[i].Month < spikes[j].Month
+		}
+		if spikes[i].Score != spikes[j].Score {
+			return spikes[i].Score > spikes[j].Score
+		}
+		return spikes[i].CategoryID < spikes[j].CategoryID
+	})
+	return spikes
+}
+
+// detectAnomalies streams the user's expenses from the lookback before start up to end, calling fn
+// with the verdict for every expense in [start, end). The returned detector holds the monthly totals of
+// loc.
+func detectAnomalies(transactionRepo repository.TransactionRepository, userID uint, start, end time.Time, loc *time.Location, fn func(models.Transaction, *models.TransactionAnomaly)) (*anomalyDetector, error) {
+	detector := newAnomalyDetector(loc)
+	filter := &models.TransactionFilter{Type: models.Expense, StartDate: start.AddDate(0, -anomalyLookbackMonths, 0), EndDate: end.Add(-time.Nanosecond)}
+	err := transactionRepo.StreamByUserID(userID, filter, exportBatchSize, func(batch []models.Transaction) error {
+		for _, transaction := range batch {
+			if !transaction.Date.Before(start) {
+				fn(transaction, detector.Score(transaction))
+			}
+			detector.Add(transaction)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return detector, nil
+}
+
+// annotateAnomalies sets Anomaly on the listed expenses that are unusual for the user's history
+func annotateAnomalies(transactionRepo repository.TransactionRepository, userID uint, transactions []models.Transaction) error {
+	var start, end time.Time
+	for _, transaction := range transactions {
+		if transaction.Type != models.Expense {
+			continue
+		}
+		if start.IsZero() || transaction.Date.Before(start) {
+			start = transaction.Date
+		}
+		if transaction.Date.After(end) {
+			end = transaction.Date
+		}
+	}
+	if start.IsZero() {
+		return nil
+	}
+
+	// only the scores are used, which don't depend on the months, so their zone doesn't matter
+	anomalies := make(map[uint]*models.TransactionAnomaly)
+	_, err := detectAnomalies(transactionRepo, userID, start, end.Add(time.Nanosecond), time.UTC, func(transaction models.Transaction, anomaly *models.TransactionAnomaly) {
+		if anomaly != nil {
+			anomalies[transaction.ID] = anomaly
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for i := range transactions {
+		transactions[i].Anomaly = anomalies[transactions[i].ID]
+	}
+	return nil
+}
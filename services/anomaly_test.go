@@ -0,0 +1,115 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestRobustScore(t *testing.T) {
+	flat := []float64{9.99, 9.99, 9.99, 9.99, 9.99, 9.99}
+	if score, median := robustScore(19.98, flat); median != 9.99 || math.Abs(score-20) > 0.01 { t.Fatalf("expected a doubled subscription to score 20, got %v (median %v)", score, median) }
+	if score, _ := robustScore(10.49, flat); score > anomalyThreshold { t.Fatalf("expected a 5%% price change not to be flagged, got %v", score) }
+	// one outlier in the history must not hide the next one
+	if score, median := robustScore(300, []float64{50, 55, 60, 65, 70, 500}); median != 62.5 || score < anomalyThreshold { t.Fatalf("expected 300 to be flagged despite an earlier outlier, got %v (median %v)", score, median) }
+	if score, _ := robustScore(5, []float64{0, 0, 0}); score != 0 { t.Fatalf("expected no score without spread, got %v", score) }
+}
+
+func TestNormalizePayee(t *testing.T) {
+	if got := normalizePayee("NETFLIX.COM 866-579 *REF123"); got != "netflix com ref" { t.Fatalf("unexpected payee %q", got) }
+	if got := normalizePayee("1234"); got != "" { t.Fatalf("expected digits only to have no payee, got %q", got) }
+}
+
+// anomalyFixture is six months of groceries and entertainment, then an October with a doubled
+// subscription charge and one very large grocery bill
+func anomalyFixture() []models.Transaction {
+	groceries := models.Category{ID: 1, Name: "Groceries"}
+	fun := models.Category{ID: 2, Name: "Entertainment"}
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 12, 0, 0, 0, time.UTC) }
+	shop := [][2]float64{{50, 70}, {60, 65}, {55, 80}, {75, 52}, {58, 66}, {62, 71}}
+	cinema := []float64{12, 30, 25, 8, 40, 18}
+
+	var transactions []models.Transaction
+	id := uint(0)
+	add := func(category models.Category, amount float64, date time.Time, description string) {
+		id++
+		transactions = append(transactions, models.Transaction{ID: id, CategoryID: category.ID, Category: category, Type: models.Expense, Amount: amount, Date: date, Description: description})
+	}
+	for i, month := range []time.Month{time.April, time.May, time.June, time.July, time.August, time.September} {
+		add(fun, 9.99, day(month, 3), "NETFLIX.COM 1234")
+		add(groceries, shop[i][0], day(month, 5), "Market")
+		add(fun, cinema[i], day(month, 15), "Cinema")
+		add(groceries, shop[i][1], day(month, 20), "Market")
+	}
+	add(groceries, 60, day(time.October, 2), "Market")
+	add(fun, 19.98, day(time.October, 3), "NETFLIX.COM 9876")
+	add(groceries, 400, day(time.October, 10), "Costco")
+	return transactions
+}
+
+func anomalyStream(transactions []models.Transaction, check func(filter *models.TransactionFilter)) func(uint, *models.TransactionFilter, int, func([]models.Transaction) error) error {
+	return func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+		if check != nil { check(filter) }
+		var batch []models.Transaction
+		for _, transaction := range transactions {
+			if !transaction.Date.Before(filter.StartDate) && !transaction.Date.After(filter.EndDate) { batch = append(batch, transaction) }
+		}
+		return fn(batch)
+	}
+}
+
+func TestInsightsService_GetAnomalies(t *testing.T) {
+	mTxn := &mockTxnRepo{StreamFn: anomalyStream(anomalyFixture(), func(filter *models.TransactionFilter) {
+		if filter.Type != models.Expense || !filter.StartDate.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected filter %+v", filter) }
+	})}
+	svc := NewInsightsService(mTxn, userRepoIn("UTC"))
+
+	report, err := svc.GetAnomalies(1, "2026-10-01", "2026-10-31")
+	if err != nil { t.Fatalf("anomalies: %v", err) }
+	if len(report.Transactions) != 2 { t.Fatalf("expected 2 flagged transactions, got %+v", report.Transactions) }
+
+	costco, netflix := report.Transactions[0], report.Transactions[1]
+	if costco.Description != "Costco" || costco.Anomaly.Basis != models.AnomalyByCategory || costco.Anomaly.Typical != 62 || costco.Anomaly.Samples != 13 { t.Fatalf("unexpected grocery anomaly %+v", costco.Anomaly) }
+	if netflix.Amount != 19.98 || netflix.Anomaly.Basis != models.AnomalyByPayee || netflix.Anomaly.Typical != 9.99 || netflix.Anomaly.Score != 20 { t.Fatalf("unexpected subscription anomaly %+v", netflix.Anomaly) }
+
+	if len(report.CategorySpikes) != 1 { t.Fatalf("expected only groceries to spike, got %+v", report.CategorySpikes) }
+	if spike := report.CategorySpikes[0]; spike.CategoryName != "Groceries" || spike.Month != "2026-10" || spike.Total != 460 || spike.Samples != 6 { t.Fatalf("unexpected spike %+v", spike) }
+}
+
+func TestAnomalyDetector_SpikesInUserTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil { t.Fatalf("load zone: %v", err) }
+	detector := newAnomalyDetector(tokyo)
+	for month := time.April; month <= time.September; month++ {
+		detector.Add(models.Transaction{CategoryID: 1, Amount: 100, Date: time.Date(2026, month, 15, 12, 0, 0, 0, tokyo), Category: models.Category{Name: "Food"}})
+	}
+	// early on 1 October in Tokyo is still 30 September in UTC
+	detector.Add(models.Transaction{CategoryID: 1, Amount: 1000, Date: time.Date(2026, 10, 1, 3, 0, 0, 0, tokyo), Category: models.Category{Name: "Food"}})
+
+	spikes := detector.Spikes(time.Date(2026, 10, 1, 0, 0, 0, 0, tokyo), time.Date(2026, 11, 1, 0, 0, 0, 0, tokyo))
+	if len(spikes) != 1 || spikes[0].Month != "2026-10" || spikes[0].Total != 1000 || spikes[0].Samples != 6 { t.Fatalf("expected one October spike, got %+v", spikes) }
+}
+
+func TestInsightsService_GetAnomalies_InvalidRange(t *testing.T) {
+	svc := NewInsightsService(&mockTxnRepo{}, userRepoIn("UTC"))
+	if _, err := svc.GetAnomalies(1, "2026-10-31", "2026-10-01"); err == nil { t.Fatalf("expected an invalid range error") }
+}
+
+func TestTransactionService_GetTransactionsAnnotatesAnomalies(t *testing.T) {
+	fixture := anomalyFixture()
+	october := append([]models.Transaction{{ID: 99, Type: models.Income, Amount: 5000, Date: time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)}}, fixture[len(fixture)-3:]...)
+	mTxn := &mockTxnRepo{
+		ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) { return october, nil },
+		StreamFn: anomalyStream(fixture, func(filter *models.TransactionFilter) {
+			if !filter.StartDate.Equal(time.Date(2025, 10, 2, 12, 0, 0, 0, time.UTC)) { t.Fatalf("expected history from a year before the oldest listed expense, got %v", filter.StartDate) }
+		}),
+	}
+	svc := NewTransactionService(mTxn, &mockCategoryRepo{}, userRepoIn("UTC"))
+
+	transactions, err := svc.GetTransactions(1, &models.TransactionFilter{})
+	if err != nil { t.Fatalf("list: %v", err) }
+	if transactions[0].Anomaly != nil || transactions[1].Anomaly != nil { t.Fatalf("expected income and the ordinary purchase unflagged") }
+	if transactions[2].Anomaly == nil || transactions[3].Anomaly == nil { t.Fatalf("expected both unusual expenses annotated, got %+v", transactions) }
+}
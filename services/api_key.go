@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+// maxAPIKeys is how many keys a user can have at once
+const maxAPIKeys = 20
+
+var (
+	ErrInvalidAPIKeyScope = errors.New("invalid API key scope")
+	ErrTooManyAPIKeys     = fmt.Errorf("a user can have at most %d API keys", maxAPIKeys)
+)
+
+type APIKeyService interface {
+	CreateKey(userID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error)
+	GetKeys(userID uint) ([]models.APIKeyResponse, error)
+	DeleteKey(userID uint, id uint) error
+}
+
+type apiKeyService struct {
+	keyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(keyRepo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{keyRepo: keyRepo}
+}
+
+// CreateKey mints a key with the requested scopes. The key is only returned here; afterwards just its
+// hash and prefix are known.
+func (s *apiKeyService) CreateKey(userID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			return nil, fmt.Errorf("%w %q, use one of %s", ErrInvalidAPIKeyScope, scope, strings.Join(models.APIKeyScopes, ", "))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes)
+
+	existing, err := s.keyRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPIKeys {
+		return nil, ErrTooManyAPIKeys
+	}
+
+	token, _, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	secret := models.APIKeyPrefix + token
+	key := &models.APIKey{
+		UserID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		Prefix:  secret[:len(models.APIKeyPrefix)+8],
+		KeyHash: utils.HashToken(secret),
+		Scopes:  strings.Join(scopes, " "),
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.keyRepo.Create(key); err != nil {
+		return nil, err
+	}
+	return &models.CreatedAPIKey{APIKeyResponse: apiKeyResponse(key), Key: secret}, nil
+}
+
+func (s *apiKeyService) GetKeys(userID uint) ([]models.APIKeyResponse, error) {
+	keys, err := s.keyRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		responses = append(responses, apiKeyResponse(&keys[i]))
+	}
+	return responses, nil
+}
+
+// DeleteKey revokes the key; requests made with it fail from then on
+func (s *apiKeyService) DeleteKey(userID uint, id uint) error {
+	deleted, err := s.keyRepo.Delete(id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func apiKeyResponse(key *models.APIKey) models.APIKeyResponse {
+	return models.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Fields(key.Scopes),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
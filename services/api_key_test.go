@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+type fakeAPIKeyRepo struct{ keys []models.APIKey }
+
+func (f *fakeAPIKeyRepo) Create(key *models.APIKey) error {
+	key.ID = uint(len(f.keys) + 1)
+	f.keys = append(f.keys, *key)
+	return nil
+}
+
+func (f *fakeAPIKeyRepo) GetByUserID(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	for _, key := range f.keys {
+		if key.UserID == userID { keys = append(keys, key) }
+	}
+	return keys, nil
+}
+
+func (f *fakeAPIKeyRepo) Delete(id uint, userID uint) (bool, error) {
+	for i, key := range f.keys {
+		if key.ID == id && key.UserID == userID { f.keys = append(f.keys[:i], f.keys[i+1:]...); return true, nil }
+	}
+	return false, nil
+}
+
+func (f *fakeAPIKeyRepo) DeleteByUserID(userID uint) error {
+	var kept []models.APIKey
+	for _, key := range f.keys {
+		if key.UserID != userID { kept = append(kept, key) }
+	}
+	f.keys = kept
+	return nil
+}
+
+func (f *fakeAPIKeyRepo) Use(keyHash string, now time.Time) (*models.APIKey, error) {
+	for _, key := range f.keys {
+		if key.KeyHash == keyHash { return &key, nil }
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+var _ repository.APIKeyRepository = (*fakeAPIKeyRepo)(nil)
+
+func TestAPIKeyService_CreateKey(t *testing.T) {
+	repo := &fakeAPIKeyRepo{}
+	svc := NewAPIKeyService(repo)
+	days := 30
+
+	created, err := svc.CreateKey(3, &models.CreateAPIKeyRequest{Name: " nightly sync ", Scopes: []string{"transactions:write", "reports:read", "transactions:write"}, ExpiresInDays: &days})
+	if err != nil { t.Fatalf("create: %v", err) }
+	if !strings.HasPrefix(created.Key, models.APIKeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) || len(created.Prefix) != 11 { t.Fatalf("unexpected key %q prefix %q", created.Key, created.Prefix) }
+	if strings.Join(created.Scopes, " ") != "reports:read transactions:write" || created.Name != "nightly sync" { t.Fatalf("unexpected key %+v", created.APIKeyResponse) }
+	if created.ExpiresAt == nil || created.ExpiresAt.Sub(time.Now()) < 29*24*time.Hour { t.Fatalf("expected a 30 day expiry, got %v", created.ExpiresAt) }
+
+	stored := repo.keys[0]
+	if stored.KeyHash != utils.HashToken(created.Key) || strings.Contains(stored.KeyHash, created.Key) { t.Fatalf("expected only the hash to be stored, got %+v", stored) }
+
+	keys, _ := svc.GetKeys(3)
+	if len(keys) != 1 || keys[0].Prefix != created.Prefix { t.Fatalf("unexpected keys %+v", keys) }
+	if keys, _ := svc.GetKeys(4); keys == nil || len(keys) != 0 { t.Fatalf("expected an empty list for another user, got %+v", keys) }
+}
+
+func TestAPIKeyService_CreateKey_Invalid(t *testing.T) {
+	repo := &fakeAPIKeyRepo{}
+	svc := NewAPIKeyService(repo)
+	if _, err := svc.CreateKey(3, &models.CreateAPIKeyRequest{Name: "x", Scopes: []string{"profile:write"}}); !errors.Is(err, ErrInvalidAPIKeyScope) { t.Fatalf("expected ErrInvalidAPIKeyScope, got %v", err) }
+
+	for i := 0; i < maxAPIKeys; i++ { repo.Create(&models.APIKey{UserID: 3}) }
+	if _, err := svc.CreateKey(3, &models.CreateAPIKeyRequest{Name: "x", Scopes: []string{models.ScopeReportsRead}}); !errors.Is(err, ErrTooManyAPIKeys) { t.Fatalf("expected ErrTooManyAPIKeys, got %v", err) }
+}
+
+func TestAPIKeyService_DeleteKey(t *testing.T) {
+	repo := &fakeAPIKeyRepo{}
+	svc := NewAPIKeyService(repo)
+	created, _ := svc.CreateKey(3, &models.CreateAPIKeyRequest{Name: "x", Scopes: []string{models.ScopeReportsRead}})
+	if err := svc.DeleteKey(4, created.ID); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected another user's key to be out of reach, got %v", err) }
+	if err := svc.DeleteKey(3, created.ID); err != nil { t.Fatalf("delete: %v", err) }
+	if len(repo.keys) != 0 { t.Fatalf("expected the key to be gone") }
+}
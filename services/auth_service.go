@@ -3,25 +3,62 @@ package services
 import (
 	"errors"
 	"gorm.io/gorm"
+	"log"
+	"sync"
+	"time"
 
+	"github.com/aditherevenger/Budget-Tracker-API/config"
 	"github.com/aditherevenger/Budget-Tracker-API/models"
 	"github.com/aditherevenger/Budget-Tracker-API/repository"
 	"github.com/aditherevenger/Budget-Tracker-API/utils"
 )
 
+// mfaChallengeTTL is how long a user has to enter the second factor after the password
+const mfaChallengeTTL = 5 * time.Minute
+
+// dummyPasswordHash is compared against when no account has the email, so the response takes as long
+// as for a wrong password
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := utils.HashPassword("no-account-has-this-password")
+	return hash
+})
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; the session has been revoked")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired two-factor challenge, please log in again")
+)
+
 type AuthService interface {
 	Register(req *models.UserRegistrationRequest) (*models.UserResponse, error)
-	Login(req *models.UserLoginRequest) (string, *models.UserResponse, error)
+	Login(req *models.UserLoginRequest, clientIP string) (*models.LoginResult, error)
+	VerifyMFA(req *models.MFAVerifyRequest, clientIP string) (*models.LoginResult, error)
+	Refresh(refreshToken string) (*models.AuthTokens, error)
+	Logout(req *models.LogoutRequest) error
 	GetUserProfile(userID uint) (*models.UserResponse, error)
+	UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.UserResponse, error)
 }
 
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo     repository.UserRepository
+	sessionRepo  repository.SessionRepository
+	verification EmailVerificationService
+	mfa          MFAService
+	throttle     LoginThrottle
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
+func NewAuthService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, verification EmailVerificationService, mfa MFAService, throttle LoginThrottle, cfg config.JWTConfig) AuthService {
 	return &authService{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		verification: verification,
+		mfa:          mfa,
+		throttle:     throttle,
+		accessTTL:    time.Duration(cfg.AccessTokenMinutes) * time.Minute,
+		refreshTTL:   time.Duration(cfg.RefreshTokenDays) * 24 * time.Hour,
 	}
 }
 
@@ -32,6 +69,11 @@ func (s *authService) Register(req *models.UserRegistrationRequest) (*models.Use
 		return nil, errors.New("user with this email already exists")
 	}
 
+	timezone, err := loadTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -44,6 +86,7 @@ func (s *authService) Register(req *models.UserRegistrationRequest) (*models.Use
 		Password:  hashedPassword,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
+		Timezone:  timezone.String(),
 	}
 
 	err = s.userRepo.Create(user)
@@ -51,42 +94,200 @@ func (s *authService) Register(req *models.UserRegistrationRequest) (*models.Use
 		return nil, err
 	}
 
-	return &models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
-	}, nil
+	// A failed verification email doesn't fail the registration; the user can ask for another one
+	if err := s.verification.SendVerification(user); err != nil && !errors.Is(err, ErrMailerNotConfigured) {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+
+	return userResponse(user), nil
 }
 
-func (s *authService) Login(req *models.UserLoginRequest) (string, *models.UserResponse, error) {
+// Login starts a new session and returns its first access and refresh token. Users with two-factor
+// authentication get a challenge instead, which VerifyMFA trades for the session. Failed attempts are
+// counted per account and per client address, and an unknown email fails just like a wrong password.
+func (s *authService) Login(req *models.UserLoginRequest, clientIP string) (*models.LoginResult, error) {
+	if err := s.throttle.Check(req.Email, clientIP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByEmail(req.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.CheckPassword(req.Password, dummyPasswordHash())
+		return nil, s.loginFailed(req.Email, clientIP, 0, ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.CheckPassword(req.Password, user.Password) {
+		return nil, s.loginFailed(req.Email, clientIP, user.ID, ErrInvalidCredentials)
+	}
+
+	enabled, err := s.mfa.Enabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		// the failures are only cleared once the second factor is in too
+		challenge, err := utils.GenerateChallengeToken(user.ID, mfaChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResult{
+			Challenge: &models.MFAChallenge{MFARequired: true, MFAToken: challenge, ExpiresIn: int(mfaChallengeTTL.Seconds())},
+		}, nil
+	}
+
+	if err := s.throttle.Success(req.Email); err != nil {
+		return nil, err
+	}
+	return s.startSession(user)
+}
+
+// VerifyMFA completes a challenged login with a code from the authenticator or a recovery code. Wrong
+// codes count towards the account's lockout like wrong passwords.
+func (s *authService) VerifyMFA(req *models.MFAVerifyRequest, clientIP string) (*models.LoginResult, error) {
+	userID, err := utils.ValidateChallengeToken(req.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", nil, errors.New("invalid credentials")
+			return nil, ErrInvalidMFAChallenge
 		}
-		return "", nil, err
+		return nil, err
+	}
+	if err := s.throttle.Check(user.Email, clientIP); err != nil {
+		return nil, err
 	}
 
-	if !utils.CheckPassword(req.Password, user.Password) {
-		return "", nil, errors.New("invalid credentials")
+	if err := s.mfa.Authenticate(user.ID, req.Code, req.RecoveryCode); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			return nil, s.loginFailed(user.Email, clientIP, user.ID, err)
+		}
+		return nil, err
+	}
+	if err := s.throttle.Success(user.Email); err != nil {
+		return nil, err
+	}
+	return s.startSession(user)
+}
+
+// loginFailed counts the failure and returns cause, unless counting fails
+func (s *authService) loginFailed(email, clientIP string, userID uint, cause error) error {
+	if err := s.throttle.Failure(email, clientIP, userID); err != nil {
+		return err
+	}
+	return cause
+}
+
+func (s *authService) startSession(user *models.User) (*models.LoginResult, error) {
+	now := time.Now()
+	refreshToken, hash, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.Session{UserID: user.ID, ExpiresAt: now.Add(s.refreshTTL), LastUsedAt: now}
+	if err := s.sessionRepo.Create(session, &models.RefreshToken{TokenHash: hash, ExpiresAt: session.ExpiresAt}); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(user, session.ID, refreshToken)
+	if err != nil {
+		return nil, err
 	}
+	return &models.LoginResult{Tokens: tokens, User: userResponse(user)}, nil
+}
 
-	token, err := utils.GenerateToken(user.ID, user.Email)
+// Refresh trades a refresh token for a new access and refresh token. Every refresh token works once:
+// presenting one that was already traded in means it leaked, so the session is revoked.
+func (s *authService) Refresh(refreshToken string) (*models.AuthTokens, error) {
+	now := time.Now()
+	stored, err := s.sessionRepo.GetRefreshToken(utils.HashToken(refreshToken))
 	if err != nil {
-		return "", nil, err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if stored.Session.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReused(stored.SessionID, now)
+	}
+	if !now.Before(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
 	}
 
-	userResponse := &models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
+	user, err := s.userRepo.GetByID(stored.Session.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
 	}
 
-	return token, userResponse, nil
+	next, hash, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.sessionRepo.Rotate(stored, &models.RefreshToken{TokenHash: hash, ExpiresAt: now.Add(s.refreshTTL)}, now)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, s.revokeReused(stored.SessionID, now)
+	}
+	return s.issueTokens(user, stored.SessionID, next)
+}
+
+func (s *authService) revokeReused(sessionID uint, now time.Time) error {
+	if err := s.sessionRepo.Revoke(sessionID, models.SessionReuse, now); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout revokes the refresh token's session, or all of the user's sessions. Access tokens of a revoked
+// session stop working immediately. Only a token that could still refresh is accepted; a spent one is
+// treated as reuse, as in Refresh, and revokes just its own session.
+func (s *authService) Logout(req *models.LogoutRequest) error {
+	now := time.Now()
+	stored, err := s.sessionRepo.GetRefreshToken(utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	if stored.Session.RevokedAt != nil {
+		return ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return s.revokeReused(stored.SessionID, now)
+	}
+	if !now.Before(stored.ExpiresAt) {
+		return ErrInvalidRefreshToken
+	}
+	if req.All {
+		return s.sessionRepo.RevokeAll(stored.Session.UserID, models.SessionLogout, now)
+	}
+	return s.sessionRepo.Revoke(stored.SessionID, models.SessionLogout, now)
+}
+
+func (s *authService) issueTokens(user *models.User, sessionID uint, refreshToken string) (*models.AuthTokens, error) {
+	token, err := utils.GenerateToken(user.ID, user.Email, sessionID, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthTokens{
+		Token:        token,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
 }
 
 func (s *authService) GetUserProfile(userID uint) (*models.UserResponse, error) {
@@ -95,11 +296,47 @@ func (s *authService) GetUserProfile(userID uint) (*models.UserResponse, error)
 		return nil, err
 	}
 
+	return userResponse(user), nil
+}
+
+func (s *authService) UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.Timezone != nil {
+		timezone, err := loadTimezone(*req.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		user.Timezone = timezone.String()
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return userResponse(user), nil
+}
+
+func userResponse(user *models.User) *models.UserResponse {
+	timezone := user.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
 	return &models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
-	}, nil
+		ID:              user.ID,
+		Email:           user.Email,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Timezone:        timezone,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+	}
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"testing"
 
 	"gorm.io/gorm"
@@ -31,17 +32,19 @@ func TestAuthService_Register_Success(t *testing.T) {
 		GetByEmailFn: func(email string) (*models.User, error) { return nil, gorm.ErrRecordNotFound },
 		CreateFn: func(user *models.User) error { user.ID = 1; return nil },
 	}
-	svc := NewAuthService(m)
+	verification := &stubVerification{}
+	svc := NewAuthService(m, newFakeSessionRepo(), verification, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
 	resp, err := svc.Register(&models.UserRegistrationRequest{Email: "jane@example.com", Password: "Pass1234", FirstName: "Jane", LastName: "Doe"})
 	if err != nil { t.Fatalf("Register error: %v", err) }
-	if resp.Email != "jane@example.com" || resp.ID == 0 { t.Fatalf("unexpected resp: %+v", resp) }
+	if resp.Email != "jane@example.com" || resp.ID == 0 || resp.EmailVerifiedAt != nil { t.Fatalf("unexpected resp: %+v", resp) }
+	if len(verification.sent) != 1 || verification.sent[0].ID != resp.ID { t.Fatalf("expected a verification email for the new user, got %+v", verification.sent) }
 }
 
 func TestAuthService_Register_Duplicate(t *testing.T) {
 	m := &mockUserRepo{
 		GetByEmailFn: func(email string) (*models.User, error) { return &models.User{ID: 99, Email: email}, nil },
 	}
-	svc := NewAuthService(m)
+	svc := NewAuthService(m, newFakeSessionRepo(), &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
 	if _, err := svc.Register(&models.UserRegistrationRequest{Email: "dup@example.com", Password: "x", FirstName: "A", LastName: "B"}); err == nil {
 		t.Fatalf("expected error for duplicate email")
 	}
@@ -52,26 +55,60 @@ func TestAuthService_Login_Success(t *testing.T) {
 	m := &mockUserRepo{
 		GetByEmailFn: func(email string) (*models.User, error) { return &models.User{ID: 3, Email: email, Password: hashed, FirstName: "J", LastName: "D"}, nil },
 	}
-	svc := NewAuthService(m)
-	token, user, err := svc.Login(&models.UserLoginRequest{Email: "jane@example.com", Password: "Pass1234"})
+	svc := NewAuthService(m, newFakeSessionRepo(), &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
+	result, err := svc.Login(&models.UserLoginRequest{Email: "jane@example.com", Password: "Pass1234"}, "10.0.0.1")
 	if err != nil { t.Fatalf("login error: %v", err) }
-	if token == "" { t.Fatalf("expected token, got empty") }
+	tokens, user := result.Tokens, result.User
+	if tokens.Token == "" || tokens.RefreshToken == "" || tokens.ExpiresIn != 900 { t.Fatalf("unexpected tokens: %+v", tokens) }
 	if user.ID != 3 || user.Email != "jane@example.com" { t.Fatalf("unexpected user: %+v", user) }
 }
 
 func TestAuthService_Login_InvalidPassword(t *testing.T) {
 	hashed, _ := utils.HashPassword("CorrectPass")
 	m := &mockUserRepo{ GetByEmailFn: func(email string) (*models.User, error) { return &models.User{ID: 3, Email: email, Password: hashed}, nil } }
-	svc := NewAuthService(m)
-	if _, _, err := svc.Login(&models.UserLoginRequest{Email: "jane@example.com", Password: "wrong"}); err == nil {
+	svc := NewAuthService(m, newFakeSessionRepo(), &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
+	if _, err := svc.Login(&models.UserLoginRequest{Email: "jane@example.com", Password: "wrong"}, "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
 		t.Fatalf("expected invalid credentials error")
 	}
 }
 
 func TestAuthService_GetUserProfile_Success(t *testing.T) {
 	m := &mockUserRepo{ GetByIDFn: func(id uint) (*models.User, error) { return &models.User{ID: id, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe"}, nil } }
-	svc := NewAuthService(m)
+	svc := NewAuthService(m, newFakeSessionRepo(), &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
 	resp, err := svc.GetUserProfile(42)
 	if err != nil { t.Fatalf("GetUserProfile error: %v", err) }
 	if resp.ID != 42 || resp.Email != "jane@example.com" { t.Fatalf("unexpected resp: %+v", resp) }
 }
+
+func TestAuthService_Register_Timezone(t *testing.T) {
+	var created *models.User
+	m := &mockUserRepo{
+		GetByEmailFn: func(email string) (*models.User, error) { return nil, gorm.ErrRecordNotFound },
+		CreateFn: func(user *models.User) error { created = user; return nil },
+	}
+	svc := NewAuthService(m, newFakeSessionRepo(), &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
+	resp, err := svc.Register(&models.UserRegistrationRequest{Email: "jane@example.com", Password: "Pass1234", FirstName: "Jane", LastName: "Doe"})
+	if err != nil || resp.Timezone != "UTC" || created.Timezone != "UTC" { t.Fatalf("expected UTC by default, got %+v %v", resp, err) }
+	if _, err := svc.Register(&models.UserRegistrationRequest{Email: "jane@example.com", Password: "Pass1234", FirstName: "Jane", LastName: "Doe", Timezone: "Mars/Olympus"}); !errors.Is(err, ErrInvalidTimezone) { t.Fatalf("expected ErrInvalidTimezone, got %v", err) }
+}
+
+func TestAuthService_UpdateProfile(t *testing.T) {
+	stored := &models.User{ID: 4, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Timezone: "UTC"}
+	updates := 0
+	m := &mockUserRepo{
+		GetByIDFn: func(id uint) (*models.User, error) { u := *stored; return &u, nil },
+		UpdateFn: func(user *models.User) error { updates++; stored = user; return nil },
+	}
+	svc := NewAuthService(m, newFakeSessionRepo(), &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
+
+	name, timezone := "Janet", "Asia/Kolkata"
+	resp, err := svc.UpdateProfile(4, &models.UpdateProfileRequest{FirstName: &name, Timezone: &timezone})
+	if err != nil { t.Fatalf("update: %v", err) }
+	if resp.FirstName != "Janet" || resp.LastName != "Doe" || resp.Timezone != "Asia/Kolkata" || stored.Timezone != "Asia/Kolkata" { t.Fatalf("unexpected profile %+v", resp) }
+
+	for _, invalid := range []string{"Local", "GMT+2", "Europe/Atlantis"} {
+		invalid := invalid
+		if _, err := svc.UpdateProfile(4, &models.UpdateProfileRequest{Timezone: &invalid}); !errors.Is(err, ErrInvalidTimezone) { t.Fatalf("expected ErrInvalidTimezone for %q, got %v", invalid, err) }
+	}
+	if updates != 1 { t.Fatalf("expected only the valid update to be saved, got %d", updates) }
+}
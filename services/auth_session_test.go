@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+var testJWTConfig = config.JWTConfig{AccessTokenMinutes: 15, RefreshTokenDays: 30}
+
+// fakeSessionRepo keeps sessions and refresh tokens in memory
+type fakeSessionRepo struct {
+	sessions map[uint]*models.Session
+	tokens   map[string]*models.RefreshToken
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{sessions: map[uint]*models.Session{}, tokens: map[string]*models.RefreshToken{}}
+}
+
+func (f *fakeSessionRepo) Create(session *models.Session, token *models.RefreshToken) error {
+	session.ID = uint(len(f.sessions) + 1)
+	f.sessions[session.ID] = session
+	token.SessionID = session.ID
+	f.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (f *fakeSessionRepo) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	token, ok := f.tokens[tokenHash]
+	if !ok { return nil, gorm.ErrRecordNotFound }
+	found := *token
+	found.Session = *f.sessions[token.SessionID]
+	return &found, nil
+}
+
+func (f *fakeSessionRepo) Rotate(used *models.RefreshToken, next *models.RefreshToken, now time.Time) (bool, error) {
+	stored := f.tokens[used.TokenHash]
+	if stored.UsedAt != nil { return false, nil }
+	stored.UsedAt = &now
+	next.SessionID = used.SessionID
+	f.tokens[next.TokenHash] = next
+	f.sessions[used.SessionID].ExpiresAt = next.ExpiresAt
+	return true, nil
+}
+
+func (f *fakeSessionRepo) Revoke(sessionID uint, reason string, now time.Time) error {
+	if session := f.sessions[sessionID]; session.RevokedAt == nil { session.RevokedAt, session.RevokedReason = &now, reason }
+	return nil
+}
+
+func (f *fakeSessionRepo) RevokeAll(userID uint, reason string, now time.Time) error {
+	for id, session := range f.sessions {
+		if session.UserID == userID { f.Revoke(id, reason, now) }
+	}
+	return nil
+}
+
+func (f *fakeSessionRepo) IsActive(sessionID uint, userID uint, now time.Time) (bool, error) {
+	session, ok := f.sessions[sessionID]
+	return ok && session.UserID == userID && session.RevokedAt == nil && now.Before(session.ExpiresAt), nil
+}
+
+var _ repository.SessionRepository = (*fakeSessionRepo)(nil)
+
+func loginFixture(t *testing.T) (AuthService, *fakeSessionRepo, *models.AuthTokens) {
+	t.Helper()
+	hashed, _ := utils.HashPassword("Pass1234")
+	user := &models.User{ID: 3, Email: "jane@example.com", Password: hashed}
+	users := &mockUserRepo{
+		GetByEmailFn: func(email string) (*models.User, error) { return user, nil },
+		GetByIDFn: func(id uint) (*models.User, error) { return user, nil },
+	}
+	sessions := newFakeSessionRepo()
+	svc := NewAuthService(users, sessions, &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), &stubThrottle{}, testJWTConfig)
+	result, err := svc.Login(&models.UserLoginRequest{Email: user.Email, Password: "Pass1234"}, "10.0.0.1")
+	if err != nil { t.Fatalf("login: %v", err) }
+	return svc, sessions, result.Tokens
+}
+
+func TestAuthService_Login_StartsSession(t *testing.T) {
+	_, sessions, tokens := loginFixture(t)
+	claims, err := utils.ValidateToken(tokens.Token)
+	if err != nil { t.Fatalf("validate: %v", err) }
+	if claims.SessionID != 1 || claims.UserID != 3 { t.Fatalf("unexpected claims %+v", claims) }
+	if _, ok := sessions.tokens[tokens.RefreshToken]; ok { t.Fatalf("expected the refresh token to be stored hashed") }
+	if _, ok := sessions.tokens[utils.HashToken(tokens.RefreshToken)]; !ok { t.Fatalf("expected the refresh token hash to be stored") }
+}
+
+func TestAuthService_Refresh_RotatesAndDetectsReuse(t *testing.T) {
+	svc, sessions, first := loginFixture(t)
+
+	second, err := svc.Refresh(first.RefreshToken)
+	if err != nil { t.Fatalf("refresh: %v", err) }
+	if second.RefreshToken == first.RefreshToken || second.Token == "" { t.Fatalf("expected a new token pair, got %+v", second) }
+	third, err := svc.Refresh(second.RefreshToken)
+	if err != nil { t.Fatalf("second refresh: %v", err) }
+
+	// replaying a spent token revokes the session, including the token that replaced it
+	if _, err := svc.Refresh(first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) { t.Fatalf("expected ErrRefreshTokenReused, got %v", err) }
+	if sessions.sessions[1].RevokedReason != models.SessionReuse { t.Fatalf("expected the session to be revoked for reuse, got %+v", sessions.sessions[1]) }
+	if _, err := svc.Refresh(third.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) { t.Fatalf("expected ErrInvalidRefreshToken after revocation, got %v", err) }
+
+	if _, err := svc.Refresh("not-a-token"); !errors.Is(err, ErrInvalidRefreshToken) { t.Fatalf("expected ErrInvalidRefreshToken, got %v", err) }
+}
+
+func TestAuthService_Refresh_Expired(t *testing.T) {
+	svc, sessions, tokens := loginFixture(t)
+	sessions.tokens[utils.HashToken(tokens.RefreshToken)].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := svc.Refresh(tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) { t.Fatalf("expected ErrInvalidRefreshToken, got %v", err) }
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	svc, sessions, first := loginFixture(t)
+	result, err := svc.Login(&models.UserLoginRequest{Email: "jane@example.com", Password: "Pass1234"}, "10.0.0.1")
+	if err != nil { t.Fatalf("second login: %v", err) }
+	second := result.Tokens
+
+	if err := svc.Logout(&models.LogoutRequest{RefreshToken: first.RefreshToken}); err != nil { t.Fatalf("logout: %v", err) }
+	if active, _ := sessions.IsActive(1, 3, time.Now()); active { t.Fatalf("expected the first session to be revoked") }
+	if active, _ := sessions.IsActive(2, 3, time.Now()); !active { t.Fatalf("expected the other session to stay active") }
+
+	if err := svc.Logout(&models.LogoutRequest{RefreshToken: second.RefreshToken, All: true}); err != nil { t.Fatalf("logout all: %v", err) }
+	if active, _ := sessions.IsActive(2, 3, time.Now()); active { t.Fatalf("expected every session to be revoked") }
+	if err := svc.Logout(&models.LogoutRequest{RefreshToken: "unknown"}); !errors.Is(err, ErrInvalidRefreshToken) { t.Fatalf("expected ErrInvalidRefreshToken, got %v", err) }
+}
+
+func TestAuthService_Logout_RefusesStaleTokens(t *testing.T) {
+	svc, sessions, first := loginFixture(t)
+	result, err := svc.Login(&models.UserLoginRequest{Email: "jane@example.com", Password: "Pass1234"}, "10.0.0.1")
+	if err != nil { t.Fatalf("second login: %v", err) }
+	other := result.Tokens
+	if _, err := svc.Refresh(first.RefreshToken); err != nil { t.Fatalf("refresh: %v", err) }
+
+	// a rotated token can't log the user out everywhere; it only burns its own session as reuse
+	if err := svc.Logout(&models.LogoutRequest{RefreshToken: first.RefreshToken, All: true}); !errors.Is(err, ErrRefreshTokenReused) { t.Fatalf("expected ErrRefreshTokenReused, got %v", err) }
+	if sessions.sessions[1].RevokedReason != models.SessionReuse { t.Fatalf("expected the first session to be revoked for reuse, got %+v", sessions.sessions[1]) }
+	if active, _ := sessions.IsActive(2, 3, time.Now()); !active { t.Fatalf("expected the other session to stay active") }
+	if err := svc.Logout(&models.LogoutRequest{RefreshToken: first.RefreshToken, All: true}); !errors.Is(err, ErrInvalidRefreshToken) { t.Fatalf("expected a revoked session's token to be refused, got %v", err) }
+
+	sessions.tokens[utils.HashToken(other.RefreshToken)].ExpiresAt = time.Now().Add(-time.Second)
+	if err := svc.Logout(&models.LogoutRequest{RefreshToken: other.RefreshToken, All: true}); !errors.Is(err, ErrInvalidRefreshToken) { t.Fatalf("expected an expired token to be refused, got %v", err) }
+	if active, _ := sessions.IsActive(2, 3, time.Now()); !active { t.Fatalf("expected an expired token to revoke nothing") }
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+var ErrInvalidBudget = errors.New("invalid budget")
+
+type BudgetService interface {
+	CreateBudget(userID uint, req *models.CreateBudgetRequest) (*models.Budget, error)
+	GetBudgets(userID uint) ([]models.Budget, error)
+	GetBudgetByID(id uint, userID uint) (*models.Budget, error)
+	UpdateBudget(id uint, userID uint, req *models.UpdateBudgetRequest) (*models.Budget, error)
+	DeleteBudget(id uint, userID uint) error
+}
+
+type budgetService struct {
+	budgetRepo   repository.BudgetRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewBudgetService(budgetRepo repository.BudgetRepository, categoryRepo repository.CategoryRepository) BudgetService {
+	return &budgetService{
+		budgetRepo:   budgetRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *budgetService) CreateBudget(userID uint, req *models.CreateBudgetRequest) (*models.Budget, error) {
+	budget := &models.Budget{
+		UserID:     userID,
+		CategoryID: req.CategoryID,
+		Type:       req.Type,
+		Amount:     req.Amount,
+		StartMonth: req.StartMonth,
+		EndMonth:   req.EndMonth,
+	}
+	if budget.Type == "" {
+		budget.Type = models.Expense
+	}
+
+	if err := s.validate(budget); err != nil {
+		return nil, err
+	}
+	if err := s.budgetRepo.Create(budget); err != nil {
+		return nil, err
+	}
+	return s.budgetRepo.GetByID(budget.ID, userID)
+}
+
+func (s *budgetService) GetBudgets(userID uint) ([]models.Budget, error) {
+	return s.budgetRepo.GetByUserID(userID)
+}
+
+func (s *budgetService) GetBudgetByID(id uint, userID uint) (*models.Budget, error) {
+	return s.budgetRepo.GetByID(id, userID)
+}
+
+func (s *budgetService) UpdateBudget(id uint, userID uint, req *models.UpdateBudgetRequest) (*models.Budget, error) {
+	budget, err := s.budgetRepo.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != nil {
+		budget.CategoryID = *req.CategoryID
+	}
+	if req.Type != nil {
+		budget.Type = *req.Type
+	}
+	if req.Amount != nil {
+		budget.Amount = *req.Amount
+	}
+	if req.StartMonth != nil {
+		budget.StartMonth = *req.StartMonth
+	}
+	if req.EndMonth != nil {
+		budget.EndMonth = *req.EndMonth
+	}
+
+	if err := s.validate(budget); err != nil {
+		return nil, err
+	}
+	if err := s.budgetRepo.Update(budget); err != nil {
+		return nil, err
+	}
+	return s.budgetRepo.GetByID(id, userID)
+}
+
+func (s *budgetService) DeleteBudget(id uint, userID uint) error {
+	return s.budgetRepo.Delete(id, userID)
+}
+
+func (s *budgetService) validate(budget *models.Budget) error {
+	if _, err := time.Parse("2006-01", budget.StartMonth); err != nil {
+		return fmt.Errorf("%w: start_month must be YYYY-MM", ErrInvalidBudget)
+	}
+	if budget.EndMonth != "" {
+		if _, err := time.Parse("2006-01", budget.EndMonth); err != nil {
+			return fmt.Errorf("%w: end_month must be YYYY-MM", ErrInvalidBudget)
+		}
+		if budget.EndMonth < budget.StartMonth {
+			return fmt.Errorf("%w: end_month is before start_month", ErrInvalidBudget)
+		}
+	}
+	if _, err := s.categoryRepo.GetByID(budget.CategoryID, budget.UserID); err != nil {
+		return fmt.Errorf("%w: category not found or does not belong to user", ErrInvalidBudget)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+type mockBudgetRepo struct {
+	budgets []models.Budget
+	created *models.Budget
+}
+
+func (m *mockBudgetRepo) Create(budget *models.Budget) error { budget.ID = 1; m.created = budget; return nil }
+func (m *mockBudgetRepo) GetByUserID(userID uint) ([]models.Budget, error) { return m.budgets, nil }
+func (m *mockBudgetRepo) GetByID(id uint, userID uint) (*models.Budget, error) {
+	if m.created != nil && m.created.ID == id { return m.created, nil }
+	for i := range m.budgets {
+		if m.budgets[i].ID == id { return &m.budgets[i], nil }
+	}
+	return nil, errors.New("not found")
+}
+func (m *mockBudgetRepo) Update(budget *models.Budget) error { return nil }
+func (m *mockBudgetRepo) Delete(id uint, userID uint) error { return nil }
+
+var _ repository.BudgetRepository = (*mockBudgetRepo)(nil)
+
+func TestBudgetService_Create(t *testing.T) {
+	mCat := &mockCatRepo{GetByIDFn: func(id uint, userID uint) (*models.Category, error) {
+		if id != 2 { return nil, errors.New("not found") }
+		return &models.Category{ID: id, UserID: userID}, nil
+	}}
+	mBudget := &mockBudgetRepo{}
+	svc := NewBudgetService(mBudget, mCat)
+
+	budget, err := svc.CreateBudget(5, &models.CreateBudgetRequest{CategoryID: 2, Amount: 400, StartMonth: "2025-01"})
+	if err != nil { t.Fatalf("create: %v", err) }
+	if budget.Type != models.Expense || budget.UserID != 5 { t.Fatalf("expected expense budget for user, got %+v", budget) }
+
+	for _, req := range []models.CreateBudgetRequest{
+		{CategoryID: 2, Amount: 1, StartMonth: "2025-1-01"},
+		{CategoryID: 2, Amount: 1, StartMonth: "2025-06", EndMonth: "2025-05"},
+		{CategoryID: 3, Amount: 1, StartMonth: "2025-06"},
+	} {
+		req := req
+		if _, err := svc.CreateBudget(5, &req); !errors.Is(err, ErrInvalidBudget) { t.Fatalf("expected ErrInvalidBudget for %+v, got %v", req, err) }
+	}
+}
+
+func TestBudgetService_Update_ValidatesResult(t *testing.T) {
+	mCat := &mockCatRepo{GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return &models.Category{ID: id}, nil }}
+	mBudget := &mockBudgetRepo{budgets: []models.Budget{{ID: 4, UserID: 5, CategoryID: 2, Type: models.Expense, Amount: 100, StartMonth: "2025-03"}}}
+	svc := NewBudgetService(mBudget, mCat)
+
+	amount := 150.0
+	budget, err := svc.UpdateBudget(4, 5, &models.UpdateBudgetRequest{Amount: &amount})
+	if err != nil || budget.Amount != 150 { t.Fatalf("update: %v %+v", err, budget) }
+	end := "2025-02"
+	if _, err := svc.UpdateBudget(4, 5, &models.UpdateBudgetRequest{EndMonth: &end}); !errors.Is(err, ErrInvalidBudget) { t.Fatalf("expected end before start to be rejected, got %v", err) }
+}
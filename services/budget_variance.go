@@ -0,0 +1,204 @@
+package services
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type varianceKey struct {
+	categoryID      uint
+	transactionType models.TransactionType
+}
+
+type varianceAccumulator struct {
+	row                      models.VarianceRow
+	monthPlanned, ytdPlanned float64
+	monthActual, ytdActual   float64
+}
+
+// GetBudgetVariance compares budgets with actuals for month (YYYY-MM, default current month) and for the
+// year to date. Months are taken in the user's time zone and actuals are read through the same aggregation
+// as GetSummary, so the totals of both agree.
+func (s *reportService) GetBudgetVariance(userID uint, month string) (*models.VarianceReport, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, err := resolveReportMonth(month, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+	end := start.AddDate(0, 1, 0)
+	yearStart := time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, loc)
+
+	budgets, err := s.budgetRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	monthActuals, err := s.transactionRepo.GetSummaryByCategory(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	ytdActuals, err := s.transactionRepo.GetSummaryByCategory(userID, yearStart, end)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[varianceKey]*varianceAccumulator)
+	row := func(categoryID uint, transactionType models.TransactionType) *varianceAccumulator {
+		key := varianceKey{categoryID, transactionType}
+		if rows[key] == nil {
+			rows[key] = &varianceAccumulator{row: models.VarianceRow{CategoryID: categoryID, Type: transactionType}}
+		}
+		return rows[key]
+	}
+
+	// Budgets are ordered by start month, so a later budget for the same category replaces an earlier one
+	for cursor := yearStart; cursor.Before(end); cursor = cursor.AddDate(0, 1, 0) {
+		key := cursor.Format("2006-01")
+		planned := make(map[varianceKey]models.Budget)
+		for _, budget := range budgets {
+			if budget.Covers(key) {
+				planned[varianceKey{budget.CategoryID, budget.Type}] = budget
+			}
+		}
+		for _, budget := range planned {
+			acc := row(budget.CategoryID, budget.Type)
+			acc.row.Budgeted = true
+			if acc.row.CategoryName == "" {
+				acc.row.CategoryName = budget.Category.Name
+			}
+			acc.ytdPlanned += budget.Amount
+			if cursor.Equal(start) {
+				acc.monthPlanned += budget.Amount
+			}
+		}
+	}
+	for _, total := range ytdActuals {
+		acc := row(total.CategoryID, total.Type)
+		acc.ytdActual += total.Total
+		if total.CategoryName != "" {
+			acc.row.CategoryName = total.CategoryName
+		}
+	}
+	for _, total := range monthActuals {
+		row(total.CategoryID, total.Type).monthActual += total.Total
+	}
+
+	report := &models.VarianceReport{
+		Month:      start.Format("2006-01"),
+		StartDate:  start,
+		EndDate:    end,
+		YearStart:  yearStart,
+		Categories: make([]models.VarianceRow, 0, len(rows)),
+	}
+	totals := map[models.TransactionType]*varianceAccumulator{models.Income: {}, models.Expense: {}}
+	for _, acc := range rows {
+		acc.row.Month = varianceValues(acc.monthPlanned, acc.monthActual)
+		acc.row.YearToDate = varianceValues(acc.ytdPlanned, acc.ytdActual)
+		report.Categories = append(report.Categories, acc.row)
+
+		if total, ok := totals[acc.row.Type]; ok {
+			total.monthPlanned += acc.monthPlanned
+			total.monthActual += acc.monthActual
+			total.ytdPlanned += acc.ytdPlanned
+			total.ytdActual += acc.ytdActual
+		}
+	}
+	for transactionType, acc := range totals {
+		total := models.VarianceTotal{Month: varianceValues(acc.monthPlanned, acc.monthActual), YearToDate: varianceValues(acc.ytdPlanned, acc.ytdActual)}
+		if transactionType == models.Income {
+			report.Income = total
+		} else {
+			report.Expense = total
+		}
+	}
+
+	// Expenses first, then income; alphabetical within each
+	sort.Slice(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i], report.Categories[j]
+		if a.Type != b.Type {
+			return a.Type == models.Expense
+		}
+		if a.CategoryName != b.CategoryName {
+			return a.CategoryName < b.CategoryName
+		}
+		return a.CategoryID < b.CategoryID
+	})
+	return report, nil
+}
+
+func varianceValues(planned, actual float64) models.VarianceValues {
+	values := models.VarianceValues{
+		Planned:  roundCents(planned),
+		Actual:   roundCents(actual),
+		Variance: roundCents(actual - planned),
+	}
+	values.Over = values.Actual > values.Planned
+	values.Under = values.Actual < values.Planned
+	if values.Planned != 0 {
+		percent := roundCents(values.Variance / values.Planned * 100)
+		values.VariancePercent = &percent
+	}
+	return values
+}
+
+var varianceCSVHeader = []string{
+	"category_id", "category", "type", "budgeted",
+	"month_planned", "month_actual", "month_variance", "month_variance_percent", "month_status",
+	"ytd_planned", "ytd_actual", "ytd_variance", "ytd_variance_percent", "ytd_status",
+}
+
+// WriteVarianceCSV writes one line per category followed by the income and expense totals
+func WriteVarianceCSV(w io.Writer, report *models.VarianceReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(varianceCSVHeader); err != nil {
+		return err
+	}
+
+	record := func(categoryID, name string, transactionType models.TransactionType, budgeted string, month, ytd models.VarianceValues) []string {
+		line := []string{categoryID, name, string(transactionType), budgeted}
+		line = append(line, varianceCSVValues(month)...)
+		return append(line, varianceCSVValues(ytd)...)
+	}
+	for _, row := range report.Categories {
+		line := record(strconv.FormatUint(uint64(row.CategoryID), 10), row.CategoryName, row.Type, strconv.FormatBool(row.Budgeted), row.Month, row.YearToDate)
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+	if err := cw.Write(record("", "Total expense", models.Expense, "", report.Expense.Month, report.Expense.YearToDate)); err != nil {
+		return err
+	}
+	if err := cw.Write(record("", "Total income", models.Income, "", report.Income.Month, report.Income.YearToDate)); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func varianceCSVValues(values models.VarianceValues) []string {
+	percent := ""
+	if values.VariancePercent != nil {
+		percent = strconv.FormatFloat(*values.VariancePercent, 'f', 2, 64)
+	}
+	status := "on_budget"
+	if values.Over {
+		status = "over"
+	} else if values.Under {
+		status = "under"
+	}
+	return []string{
+		strconv.FormatFloat(values.Planned, 'f', 2, 64),
+		strconv.FormatFloat(values.Actual, 'f', 2, 64),
+		strconv.FormatFloat(values.Variance, 'f', 2, 64),
+		percent,
+		status,
+	}
+}
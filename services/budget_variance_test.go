@@ -0,0 +1,71 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestReportService_GetBudgetVariance(t *testing.T) {
+	mBudget := &mockBudgetRepo{budgets: []models.Budget{
+		{CategoryID: 1, Type: models.Expense, Amount: 300, StartMonth: "2024-06", Category: models.Category{Name: "Groceries"}},
+		// raised from August; replaces the earlier budget from then on
+		{CategoryID: 1, Type: models.Expense, Amount: 400, StartMonth: "2025-08", Category: models.Category{Name: "Groceries"}},
+		{CategoryID: 2, Type: models.Expense, Amount: 100, StartMonth: "2025-01", EndMonth: "2025-06", Category: models.Category{Name: "Gym"}},
+		{CategoryID: 3, Type: models.Income, Amount: 3000, StartMonth: "2025-01", Category: models.Category{Name: "Salary"}},
+	}}
+	mTxn := &mockTxnRepo{SummaryByCategoryFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+		if !end.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected end bound %v", end) }
+		switch start.Format("2006-01-02") {
+		case "2025-09-01":
+			return []models.CategoryTotal{
+				{CategoryID: 1, CategoryName: "Groceries", Type: models.Expense, Total: 450},
+				{CategoryID: 3, CategoryName: "Salary", Type: models.Income, Total: 3000},
+				{CategoryID: 4, CategoryName: "Travel", Type: models.Expense, Total: 80},
+			}, nil
+		case "2025-01-01":
+			return []models.CategoryTotal{
+				{CategoryID: 1, CategoryName: "Groceries", Type: models.Expense, Total: 2900},
+				{CategoryID: 2, CategoryName: "Gym", Type: models.Expense, Total: 600},
+				{CategoryID: 3, CategoryName: "Salary", Type: models.Income, Total: 26000},
+				{CategoryID: 4, CategoryName: "Travel", Type: models.Expense, Total: 80},
+			}, nil
+		}
+		t.Fatalf("unexpected start bound %v", start)
+		return nil, nil
+	}}
+	svc := NewReportService(mTxn, mBudget, userRepoIn("UTC"))
+
+	report, err := svc.GetBudgetVariance(1, "2025-09")
+	if err != nil { t.Fatalf("variance: %v", err) }
+	if len(report.Categories) != 4 { t.Fatalf("expected 4 rows, got %+v", report.Categories) }
+
+	groceries, gym, travel, salary := report.Categories[0], report.Categories[1], report.Categories[2], report.Categories[3]
+	if groceries.CategoryName != "Groceries" || gym.CategoryName != "Gym" || travel.CategoryName != "Travel" || salary.Type != models.Income { t.Fatalf("unexpected row order %+v", report.Categories) }
+
+	if groceries.Month.Planned != 400 || groceries.Month.Variance != 50 || *groceries.Month.VariancePercent != 12.5 || !groceries.Month.Over { t.Fatalf("unexpected groceries month %+v", groceries.Month) }
+	// 7 months at 300 and 2 at 400
+	if groceries.YearToDate.Planned != 2900 || groceries.YearToDate.Variance != 0 || groceries.YearToDate.Over || groceries.YearToDate.Under { t.Fatalf("unexpected groceries ytd %+v", groceries.YearToDate) }
+	if gym.Month.Planned != 0 || gym.Month.VariancePercent != nil || gym.YearToDate.Planned != 600 || !gym.Budgeted { t.Fatalf("expected gym budget to have ended in June, got %+v", gym) }
+	if travel.Budgeted || !travel.Month.Over || travel.Month.VariancePercent != nil { t.Fatalf("expected unbudgeted travel to be flagged over, got %+v", travel) }
+	if !salary.YearToDate.Under || salary.YearToDate.Variance != -1000 { t.Fatalf("unexpected salary ytd %+v", salary.YearToDate) }
+
+	if report.Expense.Month.Planned != 400 || report.Expense.Month.Actual != 530 || report.Income.YearToDate.Planned != 27000 { t.Fatalf("unexpected totals %+v / %+v", report.Expense, report.Income) }
+
+	var buf bytes.Buffer
+	if err := WriteVarianceCSV(&buf, report); err != nil { t.Fatalf("csv: %v", err) }
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil { t.Fatalf("read csv: %v", err) }
+	if len(records) != 7 || records[0][0] != "category_id" { t.Fatalf("expected header, 4 rows and 2 totals, got %v", records) }
+	if got := records[1]; got[1] != "Groceries" || got[4] != "400.00" || got[7] != "12.50" || got[8] != "over" || got[13] != "on_budget" { t.Fatalf("unexpected groceries line %v", got) }
+	if got := records[5]; got[1] != "Total expense" || got[5] != "530.00" { t.Fatalf("unexpected total line %v", got) }
+}
+
+func TestReportService_GetBudgetVariance_InvalidMonth(t *testing.T) {
+	svc := NewReportService(&mockTxnRepo{}, &mockBudgetRepo{}, userRepoIn("UTC"))
+	if _, err := svc.GetBudgetVariance(1, "September"); !errors.Is(err, ErrInvalidSummaryRange) { t.Fatalf("expected ErrInvalidSummaryRange, got %v", err) }
+}
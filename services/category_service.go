@@ -1,6 +1,8 @@
 package services
 
 import (
+	"strings"
+
 	"github.com/aditherevenger/Budget-Tracker-API/models"
 	"github.com/aditherevenger/Budget-Tracker-API/repository"
 )
@@ -29,6 +31,8 @@ func (s *categoryService) CreateCategory(userID uint, req *models.CreateCategory
 		Name:        req.Name,
 		Description: req.Description,
 		Color:       req.Color,
+		TaxClass:    strings.TrimSpace(req.TaxClass),
+		Fixed:       req.Fixed,
 	}
 
 	if category.Color == "" {
@@ -69,6 +73,14 @@ func (s *categoryService) UpdateCategory(id uint, userID uint, req *models.Updat
 		category.Color = *req.Color
 	}
 
+	if req.TaxClass != nil {
+		category.TaxClass = strings.TrimSpace(*req.TaxClass)
+	}
+
+	if req.Fixed != nil {
+		category.Fixed = *req.Fixed
+	}
+
 	err = s.categoryRepo.Update(category)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+const defaultImportDateFormat = "2006-01-02"
+
+type csvColumns struct {
+	date        int
+	amount      int
+	debit       int
+	credit      int
+	description int
+	category    int
+}
+
+// ParseCSV reads a bank export using the given column mapping. Problems with individual
+// lines are reported on the returned rows; an error is only returned when the file or
+// the mapping itself cannot be used.
+func ParseCSV(data io.Reader, mapping *models.ImportMapping) ([]models.ImportRow, error) {
+	if err := validateMapping(mapping); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		reader.Comma = []rune(mapping.Delimiter)[0]
+	}
+
+	var header []string
+	var cols csvColumns
+	var rows []models.ImportRow
+	layout := dateLayout(mapping.DateFormat)
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		// Line numbers refer to the file as opened in a spreadsheet, header included
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			first = false
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if mapping.HasHeader {
+				header = record
+			}
+			if cols, err = resolveColumns(mapping, header); err != nil {
+				return nil, err
+			}
+			if mapping.HasHeader {
+				continue
+			}
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+		rows = append(rows, parseCSVRecord(line, record, cols, layout, mapping))
+	}
+
+	if first {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	return rows, nil
+}
+
+func validateMapping(mapping *models.ImportMapping) error {
+	if mapping == nil {
+		return errors.New("import mapping is required")
+	}
+	if strings.TrimSpace(mapping.DateColumn) == "" {
+		return errors.New("mapping must define a date column")
+	}
+	if mapping.AmountColumn == "" && mapping.DebitColumn == "" && mapping.CreditColumn == "" {
+		return errors.New("mapping must define an amount column or debit/credit columns")
+	}
+	if mapping.AmountSign != "" && mapping.AmountSign != models.AmountSignNegativeExpense && mapping.AmountSign != models.AmountSignPositiveExpense {
+		return fmt.Errorf("unknown amount sign convention %q", mapping.AmountSign)
+	}
+	if len([]rune(mapping.Delimiter)) > 1 {
+		return errors.New("delimiter must be a single character")
+	}
+	return nil
+}
+
+func resolveColumns(mapping *models.ImportMapping, header []string) (csvColumns, error) {
+	var cols csvColumns
+	var err error
+	if cols.date, err = resolveColumn(mapping.DateColumn, header); err != nil {
+		return cols, err
+	}
+	if cols.amount, err = resolveColumn(mapping.AmountColumn, header); err != nil {
+		return cols, err
+	}
+	if cols.debit, err = resolveColumn(mapping.DebitColumn, header); err != nil {
+		return cols, err
+	}
+	if cols.credit, err = resolveColumn(mapping.CreditColumn, header); err != nil {
+		return cols, err
+	}
+	if cols.description, err = resolveColumn(mapping.DescriptionColumn, header); err != nil {
+		return cols, err
+	}
+	if cols.category, err = resolveColumn(mapping.CategoryColumn, header); err != nil {
+		return cols, err
+	}
+	return cols, nil
+}
+
+// resolveColumn accepts either a 1-based column number or a header name (case-insensitive)
+func resolveColumn(name string, header []string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return -1, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		if n < 1 {
+			return -1, fmt.Errorf("invalid column number %d", n)
+		}
+		return n - 1, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i, nil
+		}
+	}
+	if header == nil {
+		return -1, fmt.Errorf("column %q can only be referenced by number when the file has no header", name)
+	}
+	return -1, fmt.Errorf("column %q not found in CSV header", name)
+}
+
+// dateLayout converts formats such as DD/MM/YYYY into Go layouts; Go layouts pass through unchanged
+func dateLayout(format string) string {
+	if strings.TrimSpace(format) == "" {
+		return defaultImportDateFormat
+	}
+	return strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(format)
+}
+
+func parseCSVRecord(line int, record []string, cols csvColumns, layout string, mapping *models.ImportMapping) models.ImportRow {
+	row := models.ImportRow{Row: line}
+
+	rawDate := field(record, cols.date)
+	date, err := time.Parse(layout, rawDate)
+	if err != nil {
+		row.Errors = append(row.Errors, fmt.Sprintf("invalid date %q", rawDate))
+	} else {
+		row.Date = date.UTC()
+	}
+
+	if cols.amount >= 0 {
+		amount, err := parseAmount(field(record, cols.amount), mapping.DecimalComma)
+		switch {
+		case err != nil:
+			row.Errors = append(row.Errors, err.Error())
+		case amount == 0:
+			row.Errors = append(row.Errors, "amount must not be zero")
+		default:
+			expense := amount < 0
+			if mapping.AmountSign == models.AmountSignPositiveExpense {
+				expense = amount > 0
+			}
+			row.Amount = abs(amount)
+			row.Type = models.Income
+			if expense {
+				row.Type = models.Expense
+			}
+		}
+	} else {
+		// Some banks fill the unused side with 0.00, so whichever side is non-zero wins
+		for _, side := range []struct {
+			raw     string
+			txnType models.TransactionType
+		}{
+			{field(record, cols.debit), models.Expense},
+			{field(record, cols.credit), models.Income},
+		} {
+			if side.raw == "" {
+				continue
+			}
+			amount, err := parseAmount(side.raw, mapping.DecimalComma)
+			if err != nil {
+				row.Errors = append(row.Errors, err.Error())
+				break
+			}
+			if amount != 0 {
+				row.Amount, row.Type = abs(amount), side.txnType
+				break
+			}
+		}
+		if len(row.Errors) == 0 && row.Amount == 0 {
+			row.Errors = append(row.Errors, "missing debit or credit amount")
+		}
+	}
+
+	row.Description = field(record, cols.description)
+	row.CategoryName = field(record, cols.category)
+	return row
+}
+
+// parseAmount tolerates currency symbols, thousands separators and accounting-style parentheses
+func parseAmount(raw string, decimalComma bool) (float64, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return 0, errors.New("missing amount")
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == '-' || r == '+' || r == '.' || r == ',' {
+			return r
+		}
+		return -1
+	}, s)
+	if decimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func field(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestParseCSV_SignedAmountWithHeader(t *testing.T) {
+	data := "Date;Description;Amount;Category\n" +
+		"18/09/2025;Coffee;-3,50;Food\n" +
+		"\n" +
+		"19/09/2025;Salary;2.500,00;Salary\n"
+	mapping := &models.ImportMapping{
+		Delimiter: ";", HasHeader: true, DateColumn: "date", DateFormat: "DD/MM/YYYY",
+		AmountColumn: "Amount", DecimalComma: true, DescriptionColumn: "Description", CategoryColumn: "Category",
+	}
+	rows, err := ParseCSV(strings.NewReader(data), mapping)
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if len(rows) != 2 { t.Fatalf("expected 2 rows (blank line skipped), got %d", len(rows)) }
+
+	if rows[0].Type != models.Expense || rows[0].Amount != 3.5 || rows[0].CategoryName != "Food" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if !rows[0].Date.Equal(time.Date(2025, 9, 18, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected date: %v", rows[0].Date) }
+	if rows[1].Type != models.Income || rows[1].Amount != 2500 || rows[1].Row != 4 {
+		t.Fatalf("unexpected second row: %+v", rows[1])
+	}
+}
+
+func TestParseCSV_PositiveExpenseConvention(t *testing.T) {
+	data := "2025-09-01,Card payment,(100.00)\n2025-09-02,Groceries,$42.10\n"
+	mapping := &models.ImportMapping{DateColumn: "1", DescriptionColumn: "2", AmountColumn: "3", AmountSign: models.AmountSignPositiveExpense}
+	rows, err := ParseCSV(strings.NewReader(data), mapping)
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if rows[0].Type != models.Income || rows[0].Amount != 100 { t.Fatalf("expected refund as income, got %+v", rows[0]) }
+	if rows[1].Type != models.Expense || rows[1].Amount != 42.10 { t.Fatalf("expected purchase as expense, got %+v", rows[1]) }
+}
+
+func TestParseCSV_DebitCreditColumnsAndRowErrors(t *testing.T) {
+	data := "date,memo,debit,credit\n" +
+		"2025-09-01,Rent,1200.00,0.00\n" +
+		"2025-09-02,Refund,,15.00\n" +
+		"not-a-date,Broken,abc,\n"
+	mapping := &models.ImportMapping{HasHeader: true, DateColumn: "date", DescriptionColumn: "memo", DebitColumn: "debit", CreditColumn: "credit"}
+	rows, err := ParseCSV(strings.NewReader(data), mapping)
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if len(rows) != 3 { t.Fatalf("expected 3 rows, got %d", len(rows)) }
+	if rows[0].Type != models.Expense || rows[0].Amount != 1200 { t.Fatalf("unexpected debit row: %+v", rows[0]) }
+	if rows[1].Type != models.Income || rows[1].Amount != 15 { t.Fatalf("unexpected credit row: %+v", rows[1]) }
+	if len(rows[2].Errors) != 2 { t.Fatalf("expected date and amount errors, got %v", rows[2].Errors) }
+}
+
+func TestParseCSV_InvalidMapping(t *testing.T) {
+	if _, err := ParseCSV(strings.NewReader("a,b\n"), &models.ImportMapping{DateColumn: "a"}); err == nil {
+		t.Fatalf("expected error when no amount column is mapped")
+	}
+	if _, err := ParseCSV(strings.NewReader("a,b\n1,2\n"), &models.ImportMapping{HasHeader: true, DateColumn: "a", AmountColumn: "missing"}); err == nil {
+		t.Fatalf("expected error for unknown header")
+	}
+	if _, err := ParseCSV(strings.NewReader("1,2\n"), &models.ImportMapping{DateColumn: "date", AmountColumn: "2"}); err == nil {
+		t.Fatalf("expected error for named column without header")
+	}
+}
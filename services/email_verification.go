@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+const (
+	verificationTTL = 48 * time.Hour
+	// a user gets at most one verification email a minute and five a day
+	verificationInterval   = time.Minute
+	verificationDailyLimit = 5
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+	ErrVerificationRateLimited  = errors.New("too many verification emails, please try again later")
+)
+
+//go:embed templates/verify_email.txt templates/verify_email.html
+var verifyEmailTemplates embed.FS
+
+var (
+	verifyEmailTextTemplate = template.Must(template.ParseFS(verifyEmailTemplates, "templates/verify_email.txt"))
+	verifyEmailHTMLTemplate = htmltemplate.Must(htmltemplate.ParseFS(verifyEmailTemplates, "templates/verify_email.html"))
+)
+
+type EmailVerificationService interface {
+	SendVerification(user *models.User) error
+	ResendVerification(userID uint) error
+	VerifyEmail(token string) error
+}
+
+type emailVerificationService struct {
+	userRepo  repository.UserRepository
+	tokenRepo repository.OneTimeTokenRepository
+	mailer    Mailer
+	publicURL string
+}
+
+// NewEmailVerificationService wires the address verification. mailer may be nil when mail is not
+// configured, in which case sending fails with ErrMailerNotConfigured.
+func NewEmailVerificationService(userRepo repository.UserRepository, tokenRepo repository.OneTimeTokenRepository, mailer Mailer, publicURL string) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// SendVerification mails a verification link, retiring any earlier link
+func (s *emailVerificationService) SendVerification(user *models.User) error {
+	if s.mailer == nil {
+		return ErrMailerNotConfigured
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	recent, err := s.tokenRepo.CountSince(user.ID, models.TokenEmailVerification, now.Add(-verificationInterval))
+	if err != nil {
+		return err
+	}
+	today, err := s.tokenRepo.CountSince(user.ID, models.TokenEmailVerification, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if recent > 0 || today >= verificationDailyLimit {
+		return ErrVerificationRateLimited
+	}
+
+	token, hash, err := utils.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	err = s.tokenRepo.Create(&models.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenEmailVerification,
+		TokenHash: hash,
+		ExpiresAt: now.Add(verificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := s.verificationMessage(user, token)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(msg)
+}
+
+func (s *emailVerificationService) ResendVerification(userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	return s.SendVerification(user)
+}
+
+func (s *emailVerificationService) verificationMessage(user *models.User, token string) (*EmailMessage, error) {
+	data := struct {
+		Subject   string
+		Name      string
+		Email     string
+		VerifyURL string
+		Hours     int
+	}{
+		Subject:   "Confirm your Budget Tracker email address",
+		Name:      user.FirstName,
+		Email:     user.Email,
+		VerifyURL: s.publicURL + "/api/auth/verify-email?token=" + url.QueryEscape(token),
+		Hours:     int(verificationTTL / time.Hour),
+	}
+	var text, html bytes.Buffer
+	if err := verifyEmailTextTemplate.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := verifyEmailHTMLTemplate.Execute(&html, data); err != nil {
+		return nil, err
+	}
+	return &EmailMessage{To: user.Email, Subject: data.Subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// VerifyEmail marks the address of the token's user as verified
+func (s *emailVerificationService) VerifyEmail(token string) error {
+	now := time.Now()
+	stored, err := s.tokenRepo.Consume(utils.HashToken(token), models.TokenEmailVerification, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user)
+}
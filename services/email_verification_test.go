@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// stubVerification records the users a verification email was requested for
+type stubVerification struct{ sent []*models.User }
+
+func (s *stubVerification) SendVerification(user *models.User) error { s.sent = append(s.sent, user); return nil }
+func (s *stubVerification) ResendVerification(userID uint) error      { return nil }
+func (s *stubVerification) VerifyEmail(token string) error             { return nil }
+
+// linkToken pulls the token out of the verification link in a mail
+func linkToken(t *testing.T, msg *EmailMessage) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Text) {
+		if link, err := url.Parse(field); err == nil && link.Query().Get("token") != "" { return link.Query().Get("token") }
+	}
+	t.Fatalf("no verification link in mail:\n%s", msg.Text)
+	return ""
+}
+
+func verificationFixture() (*emailVerificationService, *models.User, *fakeTokenRepo, *recordingMailer) {
+	user := &models.User{ID: 8, Email: "sam@example.com", FirstName: "Sam"}
+	users := &mockUserRepo{
+		GetByIDFn: func(id uint) (*models.User, error) {
+			if id != user.ID { return nil, gorm.ErrRecordNotFound }
+			return user, nil
+		},
+		UpdateFn: func(updated *models.User) error { *user = *updated; return nil },
+	}
+	tokens, mailer := &fakeTokenRepo{}, &recordingMailer{}
+	svc := NewEmailVerificationService(users, tokens, mailer, "https://budget.example.com").(*emailVerificationService)
+	return svc, user, tokens, mailer
+}
+
+func TestEmailVerificationService_Verify(t *testing.T) {
+	svc, user, _, mailer := verificationFixture()
+	if err := svc.SendVerification(user); err != nil { t.Fatalf("send: %v", err) }
+	if len(mailer.sent) != 1 || !strings.Contains(mailer.sent[0].Text, "https://budget.example.com/api/auth/verify-email?token=") { t.Fatalf("expected a verification link, got %+v", mailer.sent) }
+	token := linkToken(t, mailer.sent[0])
+
+	if err := svc.VerifyEmail("wrong"); !errors.Is(err, ErrInvalidVerificationToken) { t.Fatalf("expected ErrInvalidVerificationToken, got %v", err) }
+	if err := svc.VerifyEmail(token); err != nil { t.Fatalf("verify: %v", err) }
+	if user.EmailVerifiedAt == nil { t.Fatalf("expected the address to be verified") }
+	if err := svc.VerifyEmail(token); !errors.Is(err, ErrInvalidVerificationToken) { t.Fatalf("expected the link to work once, got %v", err) }
+	if err := svc.ResendVerification(user.ID); !errors.Is(err, ErrEmailAlreadyVerified) { t.Fatalf("expected ErrEmailAlreadyVerified, got %v", err) }
+}
+
+func TestEmailVerificationService_RateLimit(t *testing.T) {
+	svc, user, tokens, mailer := verificationFixture()
+	if err := svc.SendVerification(user); err != nil { t.Fatalf("send: %v", err) }
+	if err := svc.ResendVerification(user.ID); !errors.Is(err, ErrVerificationRateLimited) { t.Fatalf("expected a resend within a minute to be limited, got %v", err) }
+
+	// a minute later resends work until five mails went out that day
+	for i := 1; i < verificationDailyLimit; i++ {
+		for _, token := range tokens.tokens { token.CreatedAt = token.CreatedAt.Add(-2 * time.Minute) }
+		if err := svc.ResendVerification(user.ID); err != nil { t.Fatalf("resend %d: %v", i, err) }
+	}
+	for _, token := range tokens.tokens { token.CreatedAt = token.CreatedAt.Add(-2 * time.Minute) }
+	if err := svc.ResendVerification(user.ID); !errors.Is(err, ErrVerificationRateLimited) { t.Fatalf("expected the daily limit to apply, got %v", err) }
+	if len(mailer.sent) != verificationDailyLimit { t.Fatalf("expected %d mails, got %d", verificationDailyLimit, len(mailer.sent)) }
+
+	// only the newest link works
+	if err := svc.VerifyEmail(linkToken(t, mailer.sent[0])); !errors.Is(err, ErrInvalidVerificationToken) { t.Fatalf("expected a superseded link to be rejected, got %v", err) }
+	if err := svc.VerifyEmail(linkToken(t, mailer.sent[len(mailer.sent)-1])); err != nil { t.Fatalf("verify: %v", err) }
+
+	svc.mailer = nil
+	if err := svc.SendVerification(&models.User{ID: 9}); !errors.Is(err, ErrMailerNotConfigured) { t.Fatalf("expected ErrMailerNotConfigured, got %v", err) }
+}
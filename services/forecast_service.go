@@ -0,0 +1,374 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+var ErrInvalidSchedule = errors.New("invalid scheduled item")
+
+const (
+	defaultForecastDays         = 90
+	defaultForecastLookbackDays = 90
+	forecastConfidence          = 0.8
+	// forecastZScore is the two-sided standard normal quantile for forecastConfidence
+	forecastZScore = 1.2816
+)
+
+type ForecastService interface {
+	CreateRecurringItem(userID uint, req *models.CreateRecurringItemRequest) (*models.RecurringItem, error)
+	GetRecurringItems(userID uint) ([]models.RecurringItem, error)
+	UpdateRecurringItem(id uint, userID uint, req *models.UpdateRecurringItemRequest) (*models.RecurringItem, error)
+	DeleteRecurringItem(id uint, userID uint) error
+	CreateBill(userID uint, req *models.CreateBillRequest) (*models.Bill, error)
+	GetBills(userID uint, unpaidOnly bool) ([]models.Bill, error)
+	UpdateBill(id uint, userID uint, req *models.UpdateBillRequest) (*models.Bill, error)
+	DeleteBill(id uint, userID uint) error
+	GetForecast(userID uint, days, lookbackDays int) (*models.Forecast, error)
+}
+
+type forecastService struct {
+	scheduleRepo    repository.ScheduleRepository
+	transactionRepo repository.TransactionRepository
+	categoryRepo    repository.CategoryRepository
+}
+
+func NewForecastService(scheduleRepo repository.ScheduleRepository, transactionRepo repository.TransactionRepository, categoryRepo repository.CategoryRepository) ForecastService {
+	return &forecastService{
+		scheduleRepo:    scheduleRepo,
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+	}
+}
+
+func (s *forecastService) CreateRecurringItem(userID uint, req *models.CreateRecurringItemRequest) (*models.RecurringItem, error) {
+	item := &models.RecurringItem{
+		UserID:      userID,
+		CategoryID:  req.CategoryID,
+		Type:        req.Type,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Frequency:   req.Frequency,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+	}
+	if err := s.validateRecurring(item); err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.CreateRecurring(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *forecastService) GetRecurringItems(userID uint) ([]models.RecurringItem, error) {
+	return s.scheduleRepo.GetRecurringByUserID(userID)
+}
+
+func (s *forecastService) UpdateRecurringItem(id uint, userID uint, req *models.UpdateRecurringItemRequest) (*models.RecurringItem, error) {
+	item, err := s.scheduleRepo.GetRecurringByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != nil {
+		item.CategoryID = *req.CategoryID
+	}
+	if req.Type != nil {
+		item.Type = *req.Type
+	}
+	if req.Amount != nil {
+		item.Amount = *req.Amount
+	}
+	if req.Description != nil {
+		item.Description = *req.Description
+	}
+	if req.Frequency != nil {
+		item.Frequency = *req.Frequency
+	}
+	if req.StartDate != nil {
+		item.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		item.EndDate = req.EndDate
+	}
+	if req.ClearEndDate {
+		item.EndDate = nil
+	}
+
+	if err := s.validateRecurring(item); err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.UpdateRecurring(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *forecastService) DeleteRecurringItem(id uint, userID uint) error {
+	return s.scheduleRepo.DeleteRecurring(id, userID)
+}
+
+func (s *forecastService) validateRecurring(item *models.RecurringItem) error {
+	if item.EndDate != nil && item.EndDate.Before(item.StartDate) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidSchedule)
+	}
+	if _, err := s.categoryRepo.GetByID(item.CategoryID, item.UserID); err != nil {
+		return fmt.Errorf("%w: category not found or does not belong to user", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+func (s *forecastService) CreateBill(userID uint, req *models.CreateBillRequest) (*models.Bill, error) {
+	if _, err := s.categoryRepo.GetByID(req.CategoryID, userID); err != nil {
+		return nil, fmt.Errorf("%w: category not found or does not belong to user", ErrInvalidSchedule)
+	}
+
+	bill := &models.Bill{
+		UserID:      userID,
+		CategoryID:  req.CategoryID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+	}
+	if err := s.scheduleRepo.CreateBill(bill); err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+func (s *forecastService) GetBills(userID uint, unpaidOnly bool) ([]models.Bill, error) {
+	return s.scheduleRepo.GetBillsByUserID(userID, unpaidOnly)
+}
+
+func (s *forecastService) UpdateBill(id uint, userID uint, req *models.UpdateBillRequest) (*models.Bill, error) {
+	bill, err := s.scheduleRepo.GetBillByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(*req.CategoryID, userID); err != nil {
+			return nil, fmt.Errorf("%w: category not found or does not belong to user", ErrInvalidSchedule)
+		}
+		bill.CategoryID = *req.CategoryID
+	}
+	if req.Amount != nil {
+		bill.Amount = *req.Amount
+	}
+	if req.Description != nil {
+		bill.Description = *req.Description
+	}
+	if req.DueDate != nil {
+		bill.DueDate = *req.DueDate
+	}
+	if req.Paid != nil {
+		if !*req.Paid {
+			bill.PaidAt = nil
+		} else if bill.PaidAt == nil {
+			paidAt := time.Now().UTC()
+			bill.PaidAt = &paidAt
+		}
+	}
+
+	if err := s.scheduleRepo.UpdateBill(bill); err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+func (s *forecastService) DeleteBill(id uint, userID uint) error {
+	return s.scheduleRepo.DeleteBill(id, userID)
+}
+
+func (s *forecastService) GetForecast(userID uint, days, lookbackDays int) (*models.Forecast, error) {
+	return s.forecast(userID, days, lookbackDays, time.Now())
+}
+
+// forecast projects the balance at the end of each of the next days. Recurring items and unpaid bills land
+// on their dates (overdue bills on the first day); categories without a recurring item or an upcoming bill
+// are estimated from their trailing daily average, whose spread sets the confidence band.
+func (s *forecastService) forecast(userID uint, days, lookbackDays int, now time.Time) (*models.Forecast, error) {
+	if days == 0 {
+		days = defaultForecastDays
+	}
+	if lookbackDays == 0 {
+		lookbackDays = defaultForecastLookbackDays
+	}
+	tomorrow := bucketStart(now.UTC(), models.IntervalDay, time.UTC).AddDate(0, 0, 1)
+	horizon := tomorrow.AddDate(0, 0, days)
+	lookbackStart := tomorrow.AddDate(0, 0, -lookbackDays)
+
+	balance, err := s.transactionRepo.GetBalanceBefore(userID, &models.TransactionFilter{StartDate: tomorrow})
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.scheduleRepo.GetRecurringByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	bills, err := s.scheduleRepo.GetBillsByUserID(userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make([][]models.ForecastItem, days)
+	// past transactions in these are already projected by their schedule
+	scheduledKeys := make(map[varianceKey]bool)
+	for _, item := range items {
+		if item.EndDate == nil || !item.EndDate.Before(lookbackStart) {
+			scheduledKeys[varianceKey{item.CategoryID, item.Type}] = true
+		}
+		for _, date := range recurrenceDates(item, tomorrow, horizon) {
+			day := int(date.Sub(tomorrow).Hours() / 24)
+			scheduled[day] = append(scheduled[day], models.ForecastItem{
+				Source: "recurring", ID: item.ID, CategoryID: item.CategoryID, Type: item.Type, Amount: item.Amount, Description: item.Description,
+			})
+		}
+	}
+	for _, bill := range bills {
+		due := bucketStart(bill.DueDate.UTC(), models.IntervalDay, time.UTC)
+		if !due.Before(horizon) {
+			continue
+		}
+		scheduledKeys[varianceKey{bill.CategoryID, models.Expense}] = true
+		day := 0
+		if due.After(tomorrow) {
+			day = int(due.Sub(tomorrow).Hours() / 24)
+		}
+		scheduled[day] = append(scheduled[day], models.ForecastItem{
+			Source: "bill", ID: bill.ID, CategoryID: bill.CategoryID, Type: models.Expense, Amount: bill.Amount, Description: bill.Description,
+		})
+	}
+
+	// Daily net of everything a recurring item or bill does not already cover, over the lookback window
+	dailyNet := make([]float64, lookbackDays)
+	filter := &models.TransactionFilter{StartDate: lookbackStart, EndDate: tomorrow.Add(-time.Nanosecond)}
+	err = s.transactionRepo.StreamByUserID(userID, filter, exportBatchSize, func(batch []models.Transaction) error {
+		for _, transaction := range batch {
+			if scheduledKeys[varianceKey{transaction.CategoryID, transaction.Type}] {
+				continue
+			}
+			day := int(transaction.Date.UTC().Sub(lookbackStart).Hours() / 24)
+			if day >= 0 && day < lookbackDays {
+				dailyNet[day] += exportSignedAmount(transaction)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	mean, stddev := meanAndStdDev(dailyNet)
+
+	forecast := &models.Forecast{
+		AsOf:                tomorrow,
+		StartingBalance:     roundCents(balance),
+		Days:                days,
+		LookbackDays:        lookbackDays,
+		Confidence:          forecastConfidence,
+		VariableDailyNet:    roundCents(mean),
+		VariableDailyStdDev: roundCents(stddev),
+		Daily:               make([]models.ForecastDay, 0, days),
+	}
+	for i := 0; i < days; i++ {
+		day := models.ForecastDay{Date: tomorrow.AddDate(0, 0, i), Scheduled: scheduled[i], Variable: roundCents(mean)}
+		if day.Scheduled == nil {
+			day.Scheduled = []models.ForecastItem{}
+		}
+		for _, item := range day.Scheduled {
+			if item.Type == models.Income {
+				balance += item.Amount
+			} else {
+				balance -= item.Amount
+			}
+		}
+		balance += mean
+
+		band := forecastZScore * stddev * math.Sqrt(float64(i+1))
+		day.Balance = roundCents(balance)
+		day.Lower = roundCents(balance - band)
+		day.Upper = roundCents(balance + band)
+		forecast.Daily = append(forecast.Daily, day)
+
+		date := day.Date
+		if day.Balance < 0 && forecast.FirstNegativeDate == nil {
+			forecast.FirstNegativeDate = &date
+		}
+		if day.Lower < 0 && forecast.FirstNegativeLowerDate == nil {
+			forecast.FirstNegativeLowerDate = &date
+		}
+		if forecast.LowestBalanceDate == nil || day.Balance < forecast.LowestBalance {
+			forecast.LowestBalance = day.Balance
+			forecast.LowestBalanceDate = &date
+		}
+	}
+	return forecast, nil
+}
+
+// recurrenceDates lists the days in [from, to) on which item occurs
+func recurrenceDates(item models.RecurringItem, from, to time.Time) []time.Time {
+	start := bucketStart(item.StartDate.UTC(), models.IntervalDay, time.UTC)
+	var last time.Time
+	if item.EndDate != nil {
+		last = bucketStart(item.EndDate.UTC(), models.IntervalDay, time.UTC)
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		date := recurrenceDate(start, item.Frequency, n)
+		if !date.Before(to) || (item.EndDate != nil && date.After(last)) {
+			return dates
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+	}
+}
+
+// recurrenceDate returns the nth occurrence after start. Monthly schedules keep the day of month and fall
+// back to the last day in shorter months, so an item starting on the 31st stays at the end of the month.
+func recurrenceDate(start time.Time, frequency models.RecurringFrequency, n int) time.Time {
+	switch frequency {
+	case models.FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case models.FrequencyBiweekly:
+		return start.AddDate(0, 0, 14*n)
+	case models.FrequencyQuarterly:
+		return addMonthsClamped(start, 3*n)
+	case models.FrequencyYearly:
+		return addMonthsClamped(start, 12*n)
+	default:
+		return addMonthsClamped(start, n)
+	}
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	day := t.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func meanAndStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+type mockScheduleRepo struct {
+	items []models.RecurringItem
+	bills []models.Bill
+}
+
+func (m *mockScheduleRepo) CreateRecurring(item *models.RecurringItem) error { item.ID = 1; return nil }
+func (m *mockScheduleRepo) GetRecurringByUserID(userID uint) ([]models.RecurringItem, error) { return m.items, nil }
+func (m *mockScheduleRepo) GetRecurringByID(id uint, userID uint) (*models.RecurringItem, error) {
+	for i := range m.items {
+		if m.items[i].ID == id { return &m.items[i], nil }
+	}
+	return nil, errors.New("not found")
+}
+func (m *mockScheduleRepo) UpdateRecurring(item *models.RecurringItem) error { return nil }
+func (m *mockScheduleRepo) DeleteRecurring(id uint, userID uint) error { return nil }
+func (m *mockScheduleRepo) CreateBill(bill *models.Bill) error { bill.ID = 1; return nil }
+func (m *mockScheduleRepo) GetBillsByUserID(userID uint, unpaidOnly bool) ([]models.Bill, error) { return m.bills, nil }
+func (m *mockScheduleRepo) GetBillByID(id uint, userID uint) (*models.Bill, error) {
+	for i := range m.bills {
+		if m.bills[i].ID == id { return &m.bills[i], nil }
+	}
+	return nil, errors.New("not found")
+}
+func (m *mockScheduleRepo) UpdateBill(bill *models.Bill) error { return nil }
+func (m *mockScheduleRepo) DeleteBill(id uint, userID uint) error { return nil }
+
+var _ repository.ScheduleRepository = (*mockScheduleRepo)(nil)
+
+func TestForecastService_Forecast(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	now := time.Date(2025, 9, 10, 15, 0, 0, 0, time.UTC)
+	schedule := &mockScheduleRepo{
+		items: []models.RecurringItem{
+			{ID: 1, CategoryID: 1, Type: models.Income, Amount: 2000, Frequency: models.FrequencyMonthly, StartDate: day(1, 25)},
+			{ID: 2, CategoryID: 2, Type: models.Expense, Amount: 1200, Frequency: models.FrequencyMonthly, StartDate: day(1, 1)},
+		},
+		bills: []models.Bill{
+			{ID: 7, CategoryID: 3, Amount: 50, DueDate: day(9, 1)},
+			{ID: 8, CategoryID: 3, Amount: 300, DueDate: day(9, 20)},
+		},
+	}
+	mTxn := &mockTxnRepo{
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) {
+			if !filter.StartDate.Equal(day(9, 11)) { t.Fatalf("expected balance as of the end of today, got %v", filter.StartDate) }
+			return 1000, nil
+		},
+		StreamFn: func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+			if !filter.StartDate.Equal(day(6, 13)) { t.Fatalf("unexpected lookback start %v", filter.StartDate) }
+			// salary and rent are covered by recurring items; groceries are variable at 10 a day on alternate days
+			batch := []models.Transaction{
+				{CategoryID: 1, Type: models.Income, Amount: 2000, Date: day(8, 25)},
+				{CategoryID: 2, Type: models.Expense, Amount: 1200, Date: day(9, 1)},
+			}
+			for d := day(6, 13); d.Before(day(9, 11)); d = d.AddDate(0, 0, 2) {
+				batch = append(batch, models.Transaction{CategoryID: 4, Type: models.Expense, Amount: 20, Date: d})
+			}
+			return fn(batch)
+		},
+	}
+	svc := &forecastService{scheduleRepo: schedule, transactionRepo: mTxn}
+
+	forecast, err := svc.forecast(1, 30, 90, now)
+	if err != nil { t.Fatalf("forecast: %v", err) }
+	if len(forecast.Daily) != 30 || !forecast.Daily[0].Date.Equal(day(9, 11)) { t.Fatalf("expected 30 days from tomorrow, got %d starting %v", len(forecast.Daily), forecast.Daily[0].Date) }
+	if forecast.VariableDailyNet != -10 || forecast.VariableDailyStdDev != 10 { t.Fatalf("unexpected variable estimate %v ± %v", forecast.VariableDailyNet, forecast.VariableDailyStdDev) }
+
+	// overdue bill on the first day
+	first := forecast.Daily[0]
+	if len(first.Scheduled) != 1 || first.Scheduled[0].ID != 7 || first.Balance != 940 { t.Fatalf("unexpected first day %+v", first) }
+	if math.Abs(first.Upper-first.Balance-12.82) > 0.01 || first.Balance-first.Lower != first.Upper-first.Balance { t.Fatalf("unexpected band %+v", first) }
+
+	sept20 := forecast.Daily[9]
+	if !sept20.Date.Equal(day(9, 20)) || sept20.Balance != 940-90-300 { t.Fatalf("unexpected Sept 20 %+v", sept20) }
+	sept25 := forecast.Daily[14]
+	if len(sept25.Scheduled) != 1 || sept25.Scheduled[0].Source != "recurring" || sept25.Balance != 550-50+2000 { t.Fatalf("unexpected payday %+v", sept25) }
+	oct1 := forecast.Daily[20]
+	if !oct1.Date.Equal(day(10, 1)) || oct1.Balance != 2500-60-1200 { t.Fatalf("unexpected rent day %+v", oct1) }
+
+	if forecast.FirstNegativeDate != nil { t.Fatalf("did not expect a negative balance, got %v", forecast.FirstNegativeDate) }
+	if forecast.LowestBalance != 510 || !forecast.LowestBalanceDate.Equal(day(9, 24)) { t.Fatalf("unexpected lowest balance %v on %v", forecast.LowestBalance, forecast.LowestBalanceDate) }
+}
+
+func TestForecastService_FirstNegativeDate(t *testing.T) {
+	now := time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)
+	schedule := &mockScheduleRepo{bills: []models.Bill{{ID: 1, Amount: 150, DueDate: time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)}}}
+	mTxn := &mockTxnRepo{
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) { return 100, nil },
+		StreamFn: func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error { return nil },
+	}
+	svc := &forecastService{scheduleRepo: schedule, transactionRepo: mTxn}
+
+	forecast, err := svc.forecast(1, 0, 0, now)
+	if err != nil { t.Fatalf("forecast: %v", err) }
+	if forecast.Days != 90 || forecast.LookbackDays != 90 { t.Fatalf("expected defaults, got %d/%d", forecast.Days, forecast.LookbackDays) }
+	want := time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+	if forecast.FirstNegativeDate == nil || !forecast.FirstNegativeDate.Equal(want) { t.Fatalf("expected first negative on %v, got %v", want, forecast.FirstNegativeDate) }
+	if forecast.FirstNegativeLowerDate == nil || !forecast.FirstNegativeLowerDate.Equal(want) { t.Fatalf("expected zero-width band without history, got %v", forecast.FirstNegativeLowerDate) }
+}
+
+func TestForecastService_BillCategoryNotProjected(t *testing.T) {
+	now := time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)
+	schedule := &mockScheduleRepo{bills: []models.Bill{{ID: 1, CategoryID: 5, Amount: 300, DueDate: time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC)}}}
+	mTxn := &mockTxnRepo{
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) { return 400, nil },
+		StreamFn: func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+			// last month's payment of the same bill must not come back as daily spend
+			return fn([]models.Transaction{{CategoryID: 5, Type: models.Expense, Amount: 300, Date: time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC)}})
+		},
+	}
+	svc := &forecastService{scheduleRepo: schedule, transactionRepo: mTxn}
+
+	forecast, err := svc.forecast(1, 30, 30, now)
+	if err != nil { t.Fatalf("forecast: %v", err) }
+	if forecast.VariableDailyNet != 0 { t.Fatalf("expected the bill's category to be left out of the daily average, got %v", forecast.VariableDailyNet) }
+	if forecast.FirstNegativeDate != nil || forecast.LowestBalance != 100 { t.Fatalf("expected the bill to be counted once, got lowest %v first negative %v", forecast.LowestBalance, forecast.FirstNegativeDate) }
+}
+
+func TestRecurrenceDates(t *testing.T) {
+	end := time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)
+	item := models.RecurringItem{Frequency: models.FrequencyMonthly, StartDate: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), EndDate: &end}
+	dates := recurrenceDates(item, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC))
+	want := []string{"2025-02-28", "2025-03-31", "2025-04-30", "2025-05-31"}
+	if len(dates) != len(want) { t.Fatalf("expected %v, got %v", want, dates) }
+	for i := range want {
+		if dates[i].Format("2006-01-02") != want[i] { t.Fatalf("expected %v, got %v", want, dates) }
+	}
+
+	biweekly := models.RecurringItem{Frequency: models.FrequencyBiweekly, StartDate: time.Date(2025, 9, 5, 0, 0, 0, 0, time.UTC)}
+	if dates := recurrenceDates(biweekly, time.Date(2025, 9, 6, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 4, 0, 0, 0, 0, time.UTC)); len(dates) != 2 || dates[0].Day() != 19 || dates[1].Day() != 3 {
+		t.Fatalf("unexpected biweekly dates %v", dates)
+	}
+}
+
+func TestForecastService_CRUDValidation(t *testing.T) {
+	mCat := &mockCatRepo{GetByIDFn: func(id uint, userID uint) (*models.Category, error) {
+		if id != 1 { return nil, errors.New("not found") }
+		return &models.Category{ID: id}, nil
+	}}
+	schedule := &mockScheduleRepo{bills: []models.Bill{{ID: 3, CategoryID: 1, Amount: 10}}}
+	svc := NewForecastService(schedule, &mockTxnRepo{}, mCat)
+
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	before := start.AddDate(0, 0, -1)
+	if _, err := svc.CreateRecurringItem(5, &models.CreateRecurringItemRequest{CategoryID: 1, Type: models.Expense, Amount: 1, Frequency: models.FrequencyWeekly, StartDate: start, EndDate: &before}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected end before start to be rejected, got %v", err)
+	}
+	if _, err := svc.CreateBill(5, &models.CreateBillRequest{CategoryID: 2, Amount: 1, DueDate: start}); !errors.Is(err, ErrInvalidSchedule) { t.Fatalf("expected foreign category to be rejected, got %v", err) }
+
+	paid := true
+	bill, err := svc.UpdateBill(3, 5, &models.UpdateBillRequest{Paid: &paid})
+	if err != nil || bill.PaidAt == nil { t.Fatalf("expected bill marked paid: %v %+v", err, bill) }
+	paid = false
+	if bill, _ := svc.UpdateBill(3, 5, &models.UpdateBillRequest{Paid: &paid}); bill.PaidAt != nil { t.Fatalf("expected bill unpaid again") }
+}
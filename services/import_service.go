@@ -0,0 +1,355 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+var (
+	ErrImportHasInvalidRows = errors.New("import contains invalid rows; fix the mapping or set skip_errors")
+	ErrNothingToImport      = errors.New("no new transactions to import")
+)
+
+type ImportService interface {
+	CreateMapping(userID uint, req *models.CreateImportMappingRequest) (*models.ImportMapping, error)
+	GetMappings(userID uint) ([]models.ImportMapping, error)
+	GetMapping(id uint, userID uint) (*models.ImportMapping, error)
+	DeleteMapping(id uint, userID uint) error
+	PreviewCSV(userID uint, data io.Reader, mapping *models.ImportMapping) (*models.ImportPreview, error)
+	CommitCSV(userID uint, fileName string, data io.Reader, mapping *models.ImportMapping, skipErrors bool) (*models.ImportBatch, error)
+	PreviewOFX(userID uint, data io.Reader, categoryID uint) (*models.ImportPreview, error)
+	CommitOFX(userID uint, fileName string, data io.Reader, categoryID uint, skipErrors bool) (*models.ImportBatch, error)
+	PreviewQIF(userID uint, data io.Reader, categoryID uint, dayFirst bool) (*models.ImportPreview, error)
+	CommitQIF(userID uint, fileName string, data io.Reader, categoryID uint, dayFirst bool, skipErrors bool) (*models.ImportBatch, error)
+	GetImports(userID uint) ([]models.ImportBatch, error)
+	UndoImport(id uint, userID uint) error
+}
+
+type importService struct {
+	importRepo      repository.ImportRepository
+	transactionRepo repository.TransactionRepository
+	categoryRepo    repository.CategoryRepository
+}
+
+func NewImportService(importRepo repository.ImportRepository, transactionRepo repository.TransactionRepository, categoryRepo repository.CategoryRepository) ImportService {
+	return &importService{
+		importRepo:      importRepo,
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+	}
+}
+
+func (s *importService) CreateMapping(userID uint, req *models.CreateImportMappingRequest) (*models.ImportMapping, error) {
+	mapping := &models.ImportMapping{
+		UserID:            userID,
+		Name:              req.Name,
+		Delimiter:         req.Delimiter,
+		HasHeader:         req.HasHeader,
+		DateColumn:        req.DateColumn,
+		DateFormat:        req.DateFormat,
+		AmountColumn:      req.AmountColumn,
+		DebitColumn:       req.DebitColumn,
+		CreditColumn:      req.CreditColumn,
+		AmountSign:        req.AmountSign,
+		DecimalComma:      req.DecimalComma,
+		DescriptionColumn: req.DescriptionColumn,
+		CategoryColumn:    req.CategoryColumn,
+		DefaultCategoryID: req.DefaultCategoryID,
+	}
+
+	if err := validateMapping(mapping); err != nil {
+		return nil, err
+	}
+
+	if mapping.DefaultCategoryID != 0 {
+		if _, err := s.categoryRepo.GetByID(mapping.DefaultCategoryID, userID); err != nil {
+			return nil, errors.New("category not found or does not belong to user")
+		}
+	}
+
+	if err := s.importRepo.CreateMapping(mapping); err != nil {
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+func (s *importService) GetMappings(userID uint) ([]models.ImportMapping, error) {
+	return s.importRepo.GetMappingsByUserID(userID)
+}
+
+func (s *importService) GetMapping(id uint, userID uint) (*models.ImportMapping, error) {
+	return s.importRepo.GetMappingByID(id, userID)
+}
+
+func (s *importService) DeleteMapping(id uint, userID uint) error {
+	return s.importRepo.DeleteMapping(id, userID)
+}
+
+func (s *importService) PreviewCSV(userID uint, data io.Reader, mapping *models.ImportMapping) (*models.ImportPreview, error) {
+	rows, err := ParseCSV(data, mapping)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(userID, rows, mapping.DefaultCategoryID)
+}
+
+func (s *importService) CommitCSV(userID uint, fileName string, data io.Reader, mapping *models.ImportMapping, skipErrors bool) (*models.ImportBatch, error) {
+	preview, err := s.PreviewCSV(userID, data, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.ImportBatch{
+		UserID:   userID,
+		Source:   models.ImportSourceCSV,
+		FileName: fileName,
+	}
+	if mapping.ID != 0 {
+		batch.MappingID = &mapping.ID
+	}
+
+	return s.commit(batch, preview, skipErrors)
+}
+
+// OFX statements carry no categories, so every row is assigned categoryID
+func (s *importService) PreviewOFX(userID uint, data io.Reader, categoryID uint) (*models.ImportPreview, error) {
+	if categoryID == 0 {
+		return nil, errors.New("category_id is required for OFX imports")
+	}
+	rows, err := ParseOFX(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(userID, rows, categoryID)
+}
+
+func (s *importService) CommitOFX(userID uint, fileName string, data io.Reader, categoryID uint, skipErrors bool) (*models.ImportBatch, error) {
+	preview, err := s.PreviewOFX(userID, data, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.ImportBatch{
+		UserID:   userID,
+		Source:   models.ImportSourceOFX,
+		FileName: fileName,
+	}
+
+	return s.commit(batch, preview, skipErrors)
+}
+
+// QIF categories are matched by name; categoryID is used for lines without a known category
+func (s *importService) PreviewQIF(userID uint, data io.Reader, categoryID uint, dayFirst bool) (*models.ImportPreview, error) {
+	rows, err := ParseQIF(data, dayFirst)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(userID, rows, categoryID)
+}
+
+func (s *importService) CommitQIF(userID uint, fileName string, data io.Reader, categoryID uint, dayFirst bool, skipErrors bool) (*models.ImportBatch, error) {
+	preview, err := s.PreviewQIF(userID, data, categoryID, dayFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.ImportBatch{
+		UserID:   userID,
+		Source:   models.ImportSourceQIF,
+		FileName: fileName,
+	}
+
+	return s.commit(batch, preview, skipErrors)
+}
+
+func (s *importService) GetImports(userID uint) ([]models.ImportBatch, error) {
+	return s.importRepo.GetBatchesByUserID(userID)
+}
+
+func (s *importService) UndoImport(id uint, userID uint) error {
+	return s.transactionRepo.UndoImport(id, userID)
+}
+
+// preview resolves categories and flags rows that already exist for the user
+func (s *importService) preview(userID uint, rows []models.ImportRow, defaultCategoryID uint) (*models.ImportPreview, error) {
+	categories, err := s.categoryRepo.GetByUserID(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]uint, len(categories))
+	owned := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		byName[strings.ToLower(strings.TrimSpace(category.Name))] = category.ID
+		owned[category.ID] = true
+	}
+
+	var first, last time.Time
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Errors) > 0 {
+			continue
+		}
+
+		if id, ok := matchCategory(byName, row.CategoryName); ok {
+			row.CategoryID = id
+		} else if defaultCategoryID != 0 && owned[defaultCategoryID] {
+			row.CategoryID = defaultCategoryID
+		} else if row.CategoryName != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("unknown category %q", row.CategoryName))
+			continue
+		} else {
+			row.Errors = append(row.Errors, "no category and no default category configured")
+			continue
+		}
+
+		if first.IsZero() || row.Date.Before(first) {
+			first = row.Date
+		}
+		if row.Date.After(last) {
+			last = row.Date
+		}
+	}
+
+	if err := s.flagDuplicates(userID, rows, first, last); err != nil {
+		return nil, err
+	}
+
+	preview := &models.ImportPreview{Rows: rows, Total: len(rows)}
+	for _, row := range rows {
+		switch {
+		case len(row.Errors) > 0:
+			preview.Invalid++
+		case row.Duplicate:
+			preview.Duplicates++
+		default:
+			preview.Valid++
+		}
+	}
+
+	return preview, nil
+}
+
+// flagDuplicates matches rows carrying a bank-assigned ID (e.g. OFX FITID) by that ID only,
+// and all other rows by date, amount, type and description
+func (s *importService) flagDuplicates(userID uint, rows []models.ImportRow, first, last time.Time) error {
+	var externalIDs []string
+	fuzzy := false
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			continue
+		}
+		if row.ExternalID != "" {
+			externalIDs = append(externalIDs, row.ExternalID)
+		} else {
+			fuzzy = true
+		}
+	}
+
+	known := make(map[string]bool)
+	if len(externalIDs) > 0 {
+		found, err := s.transactionRepo.FindExternalIDs(userID, externalIDs)
+		if err != nil {
+			return err
+		}
+		for _, id := range found {
+			known[id] = true
+		}
+	}
+
+	if fuzzy && !first.IsZero() {
+		existing, err := s.transactionRepo.GetByUserID(userID, &models.TransactionFilter{
+			StartDate: first.Truncate(24 * time.Hour),
+			EndDate:   last.Truncate(24 * time.Hour).Add(24*time.Hour - time.Nanosecond),
+		})
+		if err != nil {
+			return err
+		}
+		for _, transaction := range existing {
+			known[duplicateKey(transaction.Date, transaction.Amount, transaction.Type, transaction.Description)] = true
+		}
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Errors) > 0 {
+			continue
+		}
+		if row.ExternalID != "" {
+			// a FITID repeated within the same file is also a duplicate
+			row.Duplicate = known[row.ExternalID]
+			known[row.ExternalID] = true
+			continue
+		}
+		row.Duplicate = known[duplicateKey(row.Date, row.Amount, row.Type, row.Description)]
+	}
+
+	return nil
+}
+
+func (s *importService) commit(batch *models.ImportBatch, preview *models.ImportPreview, skipErrors bool) (*models.ImportBatch, error) {
+	if preview.Invalid > 0 && !skipErrors {
+		return nil, ErrImportHasInvalidRows
+	}
+
+	transactions := make([]models.Transaction, 0, preview.Valid)
+	for _, row := range preview.Rows {
+		if len(row.Errors) > 0 || row.Duplicate {
+			continue
+		}
+		transactions = append(transactions, models.Transaction{
+			UserID:      batch.UserID,
+			CategoryID:  row.CategoryID,
+			Amount:      row.Amount,
+			Type:        row.Type,
+			Description: row.Description,
+			Date:        row.Date,
+			ExternalID:  row.ExternalID,
+		})
+	}
+	if len(transactions) == 0 {
+		return nil, ErrNothingToImport
+	}
+
+	batch.Status = models.ImportBatchCommitted
+	batch.RowCount = len(transactions)
+	batch.SkippedCount = preview.Total - len(transactions)
+
+	if err := s.transactionRepo.CreateImport(batch, transactions); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// matchCategory looks up a category by name, falling back to the parent of "Parent:Child" names
+func matchCategory(byName map[string]uint, name string) (uint, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return 0, false
+	}
+	if id, ok := byName[name]; ok {
+		return id, true
+	}
+	if i := strings.IndexByte(name, ':'); i > 0 {
+		id, ok := byName[strings.TrimSpace(name[:i])]
+		return id, ok
+	}
+	return 0, false
+}
+
+func duplicateKey(date time.Time, amount float64, txnType models.TransactionType, description string) string {
+	return fmt.Sprintf("%s|%d|%s|%s",
+		date.UTC().Format("2006-01-02"),
+		int64(math.Round(amount*100)),
+		txnType,
+		strings.ToLower(strings.TrimSpace(description)),
+	)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+type mockImportRepo struct {
+	CreateMappingFn func(mapping *models.ImportMapping) error
+	ListMappingsFn  func(userID uint) ([]models.ImportMapping, error)
+	GetMappingFn    func(id uint, userID uint) (*models.ImportMapping, error)
+	DeleteMappingFn func(id uint, userID uint) error
+	ListBatchesFn   func(userID uint) ([]models.ImportBatch, error)
+	GetBatchFn      func(id uint, userID uint) (*models.ImportBatch, error)
+}
+
+func (m *mockImportRepo) CreateMapping(mapping *models.ImportMapping) error { return m.CreateMappingFn(mapping) }
+func (m *mockImportRepo) GetMappingsByUserID(userID uint) ([]models.ImportMapping, error) {
+	return m.ListMappingsFn(userID)
+}
+func (m *mockImportRepo) GetMappingByID(id uint, userID uint) (*models.ImportMapping, error) {
+	return m.GetMappingFn(id, userID)
+}
+func (m *mockImportRepo) DeleteMapping(id uint, userID uint) error { return m.DeleteMappingFn(id, userID) }
+func (m *mockImportRepo) GetBatchesByUserID(userID uint) ([]models.ImportBatch, error) {
+	return m.ListBatchesFn(userID)
+}
+func (m *mockImportRepo) GetBatchByID(id uint, userID uint) (*models.ImportBatch, error) {
+	return m.GetBatchFn(id, userID)
+}
+
+var _ repository.ImportRepository = (*mockImportRepo)(nil)
+
+func importCategories() *mockCatRepo {
+	return &mockCatRepo{
+		GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return &models.Category{ID: id, UserID: userID}, nil },
+		GetByUserIDFn: func(userID uint) ([]models.Category, error) {
+			return []models.Category{{ID: 1, UserID: userID, Name: "Food"}, {ID: 2, UserID: userID, Name: "Other"}}, nil
+		},
+	}
+}
+
+const importCSV = "date,description,amount,category\n" +
+	"2025-09-01,Lunch,-12.50,food\n" +
+	"2025-09-02,Refund,5.00,\n" +
+	"2025-09-03,Mystery,abc,Travel\n"
+
+func TestImportService_PreviewCSV_CategoriesAndDuplicates(t *testing.T) {
+	mTxn := &mockTxnRepo{ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) {
+		if filter.StartDate.IsZero() || filter.EndDate.Before(time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("expected duplicate lookup to cover the file's date range, got %+v", filter)
+		}
+		return []models.Transaction{{Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Amount: 12.5, Type: models.Expense, Description: "lunch"}}, nil
+	}}
+	svc := NewImportService(&mockImportRepo{}, mTxn, importCategories())
+	mapping := &models.ImportMapping{HasHeader: true, DateColumn: "date", AmountColumn: "amount", DescriptionColumn: "description", CategoryColumn: "category", DefaultCategoryID: 2}
+
+	preview, err := svc.PreviewCSV(5, strings.NewReader(importCSV), mapping)
+	if err != nil { t.Fatalf("preview: %v", err) }
+	if preview.Total != 3 || preview.Duplicates != 1 || preview.Valid != 1 || preview.Invalid != 1 {
+		t.Fatalf("unexpected counts: %+v", preview)
+	}
+	if !preview.Rows[0].Duplicate || preview.Rows[0].CategoryID != 1 { t.Fatalf("expected first row to be a Food duplicate: %+v", preview.Rows[0]) }
+	if preview.Rows[1].CategoryID != 2 { t.Fatalf("expected default category for blank category: %+v", preview.Rows[1]) }
+	if len(preview.Rows[2].Errors) == 0 { t.Fatalf("expected invalid amount error: %+v", preview.Rows[2]) }
+
+	mapping.DefaultCategoryID = 0
+	preview, err = svc.PreviewCSV(5, strings.NewReader("date,description,amount,category\n2025-09-04,Taxi,-1.00,Travel\n"), mapping)
+	if err != nil { t.Fatalf("preview: %v", err) }
+	if preview.Invalid != 1 || len(preview.Rows[0].Errors) == 0 { t.Fatalf("expected unknown category error without default: %+v", preview.Rows[0]) }
+}
+
+func TestImportService_CommitCSV(t *testing.T) {
+	var committed []models.Transaction
+	mTxn := &mockTxnRepo{
+		ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) { return nil, nil },
+		CreateImportFn: func(batch *models.ImportBatch, transactions []models.Transaction) error {
+			batch.ID = 9
+			committed = transactions
+			return nil
+		},
+	}
+	svc := NewImportService(&mockImportRepo{}, mTxn, importCategories())
+	mapping := &models.ImportMapping{ID: 3, HasHeader: true, DateColumn: "date", AmountColumn: "amount", DescriptionColumn: "description", CategoryColumn: "category", DefaultCategoryID: 2}
+
+	if _, err := svc.CommitCSV(5, "bank.csv", strings.NewReader(importCSV), mapping, false); !errors.Is(err, ErrImportHasInvalidRows) {
+		t.Fatalf("expected invalid rows error, got %v", err)
+	}
+
+	batch, err := svc.CommitCSV(5, "bank.csv", strings.NewReader(importCSV), mapping, true)
+	if err != nil { t.Fatalf("commit: %v", err) }
+	if batch.ID != 9 || batch.RowCount != 2 || batch.SkippedCount != 1 || batch.Status != models.ImportBatchCommitted {
+		t.Fatalf("unexpected batch: %+v", batch)
+	}
+	if batch.MappingID == nil || *batch.MappingID != 3 || batch.Source != models.ImportSourceCSV { t.Fatalf("unexpected batch metadata: %+v", batch) }
+	if len(committed) != 2 || committed[0].UserID != 5 { t.Fatalf("unexpected committed transactions: %+v", committed) }
+}
+
+func TestImportService_CreateMapping_Validation(t *testing.T) {
+	created := false
+	mImp := &mockImportRepo{CreateMappingFn: func(mapping *models.ImportMapping) error { created = true; return nil }}
+	svc := NewImportService(mImp, &mockTxnRepo{}, importCategories())
+
+	if _, err := svc.CreateMapping(5, &models.CreateImportMappingRequest{Name: "Bank", DateColumn: "date"}); err == nil {
+		t.Fatalf("expected error when mapping has no amount columns")
+	}
+	mapping, err := svc.CreateMapping(5, &models.CreateImportMappingRequest{Name: "Bank", DateColumn: "date", AmountColumn: "amount"})
+	if err != nil || !created { t.Fatalf("create mapping: %v", err) }
+	if mapping.UserID != 5 || mapping.Name != "Bank" { t.Fatalf("unexpected mapping: %+v", mapping) }
+}
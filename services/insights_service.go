@@ -0,0 +1,61 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+type InsightsService interface {
+	GetAnomalies(userID uint, startDate, endDate string) (*models.AnomalyReport, error)
+	GetKPIs(userID uint, startDate, endDate string) (*models.FinancialKPIs, error)
+}
+
+type insightsService struct {
+	transactionRepo repository.TransactionRepository
+	userRepo        repository.UserRepository
+}
+
+func NewInsightsService(transactionRepo repository.TransactionRepository, userRepo repository.UserRepository) InsightsService {
+	return &insightsService{
+		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// GetAnomalies lists the unusual expenses and category spikes of the period (default: current month in
+// the user's time zone), most unusual first
+func (s *insightsService) GetAnomalies(userID uint, startDate, endDate string) (*models.AnomalyReport, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := resolveReportPeriod(startDate, endDate, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AnomalyReport{
+		StartDate:      start,
+		EndDate:        end,
+		LookbackMonths: anomalyLookbackMonths,
+		Threshold:      anomalyThreshold,
+		Transactions:   []models.Transaction{},
+	}
+	detector, err := detectAnomalies(s.transactionRepo, userID, start, end, loc, func(transaction models.Transaction, anomaly *models.TransactionAnomaly) {
+		if anomaly != nil {
+			transaction.Anomaly = anomaly
+			report.Transactions = append(report.Transactions, transaction)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(report.Transactions, func(i, j int) bool {
+		return report.Transactions[i].Anomaly.Score > report.Transactions[j].Anomaly.Score
+	})
+	report.CategorySpikes = detector.Spikes(start, end)
+	return report, nil
+}
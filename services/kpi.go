@@ -0,0 +1,160 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// daysPerMonth is the length of an average month, used to turn daily rates into monthly ones
+const daysPerMonth = 365.25 / 12
+
+// kpiPeriod holds the raw figures of one period that the indicators are derived from
+type kpiPeriod struct {
+	days    float64
+	income  float64
+	expense float64
+	fixed   float64
+	cash    float64
+}
+
+// GetKPIs computes the financial health indicators of the period (default: current month) and of the
+// period of equal length before it. Dates are read in the user's time zone.
+func (s *insightsService) GetKPIs(userID uint, startDate, endDate string) (*models.FinancialKPIs, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := resolveReportPeriod(startDate, endDate, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+	current := models.ReportPeriod{StartDate: start, EndDate: end}
+	previous := precedingPeriod(current, loc)
+
+	now, err := s.kpiPeriod(userID, current)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.kpiPeriod(userID, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.FinancialKPIs{
+		Current:              current,
+		Previous:             previous,
+		Days:                 int(math.Round(now.days)),
+		Income:               roundCents(now.income),
+		Expense:              roundCents(now.expense),
+		FixedExpense:         roundCents(now.fixed),
+		DiscretionaryExpense: roundCents(now.expense - now.fixed),
+		CashBalance:          roundCents(now.cash),
+		SavingsRate:          kpiValue(now.savingsRate(), before.savingsRate(), true),
+		AverageDailySpend:    kpiValue(now.dailySpend(), before.dailySpend(), false),
+		BurnRate:             kpiValue(now.burnRate(), before.burnRate(), false),
+		RunwayMonths:         kpiValue(now.runway(), before.runway(), true),
+		FixedRatio:           kpiValue(now.fixedRatio(), before.fixedRatio(), false),
+	}, nil
+}
+
+// kpiPeriod sums the period exactly like GetSummary and takes the cash balance at its end
+func (s *insightsService) kpiPeriod(userID uint, period models.ReportPeriod) (*kpiPeriod, error) {
+	totals, err := s.transactionRepo.GetSummaryByCategory(userID, period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	cash, err := s.transactionRepo.GetBalanceBefore(userID, &models.TransactionFilter{StartDate: period.EndDate})
+	if err != nil {
+		return nil, err
+	}
+
+	figures := &kpiPeriod{days: period.EndDate.Sub(period.StartDate).Hours() / 24, cash: cash}
+	for _, total := range totals {
+		if total.Type == models.Income {
+			figures.income += total.Total
+			continue
+		}
+		figures.expense += total.Total
+		if total.Fixed {
+			figures.fixed += total.Total
+		}
+	}
+	return figures, nil
+}
+
+// savingsRate is the share of income that was not spent
+func (p *kpiPeriod) savingsRate() *float64 {
+	if p.income <= 0 {
+		return nil
+	}
+	rate := (p.income - p.expense) / p.income * 100
+	return &rate
+}
+
+func (p *kpiPeriod) dailySpend() *float64 {
+	if p.days <= 0 {
+		return nil
+	}
+	spend := p.expense / p.days
+	return &spend
+}
+
+// burnRate is the net outflow per month; it is negative while income exceeds spending
+func (p *kpiPeriod) burnRate() *float64 {
+	if p.days <= 0 {
+		return nil
+	}
+	burn := (p.expense - p.income) / p.days * daysPerMonth
+	return &burn
+}
+
+// runway is how many months the cash balance covers spending at the period's pace without any income
+func (p *kpiPeriod) runway() *float64 {
+	spend := p.dailySpend()
+	if spend == nil || *spend <= 0 {
+		return nil
+	}
+	months := math.Max(p.cash, 0) / (*spend * daysPerMonth)
+	return &months
+}
+
+// fixedRatio is the share of spending that went to categories flagged as fixed
+func (p *kpiPeriod) fixedRatio() *float64 {
+	if p.expense <= 0 {
+		return nil
+	}
+	ratio := p.fixed / p.expense * 100
+	return &ratio
+}
+
+func kpiValue(current, previous *float64, higherIsBetter bool) models.KPIValue {
+	var value models.KPIValue
+	if current != nil {
+		rounded := roundCents(*current)
+		value.Current = &rounded
+	}
+	if previous != nil {
+		rounded := roundCents(*previous)
+		value.Previous = &rounded
+	}
+	if value.Current == nil || value.Previous == nil {
+		return value
+	}
+
+	delta := roundCents(*value.Current - *value.Previous)
+	value.Delta = &delta
+	switch {
+	case delta > 0:
+		value.Trend = models.TrendUp
+	case delta < 0:
+		value.Trend = models.TrendDown
+	default:
+		value.Trend = models.TrendFlat
+		return value
+	}
+	improved := (delta > 0) == higherIsBetter
+	value.Improved = &improved
+	return value
+}
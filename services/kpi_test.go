@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestInsightsService_GetKPIs(t *testing.T) {
+	mTxn := &mockTxnRepo{
+		SummaryByCategoryFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+			switch start.Format("2006-01-02") + " " + end.Format("2006-01-02") {
+			case "2025-09-01 2025-10-01":
+				return []models.CategoryTotal{
+					{CategoryID: 1, Type: models.Income, Total: 4000},
+					{CategoryID: 2, Type: models.Expense, Fixed: true, Total: 1200},
+					{CategoryID: 3, Type: models.Expense, Total: 600},
+				}, nil
+			case "2025-08-01 2025-09-01":
+				return []models.CategoryTotal{
+					{CategoryID: 1, Type: models.Income, Total: 4000},
+					{CategoryID: 2, Type: models.Expense, Fixed: true, Total: 1200},
+					{CategoryID: 3, Type: models.Expense, Total: 1900},
+				}, nil
+			}
+			t.Fatalf("unexpected bounds %v - %v", start, end)
+			return nil, nil
+		},
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) {
+			if filter.StartDate.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)) { return 9000, nil }
+			return 6800, nil
+		},
+	}
+	svc := NewInsightsService(mTxn, userRepoIn("UTC"))
+
+	kpis, err := svc.GetKPIs(1, "2025-09-01", "")
+	if err != nil { t.Fatalf("kpis: %v", err) }
+	if kpis.Days != 30 || kpis.Expense != 1800 || kpis.FixedExpense != 1200 || kpis.DiscretionaryExpense != 600 || kpis.CashBalance != 9000 { t.Fatalf("unexpected figures %+v", kpis) }
+	if !kpis.Previous.StartDate.Equal(time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)) { t.Fatalf("expected August as the prior period, got %+v", kpis.Previous) }
+
+	check := func(name string, value models.KPIValue, current, previous float64, trend models.KPITrend, improved bool) {
+		t.Helper()
+		if value.Current == nil || *value.Current != current || value.Previous == nil || *value.Previous != previous || value.Trend != trend || value.Improved == nil || *value.Improved != improved {
+			t.Fatalf("unexpected %s: %+v", name, value)
+		}
+	}
+	check("savings rate", kpis.SavingsRate, 55, 22.5, models.TrendUp, true)
+	check("daily spend", kpis.AverageDailySpend, 60, 100, models.TrendDown, true)
+	check("burn rate", kpis.BurnRate, -2232.08, -883.67, models.TrendDown, true)
+	check("runway", kpis.RunwayMonths, 4.93, 2.23, models.TrendUp, true)
+	check("fixed ratio", kpis.FixedRatio, 66.67, 38.71, models.TrendUp, false)
+}
+
+func TestInsightsService_GetKPIs_MissingValues(t *testing.T) {
+	mTxn := &mockTxnRepo{
+		SummaryByCategoryFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+			if start.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)) { return []models.CategoryTotal{{CategoryID: 3, Type: models.Expense, Total: 300}}, nil }
+			return nil, nil
+		},
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) { return -50, nil },
+	}
+	svc := NewInsightsService(mTxn, userRepoIn("UTC"))
+
+	kpis, err := svc.GetKPIs(1, "2025-09-01", "2025-09-30")
+	if err != nil { t.Fatalf("kpis: %v", err) }
+	if kpis.SavingsRate.Current != nil || kpis.SavingsRate.Trend != "" { t.Fatalf("expected no savings rate without income, got %+v", kpis.SavingsRate) }
+	if kpis.RunwayMonths.Current == nil || *kpis.RunwayMonths.Current != 0 || kpis.RunwayMonths.Previous != nil { t.Fatalf("expected zero runway on a negative balance and none without spending, got %+v", kpis.RunwayMonths) }
+	if kpis.AverageDailySpend.Trend != models.TrendUp || *kpis.AverageDailySpend.Delta != 10 { t.Fatalf("unexpected daily spend %+v", kpis.AverageDailySpend) }
+
+	if _, err := svc.GetKPIs(1, "2025-09-30", "2025-09-01"); !errors.Is(err, ErrInvalidSummaryRange) { t.Fatalf("expected ErrInvalidSummaryRange, got %v", err) }
+}
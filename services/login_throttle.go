@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+const (
+	// loginBaseDelay is how long a key waits after its first failure; the wait doubles with each
+	// further failure until the key is locked out
+	loginBaseDelay = time.Second
+	// loginMaxLockout caps the lockout, which doubles with every failure after the limit
+	loginMaxLockout = 24 * time.Hour
+	// loginFailureWindow is how long a failure counts towards the limit
+	loginFailureWindow = 24 * time.Hour
+)
+
+// Reasons an account was unlocked
+const (
+	UnlockPasswordReset = "password_reset"
+	UnlockAdmin         = "admin"
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, please try again later")
+
+// LoginThrottledError tells how long to wait before the next attempt. It matches
+// ErrTooManyLoginAttempts and reads the same whichever key is locked.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string { return ErrTooManyLoginAttempts.Error() }
+
+func (e *LoginThrottledError) Unwrap() error { return ErrTooManyLoginAttempts }
+
+// LoginThrottle counts failed logins per account and per client address. Accounts are tracked by the
+// email that was tried, whether or not it exists, so a lockout doesn't reveal which accounts exist.
+type LoginThrottle interface {
+	Check(email, clientIP string) error
+	Failure(email, clientIP string, userID uint) error
+	Success(email string) error
+	Unlock(email string, userID uint, reason string) (bool, error)
+	UnlockAddress(clientIP string) error
+}
+
+type loginThrottle struct {
+	store         repository.LoginAttemptStore
+	events        repository.SecurityEventRepository
+	maxFailures   int
+	ipMaxFailures int
+	lockout       time.Duration
+	now           func() time.Time
+}
+
+func NewLoginThrottle(store repository.LoginAttemptStore, events repository.SecurityEventRepository, cfg config.LoginConfig) LoginThrottle {
+	return &loginThrottle{
+		store:         store,
+		events:        events,
+		maxFailures:   cfg.MaxFailures,
+		ipMaxFailures: cfg.IPMaxFailures,
+		lockout:       time.Duration(cfg.LockoutMinutes) * time.Minute,
+		now:           time.Now,
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func addressKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+// Check refuses the login while the account or the address waits out a backoff or lockout
+func (t *loginThrottle) Check(email, clientIP string) error {
+	keys := []string{accountKey(email)}
+	if clientIP != "" {
+		keys = append(keys, addressKey(clientIP))
+	}
+	now := t.now()
+	var wait time.Duration
+	for _, key := range keys {
+		attempt, err := t.store.Get(key)
+		if err != nil {
+			return err
+		}
+		if remaining := attempt.LockedUntil.Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Failure counts a wrong password or second factor. userID is 0 when no account has the email;
+// lockouts of existing accounts are recorded as security events.
+func (t *loginThrottle) Failure(email, clientIP string, userID uint) error {
+	now := t.now()
+	attempt, err := t.store.RecordFailure(accountKey(email), t.fail(now, t.maxFailures))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures >= t.maxFailures && userID != 0 {
+		log.Printf("Security: account %d locked until %s after %d failed logins, last from %s", userID, attempt.LockedUntil.Format(time.RFC3339), attempt.Failures, clientIP)
+		err := t.events.Create(&models.SecurityEvent{
+			UserID:    userID,
+			Type:      models.SecurityAccountLocked,
+			IPAddress: clientIP,
+			Detail:    "locked until " + attempt.LockedUntil.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if clientIP == "" {
+		return nil
+	}
+	attempt, err = t.store.RecordFailure(addressKey(clientIP), t.fail(now, t.ipMaxFailures))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures >= t.ipMaxFailures {
+		log.Printf("Security: address %s locked until %s after %d failed logins", clientIP, attempt.LockedUntil.Format(time.RFC3339), attempt.Failures)
+	}
+	return nil
+}
+
+// fail counts one more failure and blocks the key for the resulting delay
+func (t *loginThrottle) fail(now time.Time, maxFailures int) func(attempt *models.LoginAttempt) {
+	return func(attempt *models.LoginAttempt) {
+		if now.Sub(attempt.LastFailureAt) > loginFailureWindow && !now.Before(attempt.LockedUntil) {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		attempt.LockedUntil = now.Add(lockDuration(attempt.Failures, maxFailures, t.lockout))
+	}
+}
+
+// lockDuration is one second after the first failure, doubling with each failure, until maxFailures
+// lock the key for lockout, which doubles with each failure after that, up to a day
+func lockDuration(failures, maxFailures int, lockout time.Duration) time.Duration {
+	delay, doublings := loginBaseDelay, failures-1
+	if failures >= maxFailures {
+		delay, doublings = lockout, failures-maxFailures
+	}
+	for ; doublings > 0 && delay < loginMaxLockout; doublings-- {
+		delay *= 2
+	}
+	if delay > loginMaxLockout {
+		delay = loginMaxLockout
+	}
+	return delay
+}
+
+// Success clears the account's failures; the address keeps its count
+func (t *loginThrottle) Success(email string) error {
+	return t.store.Reset(accountKey(email))
+}
+
+// Unlock clears the account's failures and reports whether it was locked out
+func (t *loginThrottle) Unlock(email string, userID uint, reason string) (bool, error) {
+	key := accountKey(email)
+	attempt, err := t.store.Get(key)
+	if err != nil {
+		return false, err
+	}
+	if err := t.store.Reset(key); err != nil {
+		return false, err
+	}
+	locked := attempt.Failures >= t.maxFailures && t.now().Before(attempt.LockedUntil)
+	if !locked || userID == 0 {
+		return locked, nil
+	}
+	log.Printf("Security: account %d unlocked (%s)", userID, reason)
+	return true, t.events.Create(&models.SecurityEvent{UserID: userID, Type: models.SecurityAccountUnlocked, Detail: reason})
+}
+
+func (t *loginThrottle) UnlockAddress(clientIP string) error {
+	return t.store.Reset(addressKey(clientIP))
+}
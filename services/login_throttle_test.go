@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+// stubThrottle never refuses a login, for tests that aren't about lockouts
+type stubThrottle struct{}
+
+func (s *stubThrottle) Check(email, clientIP string) error                  { return nil }
+func (s *stubThrottle) Failure(email, clientIP string, userID uint) error   { return nil }
+func (s *stubThrottle) Success(email string) error                          { return nil }
+func (s *stubThrottle) Unlock(email string, userID uint, reason string) (bool, error) { return false, nil }
+func (s *stubThrottle) UnlockAddress(clientIP string) error                 { return nil }
+
+type fakeSecurityEvents struct{ events []models.SecurityEvent }
+
+func (f *fakeSecurityEvents) Create(event *models.SecurityEvent) error { f.events = append(f.events, *event); return nil }
+func (f *fakeSecurityEvents) GetByUserID(userID uint, limit int) ([]models.SecurityEvent, error) { return f.events, nil }
+
+var _ repository.SecurityEventRepository = (*fakeSecurityEvents)(nil)
+
+// newTestThrottle locks an account after 3 failures and an address after 5, for 15 minutes
+func newTestThrottle() *loginThrottle {
+	return NewLoginThrottle(repository.NewMemoryLoginAttemptStore(), &fakeSecurityEvents{}, config.LoginConfig{MaxFailures: 3, IPMaxFailures: 5, LockoutMinutes: 15}).(*loginThrottle)
+}
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) || !errors.Is(err, ErrTooManyLoginAttempts) { t.Fatalf("expected a LoginThrottledError, got %v", err) }
+	return throttled.RetryAfter
+}
+
+func TestLockDuration(t *testing.T) {
+	cases := []struct{ failures int; want time.Duration }{
+		{1, time.Second}, {2, 2 * time.Second}, {4, 8 * time.Second},
+		{5, 15 * time.Minute}, {6, 30 * time.Minute}, {8, 2 * time.Hour}, {20, 24 * time.Hour}, {200, 24 * time.Hour},
+	}
+	for _, c := range cases {
+		if got := lockDuration(c.failures, 5, 15*time.Minute); got != c.want { t.Errorf("lockDuration(%d) = %s, want %s", c.failures, got, c.want) }
+	}
+}
+
+func TestLoginThrottle_BackoffAndLockout(t *testing.T) {
+	throttle := newTestThrottle()
+	events := throttle.events.(*fakeSecurityEvents)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	throttle.now = func() time.Time { return now }
+
+	if err := throttle.Check("jane@example.com", "10.0.0.1"); err != nil { t.Fatalf("expected a fresh account to pass, got %v", err) }
+	throttle.Failure("jane@example.com", "10.0.0.1", 7)
+	if d := retryAfter(t, throttle.Check("Jane@Example.com ", "10.0.0.2")); d != time.Second { t.Fatalf("expected a one second backoff for any spelling of the email, got %s", d) }
+	now = now.Add(time.Second)
+	if err := throttle.Check("jane@example.com", "10.0.0.1"); err != nil { t.Fatalf("expected the backoff to end, got %v", err) }
+
+	throttle.Failure("jane@example.com", "10.0.0.1", 7)
+	throttle.Failure("jane@example.com", "10.0.0.1", 7)
+	if d := retryAfter(t, throttle.Check("jane@example.com", "10.0.0.3")); d != 15*time.Minute { t.Fatalf("expected a 15 minute lockout, got %s", d) }
+	if len(events.events) != 1 || events.events[0].UserID != 7 || events.events[0].Type != models.SecurityAccountLocked || events.events[0].IPAddress != "10.0.0.1" { t.Fatalf("expected one lockout event, got %+v", events.events) }
+
+	// an email without an account locks the same way but has nobody to record the event for
+	for i := 0; i < 3; i++ { throttle.Failure("nobody@example.com", "10.0.0.4", 0) }
+	if d := retryAfter(t, throttle.Check("nobody@example.com", "10.0.0.5")); d != 15*time.Minute { t.Fatalf("expected an unknown email to be locked alike, got %s", d) }
+	if len(events.events) != 1 { t.Fatalf("expected no event without an account, got %+v", events.events) }
+
+	// the address gets locked across emails
+	for i := 0; i < 5; i++ { throttle.Failure("user"+string(rune('a'+i))+"@example.com", "10.0.0.9", 0) }
+	if d := retryAfter(t, throttle.Check("other@example.com", "10.0.0.9")); d != 15*time.Minute { t.Fatalf("expected the address to be locked, got %s", d) }
+	if err := throttle.UnlockAddress("10.0.0.9"); err != nil { t.Fatalf("unlock address: %v", err) }
+	if err := throttle.Check("other@example.com", "10.0.0.9"); err != nil { t.Fatalf("expected the address to be cleared, got %v", err) }
+
+	locked, err := throttle.Unlock("jane@example.com", 7, UnlockAdmin)
+	if err != nil || !locked { t.Fatalf("unlock: %v %v", locked, err) }
+	if err := throttle.Check("jane@example.com", "10.0.0.3"); err != nil { t.Fatalf("expected the account to be unlocked, got %v", err) }
+	if last := events.events[len(events.events)-1]; last.Type != models.SecurityAccountUnlocked || last.Detail != UnlockAdmin { t.Fatalf("expected an unlock event, got %+v", last) }
+	if locked, _ := throttle.Unlock("jane@example.com", 7, UnlockAdmin); locked { t.Fatalf("expected a second unlock to find nothing locked") }
+}
+
+func TestLoginThrottle_FailuresExpire(t *testing.T) {
+	throttle := newTestThrottle()
+	now := time.Now()
+	throttle.now = func() time.Time { return now }
+
+	throttle.Failure("jane@example.com", "", 7)
+	throttle.Failure("jane@example.com", "", 7)
+	now = now.Add(loginFailureWindow + time.Minute)
+	throttle.Failure("jane@example.com", "", 7)
+	if d := retryAfter(t, throttle.Check("jane@example.com", "")); d != time.Second { t.Fatalf("expected old failures to be forgotten, got %s", d) }
+}
+
+func TestAuthService_Login_Lockout(t *testing.T) {
+	hashed, _ := utils.HashPassword("Pass1234")
+	user := &models.User{ID: 3, Email: "jane@example.com", Password: hashed}
+	users := &mockUserRepo{
+		GetByEmailFn: func(email string) (*models.User, error) {
+			if email != user.Email { return nil, gorm.ErrRecordNotFound }
+			return user, nil
+		},
+	}
+	throttle := newTestThrottle()
+	now := time.Now()
+	throttle.now = func() time.Time { return now }
+	svc := NewAuthService(users, newFakeSessionRepo(), &stubVerification{}, NewMFAService(newFakeMFARepo(), nil), throttle, testJWTConfig)
+
+	// an existing account and an unknown email can't be told apart, before or after the lockout
+	for n, email := range []string{"jane@example.com", "nobody@example.com"} {
+		for i := 0; i < 3; i++ {
+			if _, err := svc.Login(&models.UserLoginRequest{Email: email, Password: "wrong"}, fmt.Sprintf("10.0.%d.1", n)); !errors.Is(err, ErrInvalidCredentials) { t.Fatalf("%s attempt %d: expected ErrInvalidCredentials, got %v", email, i, err) }
+			now = now.Add(time.Minute)
+		}
+		_, err := svc.Login(&models.UserLoginRequest{Email: email, Password: "Pass1234"}, "10.0.0.2")
+		if d := retryAfter(t, err); d != 14*time.Minute { t.Fatalf("%s: expected the lockout to refuse even the right password, got %s", email, d) }
+	}
+
+	now = now.Add(15 * time.Minute)
+	if _, err := svc.Login(&models.UserLoginRequest{Email: user.Email, Password: "Pass1234"}, "10.0.0.2"); err != nil { t.Fatalf("expected a login after the lockout, got %v", err) }
+	if attempt, _ := throttle.store.Get(accountKey(user.Email)); attempt.Failures != 0 { t.Fatalf("expected a login to clear the failures, got %+v", attempt) }
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+)
+
+var ErrMailerNotConfigured = errors.New("mail delivery is not configured")
+
+// EmailMessage is a single email with a plain text and an HTML alternative. Headers are added as-is
+// after the standard ones.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+type Mailer interface {
+	Send(msg *EmailMessage) error
+}
+
+// SMTPMailer delivers mail through an SMTP relay. STARTTLS is used whenever the server offers it, and
+// credentials are only sent when a username is configured.
+type SMTPMailer struct {
+	config config.MailConfig
+}
+
+func NewSMTPMailer(cfg config.MailConfig) *SMTPMailer {
+	return &SMTPMailer{config: cfg}
+}
+
+func (m *SMTPMailer) Send(msg *EmailMessage) error {
+	if m.config.Host == "" {
+		return ErrMailerNotConfigured
+	}
+	from, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	body, err := buildMIMEMessage(from, to, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(m.config.Host, m.config.Port), auth, from.Address, []string{to.Address}, body)
+}
+
+// buildMIMEMessage renders msg as multipart/alternative with quoted-printable parts, text first so
+// clients that understand HTML prefer it.
+func buildMIMEMessage(from, to *mail.Address, msg *EmailMessage, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("UTF-8", msg.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + parts.Boundary()},
+	}
+	extra := make([]string, 0, len(msg.Headers))
+	for name := range msg.Headers {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		headers = append(headers, [2]string{name, msg.Headers[name]})
+	}
+
+	var out bytes.Buffer
+	for _, header := range headers {
+		if strings.ContainsAny(header[0]+header[1], "\r\n") {
+			return nil, fmt.Errorf("header %s contains a line break", header[0])
+		}
+		fmt.Fprintf(&out, "%s: %s\r\n", header[0], header[1])
+	}
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+)
+
+type sinkMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// startSMTPSink accepts mail on a local port, speaking just enough SMTP for net/smtp, and hands every
+// message it receives to the returned channel.
+func startSMTPSink(t *testing.T) (config.MailConfig, <-chan sinkMessage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil { t.Fatalf("listen: %v", err) }
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan sinkMessage, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil { return }
+			go serveSMTPSink(conn, messages)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return config.MailConfig{Host: host, Port: port, From: "Budget Tracker <reports@example.com>"}, messages
+}
+
+func serveSMTPSink(conn net.Conn, messages chan<- sinkMessage) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+	var msg sinkMessage
+	tp.PrintfLine("220 sink ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil { return }
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case verb == "EHLO" || verb == "HELO":
+			tp.PrintfLine("250 sink")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			msg = sinkMessage{From: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			tp.PrintfLine("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			msg.To = append(msg.To, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			tp.PrintfLine("250 OK")
+		case verb == "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil { return }
+			msg.Data = string(data)
+			messages <- msg
+			tp.PrintfLine("250 OK: queued")
+		case verb == "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+// parseSinkMessage splits a received multipart/alternative message into its headers and decoded parts
+func parseSinkMessage(t *testing.T, data string) (mail.Header, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil { t.Fatalf("read message: %v", err) }
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" { t.Fatalf("unexpected content type %q", msg.Header.Get("Content-Type")) }
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF { break }
+		if err != nil { t.Fatalf("next part: %v", err) }
+		body, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	return msg.Header, parts
+}
+
+func TestSMTPMailer_SendDeliversMultipartMessage(t *testing.T) {
+	cfg, messages := startSMTPSink(t)
+	mailer := NewSMTPMailer(cfg)
+
+	err := mailer.Send(&EmailMessage{
+		To:      "Jo <jo@example.com>",
+		Subject: "Résumé of your week",
+		Text:    "Net: 1,250.00\nA long line that is long enough to need a soft line break when it is quoted-printable encoded for transport.",
+		HTML:    "<p>Net: <strong>1,250.00</strong></p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe?token=abc>"},
+	})
+	if err != nil { t.Fatalf("send: %v", err) }
+
+	msg := <-messages
+	if msg.From != "reports@example.com" || len(msg.To) != 1 || msg.To[0] != "jo@example.com" { t.Fatalf("unexpected envelope %+v", msg) }
+	header, parts := parseSinkMessage(t, msg.Data)
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(header.Get("Subject")); subject != "Résumé of your week" { t.Fatalf("unexpected subject %q", subject) }
+	if header.Get("List-Unsubscribe") != "<https://example.com/unsubscribe?token=abc>" { t.Fatalf("expected the extra header to be kept") }
+	if !strings.Contains(parts["text/plain"], "need a soft line break when it is quoted-printable encoded") { t.Fatalf("unexpected text part %q", parts["text/plain"]) }
+	if parts["text/html"] != "<p>Net: <strong>1,250.00</strong></p>" { t.Fatalf("unexpected html part %q", parts["text/html"]) }
+}
+
+func TestSMTPMailer_RejectsHeaderInjection(t *testing.T) {
+	cfg, _ := startSMTPSink(t)
+	err := NewSMTPMailer(cfg).Send(&EmailMessage{To: "jo@example.com", Subject: "Hi", Headers: map[string]string{"X-Note": "a\r\nBcc: eve@example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "line break") { t.Fatalf("expected a line break error, got %v", err) }
+}
+
+func TestSMTPMailer_NotConfigured(t *testing.T) {
+	if err := NewSMTPMailer(config.MailConfig{}).Send(&EmailMessage{To: "jo@example.com"}); err != ErrMailerNotConfigured { t.Fatalf("expected ErrMailerNotConfigured, got %v", err) }
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+const (
+	mfaIssuer         = "Budget Tracker"
+	recoveryCodeCount = 10
+	qrCodeSize        = 256
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not set up")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type MFAService interface {
+	Enroll(userID uint) (*models.MFAEnrollment, error)
+	QRCode(userID uint) ([]byte, error)
+	Confirm(userID uint, code string) ([]string, error)
+	Disable(userID uint, req *models.MFADisableRequest) error
+	Enabled(userID uint) (bool, error)
+	Authenticate(userID uint, code, recoveryCode string) error
+}
+
+type mfaService struct {
+	mfaRepo  repository.MFARepository
+	userRepo repository.UserRepository
+}
+
+func NewMFAService(mfaRepo repository.MFARepository, userRepo repository.UserRepository) MFAService {
+	return &mfaService{
+		mfaRepo:  mfaRepo,
+		userRepo: userRepo,
+	}
+}
+
+// Enroll starts setting up an authenticator with a new secret. Until it is confirmed, enrolling again
+// replaces the secret.
+func (s *mfaService) Enroll(userID uint) (*models.MFAEnrollment, error) {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && factor.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		factor = &models.MFAFactor{UserID: userID}
+	}
+
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	factor.Secret, factor.LastUsedStep = secret, 0
+	if err := s.mfaRepo.SaveFactor(factor); err != nil {
+		return nil, err
+	}
+	return s.enrollment(factor)
+}
+
+func (s *mfaService) enrollment(factor *models.MFAFactor) (*models.MFAEnrollment, error) {
+	user, err := s.userRepo.GetByID(factor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MFAEnrollment{
+		Secret:     factor.Secret,
+		OTPAuthURI: utils.TOTPURI(mfaIssuer, user.Email, factor.Secret),
+		QRCodeURL:  "/api/mfa/qr.png",
+	}, nil
+}
+
+// QRCode renders the otpauth URI of a pending enrollment as a PNG. Once the authenticator is
+// confirmed, the secret is not shown again.
+func (s *mfaService) QRCode(userID uint) ([]byte, error) {
+	factor, err := s.pendingFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.enrollment(factor)
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(enrollment.OTPAuthURI, qrcode.Medium, qrCodeSize)
+}
+
+func (s *mfaService) pendingFactor(userID uint) (*models.MFAFactor, error) {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return factor, nil
+}
+
+// Confirm turns two-factor authentication on once a code from the authenticator checks out, and
+// returns recovery codes. They are only shown here.
+func (s *mfaService) Confirm(userID uint, code string) ([]string, error) {
+	factor, err := s.pendingFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	step, ok := utils.VerifyTOTP(factor.Secret, code, now, factor.LastUsedStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	stored := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))
+		codes[i] = raw[:4] + "-" + raw[4:]
+		stored[i] = models.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(codes[i])}
+	}
+	if err := s.mfaRepo.Confirm(factor, step, stored, now); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so a code can be typed the way it reads
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	return utils.HashToken(normalized)
+}
+
+// Disable turns two-factor authentication off after checking the password and a second factor
+func (s *mfaService) Disable(userID uint, req *models.MFADisableRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPassword(req.Password, user.Password) {
+		return ErrInvalidCredentials
+	}
+	if err := s.Authenticate(userID, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+	return s.mfaRepo.Delete(userID)
+}
+
+// Enabled reports whether the user has a confirmed authenticator
+func (s *mfaService) Enabled(userID uint) (bool, error) {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return factor.ConfirmedAt != nil, nil
+}
+
+// Authenticate checks a code from the authenticator or, failing that, spends a recovery code
+func (s *mfaService) Authenticate(userID uint, code, recoveryCode string) error {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMFANotEnrolled
+	}
+	if err != nil {
+		return err
+	}
+	if factor.ConfirmedAt == nil {
+		return ErrMFANotEnrolled
+	}
+
+	now := time.Now()
+	if code != "" {
+		step, ok := utils.VerifyTOTP(factor.Secret, code, now, factor.LastUsedStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		used, err := s.mfaRepo.UseStep(factor.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+	if recoveryCode != "" {
+		used, err := s.mfaRepo.UseRecoveryCode(userID, hashRecoveryCode(recoveryCode), now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+	return ErrInvalidMFACode
+}
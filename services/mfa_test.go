@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+// fakeMFARepo keeps factors and recovery codes in memory
+type fakeMFARepo struct {
+	factors map[uint]*models.MFAFactor
+	codes   []*models.RecoveryCode
+}
+
+func newFakeMFARepo() *fakeMFARepo { return &fakeMFARepo{factors: map[uint]*models.MFAFactor{}} }
+
+func (f *fakeMFARepo) GetFactor(userID uint) (*models.MFAFactor, error) {
+	factor, ok := f.factors[userID]
+	if !ok { return nil, gorm.ErrRecordNotFound }
+	copied := *factor
+	return &copied, nil
+}
+
+func (f *fakeMFARepo) SaveFactor(factor *models.MFAFactor) error {
+	if factor.ID == 0 { factor.ID = uint(len(f.factors) + 1) }
+	copied := *factor
+	f.factors[factor.UserID] = &copied
+	return nil
+}
+
+func (f *fakeMFARepo) Confirm(factor *models.MFAFactor, step int64, codes []models.RecoveryCode, now time.Time) error {
+	stored := f.factors[factor.UserID]
+	stored.ConfirmedAt, stored.LastUsedStep = &now, step
+	f.codes = nil
+	for i := range codes { f.codes = append(f.codes, &codes[i]) }
+	return nil
+}
+
+func (f *fakeMFARepo) UseStep(factorID uint, step int64) (bool, error) {
+	for _, factor := range f.factors {
+		if factor.ID == factorID && factor.LastUsedStep < step { factor.LastUsedStep = step; return true, nil }
+	}
+	return false, nil
+}
+
+func (f *fakeMFARepo) UseRecoveryCode(userID uint, codeHash string, now time.Time) (bool, error) {
+	for _, code := range f.codes {
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil { code.UsedAt = &now; return true, nil }
+	}
+	return false, nil
+}
+
+func (f *fakeMFARepo) Delete(userID uint) error {
+	delete(f.factors, userID)
+	f.codes = nil
+	return nil
+}
+
+var _ repository.MFARepository = (*fakeMFARepo)(nil)
+
+// enrolledUser sets up a user with a confirmed authenticator and returns the recovery codes
+func enrolledUser(t *testing.T) (AuthService, MFAService, *fakeMFARepo, *models.User, []string) {
+	t.Helper()
+	hashed, _ := utils.HashPassword("Pass1234")
+	user := &models.User{ID: 4, Email: "jane@example.com", Password: hashed}
+	users := &mockUserRepo{
+		GetByEmailFn: func(email string) (*models.User, error) { return user, nil },
+		GetByIDFn: func(id uint) (*models.User, error) { return user, nil },
+	}
+	mfaRepo := newFakeMFARepo()
+	mfa := NewMFAService(mfaRepo, users)
+	auth := NewAuthService(users, newFakeSessionRepo(), &stubVerification{}, mfa, &stubThrottle{}, testJWTConfig)
+
+	enrollment, err := mfa.Enroll(user.ID)
+	if err != nil { t.Fatalf("enroll: %v", err) }
+	if enrollment.OTPAuthURI != utils.TOTPURI("Budget Tracker", user.Email, enrollment.Secret) { t.Fatalf("unexpected uri %s", enrollment.OTPAuthURI) }
+	png, err := mfa.QRCode(user.ID)
+	if err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) { t.Fatalf("expected a PNG: %v", err) }
+
+	if _, err := mfa.Confirm(user.ID, "000000"); !errors.Is(err, ErrInvalidMFACode) { t.Fatalf("expected a wrong code to be refused, got %v", err) }
+	code, _ := utils.TOTPCode(enrollment.Secret, utils.TOTPStep(time.Now()))
+	recovery, err := mfa.Confirm(user.ID, code)
+	if err != nil || len(recovery) != recoveryCodeCount { t.Fatalf("confirm: %v codes=%v", err, recovery) }
+	if mfaRepo.codes[0].CodeHash == recovery[0] || len(mfaRepo.codes[0].CodeHash) != 64 { t.Fatalf("expected recovery codes to be stored hashed") }
+	return auth, mfa, mfaRepo, user, recovery
+}
+
+func TestMFAService_Enrollment(t *testing.T) {
+	_, mfa, _, user, _ := enrolledUser(t)
+	if _, err := mfa.Enroll(user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) { t.Fatalf("expected ErrMFAAlreadyEnabled, got %v", err) }
+	if _, err := mfa.QRCode(user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) { t.Fatalf("expected the secret to stay hidden once enabled, got %v", err) }
+	if _, err := mfa.QRCode(99); !errors.Is(err, ErrMFANotEnrolled) { t.Fatalf("expected ErrMFANotEnrolled, got %v", err) }
+	if enabled, _ := mfa.Enabled(user.ID); !enabled { t.Fatalf("expected two-factor authentication to be enabled") }
+}
+
+func TestAuthService_Login_MFAChallenge(t *testing.T) {
+	auth, _, mfaRepo, user, recovery := enrolledUser(t)
+
+	result, err := auth.Login(&models.UserLoginRequest{Email: user.Email, Password: "Pass1234"}, "10.0.0.1")
+	if err != nil { t.Fatalf("login: %v", err) }
+	if result.Tokens != nil || result.Challenge == nil || !result.Challenge.MFARequired || result.Challenge.ExpiresIn != 300 { t.Fatalf("expected a challenge instead of tokens, got %+v", result) }
+	challenge := result.Challenge.MFAToken
+	if claims, err := utils.ValidateToken(challenge); err == nil && claims.SessionID != 0 { t.Fatalf("expected the challenge not to work as an access token") }
+
+	// the code used to confirm the enrollment can't be replayed; the next step's code is accepted
+	secret := mfaRepo.factors[user.ID].Secret
+	used, _ := utils.TOTPCode(secret, mfaRepo.factors[user.ID].LastUsedStep)
+	if _, err := auth.VerifyMFA(&models.MFAVerifyRequest{MFAToken: challenge, Code: used}, "10.0.0.1"); !errors.Is(err, ErrInvalidMFACode) { t.Fatalf("expected a replayed code to be refused, got %v", err) }
+	next, _ := utils.TOTPCode(secret, mfaRepo.factors[user.ID].LastUsedStep+1)
+	result, err = auth.VerifyMFA(&models.MFAVerifyRequest{MFAToken: challenge, Code: next}, "10.0.0.1")
+	if err != nil || result.Tokens == nil || result.Tokens.Token == "" || result.User.ID != user.ID { t.Fatalf("verify: %v %+v", err, result) }
+
+	// recovery codes work once, in any case and with or without the dash
+	if _, err := auth.VerifyMFA(&models.MFAVerifyRequest{MFAToken: challenge, RecoveryCode: " " + recovery[1][:4] + recovery[1][5:] + " "}, "10.0.0.1"); err != nil { t.Fatalf("recovery code: %v", err) }
+	if _, err := auth.VerifyMFA(&models.MFAVerifyRequest{MFAToken: challenge, RecoveryCode: recovery[1]}, "10.0.0.1"); !errors.Is(err, ErrInvalidMFACode) { t.Fatalf("expected a used recovery code to be refused, got %v", err) }
+	if _, err := auth.VerifyMFA(&models.MFAVerifyRequest{MFAToken: challenge}, "10.0.0.1"); !errors.Is(err, ErrInvalidMFACode) { t.Fatalf("expected a missing code to be refused, got %v", err) }
+	if _, err := auth.VerifyMFA(&models.MFAVerifyRequest{MFAToken: "bogus", Code: next}, "10.0.0.1"); !errors.Is(err, ErrInvalidMFAChallenge) { t.Fatalf("expected ErrInvalidMFAChallenge, got %v", err) }
+}
+
+func TestMFAService_Disable(t *testing.T) {
+	auth, mfa, _, user, recovery := enrolledUser(t)
+	if err := mfa.Disable(user.ID, &models.MFADisableRequest{Password: "wrong", RecoveryCode: recovery[0]}); !errors.Is(err, ErrInvalidCredentials) { t.Fatalf("expected ErrInvalidCredentials, got %v", err) }
+	if err := mfa.Disable(user.ID, &models.MFADisableRequest{Password: "Pass1234", Code: "123456"}); !errors.Is(err, ErrInvalidMFACode) { t.Fatalf("expected ErrInvalidMFACode, got %v", err) }
+	if err := mfa.Disable(user.ID, &models.MFADisableRequest{Password: "Pass1234", RecoveryCode: recovery[0]}); err != nil { t.Fatalf("disable: %v", err) }
+
+	result, err := auth.Login(&models.UserLoginRequest{Email: user.Email, Password: "Pass1234"}, "10.0.0.1")
+	if err != nil || result.Tokens == nil { t.Fatalf("expected a plain login after disabling, got %v %+v", err, result) }
+}
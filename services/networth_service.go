@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+type NetWorthService interface {
+	CreateAsset(userID uint, req *models.CreateAssetRequest) (*models.Asset, error)
+	GetAssets(userID uint) ([]models.Asset, error)
+	UpdateAsset(id uint, userID uint, req *models.UpdateAssetRequest) (*models.Asset, error)
+	DeleteAsset(id uint, userID uint) error
+	AddValuation(assetID uint, userID uint, req *models.CreateValuationRequest) (*models.AssetValuation, error)
+	DeleteValuation(id uint, assetID uint, userID uint) error
+	GetNetWorth(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.NetWorthSeries, error)
+	ResetSnapshots(userID uint) error
+}
+
+type netWorthService struct {
+	netWorthRepo    repository.NetWorthRepository
+	transactionRepo repository.TransactionRepository
+}
+
+func NewNetWorthService(netWorthRepo repository.NetWorthRepository, transactionRepo repository.TransactionRepository) NetWorthService {
+	return &netWorthService{
+		netWorthRepo:    netWorthRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+func (s *netWorthService) CreateAsset(userID uint, req *models.CreateAssetRequest) (*models.Asset, error) {
+	asset := &models.Asset{
+		UserID:      userID,
+		Name:        req.Name,
+		Kind:        req.Kind,
+		Description: req.Description,
+	}
+	if err := s.netWorthRepo.CreateAsset(asset); err != nil {
+		return nil, err
+	}
+
+	if req.Value != nil {
+		date := time.Now().UTC()
+		if req.Date != nil {
+			date = *req.Date
+		}
+		valuation := &models.AssetValuation{AssetID: asset.ID, UserID: userID, Value: *req.Value, Date: date}
+		if err := s.netWorthRepo.CreateValuation(valuation); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.netWorthRepo.GetAssetByID(asset.ID, userID)
+}
+
+func (s *netWorthService) GetAssets(userID uint) ([]models.Asset, error) {
+	return s.netWorthRepo.GetAssetsByUserID(userID)
+}
+
+func (s *netWorthService) UpdateAsset(id uint, userID uint, req *models.UpdateAssetRequest) (*models.Asset, error) {
+	asset, err := s.netWorthRepo.GetAssetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		asset.Name = *req.Name
+	}
+	if req.Kind != nil {
+		asset.Kind = *req.Kind
+	}
+	if req.Description != nil {
+		asset.Description = *req.Description
+	}
+
+	if err := s.netWorthRepo.UpdateAsset(asset); err != nil {
+		return nil, err
+	}
+	return s.netWorthRepo.GetAssetByID(id, userID)
+}
+
+func (s *netWorthService) DeleteAsset(id uint, userID uint) error {
+	return s.netWorthRepo.DeleteAsset(id, userID)
+}
+
+func (s *netWorthService) AddValuation(assetID uint, userID uint, req *models.CreateValuationRequest) (*models.AssetValuation, error) {
+	if _, err := s.netWorthRepo.GetAssetByID(assetID, userID); err != nil {
+		return nil, err
+	}
+	if req.Value == nil {
+		return nil, errors.New("value is required")
+	}
+
+	valuation := &models.AssetValuation{
+		AssetID: assetID,
+		UserID:  userID,
+		Value:   *req.Value,
+		Date:    req.Date,
+		Note:    req.Note,
+	}
+	if err := s.netWorthRepo.CreateValuation(valuation); err != nil {
+		return nil, err
+	}
+	return valuation, nil
+}
+
+func (s *netWorthService) DeleteValuation(id uint, assetID uint, userID uint) error {
+	return s.netWorthRepo.DeleteValuation(id, assetID, userID)
+}
+
+func (s *netWorthService) ResetSnapshots(userID uint) error {
+	return s.netWorthRepo.DeleteSnapshots(userID)
+}
+
+// GetNetWorth returns the net worth at the end of every period in the range. Closed periods are read from
+// stored snapshots when available and snapshotted otherwise; the open period is always calculated live.
+// Periods are UTC, which the stored snapshots are keyed by.
+func (s *netWorthService) GetNetWorth(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.NetWorthSeries, error) {
+	now := time.Now().UTC()
+	interval, start, end, periods, err := resolveSeries(interval, startDate, endDate, now, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(periods))
+	for i, period := range periods {
+		keys[i] = period.Format("2006-01-02")
+	}
+	stored, err := s.netWorthRepo.GetSnapshots(userID, interval, keys)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make(map[string]models.NetWorthSnapshot, len(stored))
+	for _, snapshot := range stored {
+		snapshots[snapshot.Period] = snapshot
+	}
+
+	// Cash is the opening balance before the first bucket plus the running net of every bucket
+	cash, err := s.transactionRepo.GetBalanceBefore(userID, &models.TransactionFilter{StartDate: periods[0]})
+	if err != nil {
+		return nil, err
+	}
+	flows, err := s.transactionRepo.GetTimeSeries(userID, interval, periods[0], end, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	netByPeriod := make(map[string]float64, len(flows))
+	for _, flow := range flows {
+		netByPeriod[flow.Period] = flow.Income - flow.Expense
+	}
+
+	assets, err := s.netWorthRepo.GetAssetsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.NetWorthSeries{Interval: interval, StartDate: start, EndDate: end, Points: make([]models.NetWorthPoint, 0, len(periods))}
+	var fresh []models.NetWorthSnapshot
+	for i, period := range periods {
+		cash = roundCents(cash + netByPeriod[keys[i]])
+
+		if snapshot, ok := snapshots[keys[i]]; ok {
+			series.Points = append(series.Points, models.NetWorthPoint{
+				Period:      snapshot.Period,
+				Cash:        snapshot.Cash,
+				Assets:      snapshot.Assets,
+				Liabilities: snapshot.Liabilities,
+				NetWorth:    snapshot.NetWorth,
+				Snapshot:    true,
+			})
+			continue
+		}
+
+		periodEnd := nextBucket(period, interval)
+		closed := !periodEnd.After(now) && !periodEnd.After(end)
+		if periodEnd.After(end) {
+			periodEnd = end
+		}
+
+		assetTotal, liabilityTotal := valueAssetsAt(assets, periodEnd)
+		point := models.NetWorthPoint{
+			Period:      keys[i],
+			Cash:        cash,
+			Assets:      assetTotal,
+			Liabilities: liabilityTotal,
+			NetWorth:    roundCents(cash + assetTotal - liabilityTotal),
+		}
+		series.Points = append(series.Points, point)
+
+		if closed {
+			fresh = append(fresh, models.NetWorthSnapshot{
+				UserID:      userID,
+				Interval:    interval,
+				Period:      point.Period,
+				Cash:        point.Cash,
+				Assets:      point.Assets,
+				Liabilities: point.Liabilities,
+				NetWorth:    point.NetWorth,
+			})
+		}
+	}
+
+	if err := s.netWorthRepo.SaveSnapshots(fresh); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// valueAssetsAt sums the latest valuation before at for every asset, split into assets and liabilities.
+// Valuations must be sorted oldest first.
+func valueAssetsAt(assets []models.Asset, at time.Time) (float64, float64) {
+	var assetTotal, liabilityTotal float64
+	for _, asset := range assets {
+		var value float64
+		for _, valuation := range asset.Valuations {
+			if !valuation.Date.Before(at) {
+				break
+			}
+			value = valuation.Value
+		}
+		if asset.Kind == models.AssetKindLiability {
+			liabilityTotal += value
+		} else {
+			assetTotal += value
+		}
+	}
+	return roundCents(assetTotal), roundCents(liabilityTotal)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type mockNetWorthRepo struct {
+	assets    []models.Asset
+	snapshots []models.NetWorthSnapshot
+	saved     []models.NetWorthSnapshot
+}
+
+func (m *mockNetWorthRepo) CreateAsset(asset *models.Asset) error { asset.ID = 1; return nil }
+func (m *mockNetWorthRepo) GetAssetsByUserID(userID uint) ([]models.Asset, error) { return m.assets, nil }
+func (m *mockNetWorthRepo) GetAssetByID(id uint, userID uint) (*models.Asset, error) {
+	return &models.Asset{ID: id, UserID: userID}, nil
+}
+func (m *mockNetWorthRepo) UpdateAsset(asset *models.Asset) error { return nil }
+func (m *mockNetWorthRepo) DeleteAsset(id uint, userID uint) error { return nil }
+func (m *mockNetWorthRepo) CreateValuation(valuation *models.AssetValuation) error {
+	m.assets = append(m.assets, models.Asset{Valuations: []models.AssetValuation{*valuation}})
+	return nil
+}
+func (m *mockNetWorthRepo) DeleteValuation(id uint, assetID uint, userID uint) error { return nil }
+func (m *mockNetWorthRepo) GetSnapshots(userID uint, interval models.SummaryInterval, periods []string) ([]models.NetWorthSnapshot, error) {
+	return m.snapshots, nil
+}
+func (m *mockNetWorthRepo) SaveSnapshots(snapshots []models.NetWorthSnapshot) error {
+	m.saved = append(m.saved, snapshots...)
+	return nil
+}
+func (m *mockNetWorthRepo) DeleteSnapshots(userID uint) error { return nil }
+
+func TestNetWorthService_GetNetWorth(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	nwRepo := &mockNetWorthRepo{
+		assets: []models.Asset{
+			{Kind: models.AssetKindAsset, Valuations: []models.AssetValuation{{Value: 1000, Date: day(2025, 1, 15)}, {Value: 1200, Date: day(2025, 3, 1)}}},
+			{Kind: models.AssetKindLiability, Valuations: []models.AssetValuation{{Value: 500, Date: day(2025, 2, 10)}}},
+		},
+		// January was snapshotted before a back-dated edit; it must not change
+		snapshots: []models.NetWorthSnapshot{{Period: "2025-01-01", Interval: models.IntervalMonth, Cash: 1, NetWorth: 1}},
+	}
+	mTxn := &mockTxnRepo{
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) {
+			if !filter.StartDate.Equal(day(2025, 1, 1)) { t.Fatalf("unexpected opening date %v", filter.StartDate) }
+			return 200, nil
+		},
+		TimeSeriesFn: func(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+			return []models.SummaryBucket{{Period: "2025-01-01", Income: 100}, {Period: "2025-03-01", Expense: 50}}, nil
+		},
+	}
+	svc := NewNetWorthService(nwRepo, mTxn)
+
+	series, err := svc.GetNetWorth(1, models.IntervalMonth, "2025-01-01", "2025-03-31")
+	if err != nil { t.Fatalf("networth: %v", err) }
+	if len(series.Points) != 3 { t.Fatalf("expected 3 monthly points, got %+v", series.Points) }
+
+	jan, feb, mar := series.Points[0], series.Points[1], series.Points[2]
+	if !jan.Snapshot || jan.NetWorth != 1 { t.Fatalf("expected January from its snapshot, got %+v", jan) }
+	if feb.Snapshot || feb.Cash != 300 || feb.Assets != 1000 || feb.Liabilities != 500 || feb.NetWorth != 800 { t.Fatalf("unexpected February %+v", feb) }
+	if mar.Cash != 250 || mar.Assets != 1200 || mar.NetWorth != 950 { t.Fatalf("unexpected March %+v", mar) }
+	if len(nwRepo.saved) != 2 || nwRepo.saved[0].Period != "2025-02-01" { t.Fatalf("expected closed February and March to be snapshotted, got %+v", nwRepo.saved) }
+}
+
+func TestNetWorthService_OpenPeriodIsNotSnapshotted(t *testing.T) {
+	nwRepo := &mockNetWorthRepo{}
+	mTxn := &mockTxnRepo{
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) { return 0, nil },
+		TimeSeriesFn: func(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+			return nil, nil
+		},
+	}
+	svc := NewNetWorthService(nwRepo, mTxn)
+
+	series, err := svc.GetNetWorth(1, models.IntervalMonth, "", "")
+	if err != nil { t.Fatalf("networth: %v", err) }
+	if len(series.Points) != 12 || len(nwRepo.saved) != 11 { t.Fatalf("expected 12 points with only the 11 closed months snapshotted, got %d/%d", len(series.Points), len(nwRepo.saved)) }
+}
+
+func TestValueAssetsAt_UsesLatestValuationBeforeDate(t *testing.T) {
+	assets := []models.Asset{{Kind: models.AssetKindAsset, Valuations: []models.AssetValuation{
+		{Value: 10, Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Value: 20, Date: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}}}
+	if a, _ := valueAssetsAt(assets, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)); a != 10 { t.Fatalf("expected 10 before the second valuation, got %v", a) }
+	if a, _ := valueAssetsAt(assets, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)); a != 0 { t.Fatalf("expected 0 before any valuation, got %v", a) }
+}
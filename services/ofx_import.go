@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// ParseOFX reads STMTTRN records from an OFX 1.x (SGML) or 2.x (XML) statement. Both
+// flavours are handled by the same tag scanner: SGML leaf elements have no closing tags,
+// so values are read up to the next tag and closing tags are only used for aggregates.
+func ParseOFX(data io.Reader) ([]models.ImportRow, error) {
+	raw, err := io.ReadAll(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OFX: %w", err)
+	}
+
+	content := string(raw)
+	start := strings.Index(strings.ToUpper(content), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("file is not an OFX statement")
+	}
+	content = content[start:]
+
+	var rows []models.ImportRow
+	var account string
+	var current map[string]string
+	seq := 0
+
+	for len(content) > 0 {
+		open := strings.IndexByte(content, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(content[open:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(content[open+1 : open+end]))
+		content = content[open+end+1:]
+
+		next := strings.IndexByte(content, '<')
+		if next < 0 {
+			next = len(content)
+		}
+		value := strings.TrimSpace(html.UnescapeString(content[:next]))
+
+		switch {
+		case tag == "STMTTRN":
+			current = map[string]string{}
+		case tag == "/STMTTRN":
+			if current != nil {
+				seq++
+				rows = append(rows, ofxRow(seq, account, current))
+				current = nil
+			}
+		case strings.HasPrefix(tag, "/"), strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+			// closing tags of leaf elements and XML processing instructions carry no data
+		case tag == "ACCTID" && current == nil:
+			account = value
+		case current != nil && value != "":
+			// PAYEE aggregates carry their own NAME; only fill it when the plain NAME is absent
+			if _, exists := current[tag]; !exists {
+				current[tag] = value
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+func ofxRow(seq int, account string, fields map[string]string) models.ImportRow {
+	row := models.ImportRow{Row: seq}
+
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	} else {
+		row.Date = date
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(fields["TRNAMT"], ",", "."), 64)
+	switch {
+	case err != nil:
+		row.Errors = append(row.Errors, fmt.Sprintf("invalid amount %q", fields["TRNAMT"]))
+	case amount == 0:
+		row.Errors = append(row.Errors, "amount must not be zero")
+	default:
+		row.Amount = abs(amount)
+		row.Type = models.Income
+		if amount < 0 {
+			row.Type = models.Expense
+		}
+	}
+
+	name, memo := fields["NAME"], fields["MEMO"]
+	switch {
+	case name == "":
+		row.Description = memo
+	case memo == "" || strings.EqualFold(name, memo):
+		row.Description = name
+	default:
+		row.Description = name + " - " + memo
+	}
+
+	if fitid := fields["FITID"]; fitid != "" {
+		// FITIDs are only unique within an account
+		row.ExternalID = "ofx:" + account + ":" + fitid
+	} else {
+		row.Errors = append(row.Errors, "missing FITID")
+	}
+
+	return row
+}
+
+// parseOFXDate handles YYYYMMDD[HHMMSS[.XXX]][[gmt offset:tz name]]; only the calendar date is kept
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date.UTC(), nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil { t.Fatalf("open fixture: %v", err) }
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestParseOFX_SGML(t *testing.T) {
+	rows, err := ParseOFX(openFixture(t, "statement_v1.ofx"))
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if len(rows) != 3 { t.Fatalf("expected 3 transactions, got %d", len(rows)) }
+
+	if rows[0].Type != models.Expense || rows[0].Amount != 42.17 || rows[0].Description != "CORNER GROCERY - POS PURCHASE" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if !rows[0].Date.Equal(time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected date: %v", rows[0].Date) }
+	if rows[0].ExternalID != "ofx:000123456789:2025090201" { t.Fatalf("unexpected external id: %s", rows[0].ExternalID) }
+	if rows[1].Type != models.Income || rows[1].Amount != 2500 { t.Fatalf("unexpected second row: %+v", rows[1]) }
+	if rows[2].Description != "STREAMING & CO" { t.Fatalf("expected entities decoded and memo folded, got %q", rows[2].Description) }
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	rows, err := ParseOFX(openFixture(t, "statement_v2.qfx"))
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if len(rows) != 3 { t.Fatalf("expected 3 transactions, got %d", len(rows)) }
+
+	if rows[0].Type != models.Expense || rows[0].Amount != 64.20 || rows[0].Description != "CITY FUEL - Pump 4" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Type != models.Income || rows[1].ExternalID != "ofx:4111111111111111:CC-0002" { t.Fatalf("unexpected second row: %+v", rows[1]) }
+	for _, row := range rows {
+		if len(row.Errors) > 0 { t.Fatalf("unexpected row errors: %+v", row) }
+	}
+}
+
+func TestParseOFX_RowErrorsAndInvalidFile(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("date,amount\n")); err == nil {
+		t.Fatalf("expected error for non-OFX input")
+	}
+	rows, err := ParseOFX(strings.NewReader("<OFX><STMTTRN><DTPOSTED>bad<TRNAMT>0</STMTTRN></OFX>"))
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if len(rows) != 1 || len(rows[0].Errors) != 3 { t.Fatalf("expected date, amount and FITID errors, got %+v", rows) }
+}
+
+func TestImportService_PreviewOFX_DeduplicatesByFITID(t *testing.T) {
+	mTxn := &mockTxnRepo{
+		FindExternalIDsFn: func(userID uint, externalIDs []string) ([]string, error) {
+			return []string{"ofx:4111111111111111:CC-0001"}, nil
+		},
+		ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) {
+			t.Fatalf("rows with a FITID should not fall back to fuzzy matching")
+			return nil, nil
+		},
+	}
+	svc := NewImportService(&mockImportRepo{}, mTxn, importCategories())
+
+	if _, err := svc.PreviewOFX(5, openFixture(t, "statement_v2.qfx"), 0); err == nil {
+		t.Fatalf("expected error without a category")
+	}
+
+	preview, err := svc.PreviewOFX(5, openFixture(t, "statement_v2.qfx"), 2)
+	if err != nil { t.Fatalf("preview: %v", err) }
+	if preview.Duplicates != 2 || preview.Valid != 1 { t.Fatalf("expected known and repeated FITIDs flagged, got %+v", preview) }
+	if preview.Rows[1].Duplicate || !preview.Rows[2].Duplicate { t.Fatalf("expected only the repeated FITID flagged: %+v", preview.Rows) }
+}
+
+func TestImportService_CommitOFX_StoresFITID(t *testing.T) {
+	var committed []models.Transaction
+	mTxn := &mockTxnRepo{
+		FindExternalIDsFn: func(userID uint, externalIDs []string) ([]string, error) { return nil, nil },
+		CreateImportFn: func(batch *models.ImportBatch, transactions []models.Transaction) error {
+			committed = transactions
+			return nil
+		},
+	}
+	svc := NewImportService(&mockImportRepo{}, mTxn, importCategories())
+
+	batch, err := svc.CommitOFX(5, "statement_v1.ofx", openFixture(t, "statement_v1.ofx"), 1, false)
+	if err != nil { t.Fatalf("commit: %v", err) }
+	if batch.Source != models.ImportSourceOFX || batch.RowCount != 3 { t.Fatalf("unexpected batch: %+v", batch) }
+	if committed[1].ExternalID != "ofx:000123456789:2025091501" || committed[1].CategoryID != 1 {
+		t.Fatalf("unexpected committed transaction: %+v", committed[1])
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+const (
+	// passwordResetTTL is how long a reset token can be used
+	passwordResetTTL = time.Hour
+	// an account gets at most one reset email a minute and five a day
+	passwordResetInterval   = time.Minute
+	passwordResetDailyLimit = 5
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+//go:embed templates/password_reset.txt templates/password_reset.html
+var passwordResetTemplates embed.FS
+
+var (
+	passwordResetTextTemplate = template.Must(template.ParseFS(passwordResetTemplates, "templates/password_reset.txt"))
+	passwordResetHTMLTemplate = htmltemplate.Must(htmltemplate.ParseFS(passwordResetTemplates, "templates/password_reset.html"))
+)
+
+type PasswordService interface {
+	ForgotPassword(email string) error
+	ResetPassword(req *models.ResetPasswordRequest) error
+}
+
+type passwordService struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	tokenRepo   repository.OneTimeTokenRepository
+	apiKeyRepo  repository.APIKeyRepository
+	throttle    LoginThrottle
+	mailer      Mailer
+	// resetPageURL is the front-end page the email links to; without it the mail explains the API call
+	resetPageURL string
+	// deliver runs the account lookup and the mail; it is asynchronous outside tests
+	deliver func(func())
+}
+
+// NewPasswordService wires the password reset. mailer may be nil when mail is not configured, in
+// which case ForgotPassword fails with ErrMailerNotConfigured. resetPageURL may be empty when
+// there is no front-end reset page.
+func NewPasswordService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, tokenRepo repository.OneTimeTokenRepository, apiKeyRepo repository.APIKeyRepository, throttle LoginThrottle, mailer Mailer, resetPageURL string) PasswordService {
+	return &passwordService{
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		tokenRepo:    tokenRepo,
+		apiKeyRepo:   apiKeyRepo,
+		throttle:     throttle,
+		mailer:       mailer,
+		resetPageURL: resetPageURL,
+		deliver:      func(f func()) { go f() },
+	}
+}
+
+// ForgotPassword mails a reset token if an account with the email exists and hasn't had too many
+// lately. The outcome is the same either way, and the work happens in the background so the response
+// time doesn't tell them apart.
+func (s *passwordService) ForgotPassword(email string) error {
+	if s.mailer == nil {
+		return ErrMailerNotConfigured
+	}
+	s.deliver(func() {
+		if err := s.sendResetToken(email, time.Now()); err != nil {
+			log.Printf("Failed to send password reset email: %v", err)
+		}
+	})
+	return nil
+}
+
+func (s *passwordService) sendResetToken(email string, now time.Time) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	recent, err := s.tokenRepo.CountSince(user.ID, models.TokenPasswordReset, now.Add(-passwordResetInterval))
+	if err != nil {
+		return err
+	}
+	today, err := s.tokenRepo.CountSince(user.ID, models.TokenPasswordReset, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if recent > 0 || today >= passwordResetDailyLimit {
+		log.Printf("Password reset for user %d not sent: too many requests", user.ID)
+		return nil
+	}
+
+	token, hash, err := utils.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	err = s.tokenRepo.Create(&models.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenPasswordReset,
+		TokenHash: hash,
+		ExpiresAt: now.Add(passwordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := s.resetMessage(user, token)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(msg)
+}
+
+func (s *passwordService) resetMessage(user *models.User, token string) (*EmailMessage, error) {
+	data := struct {
+		Subject   string
+		Name      string
+		Token     string
+		ResetLink string
+		Minutes   int
+	}{
+		Subject: "Reset your Budget Tracker password",
+		Name:    user.FirstName,
+		Token:   token,
+		Minutes: int(passwordResetTTL / time.Minute),
+	}
+	if s.resetPageURL != "" {
+		separator := "?"
+		if strings.Contains(s.resetPageURL, "?") {
+			separator = "&"
+		}
+		data.ResetLink = s.resetPageURL + separator + "token=" + url.QueryEscape(token)
+	}
+	var text, html bytes.Buffer
+	if err := passwordResetTextTemplate.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := passwordResetHTMLTemplate.Execute(&html, data); err != nil {
+		return nil, err
+	}
+	return &EmailMessage{To: user.Email, Subject: data.Subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// ResetPassword sets the new password, signs the user out everywhere and lifts a login lockout, so a
+// user locked out by someone guessing their password can get back in
+func (s *passwordService) ResetPassword(req *models.ResetPasswordRequest) error {
+	now := time.Now()
+	token, err := s.tokenRepo.Consume(utils.HashToken(req.Token), models.TokenPasswordReset, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	if _, err := s.throttle.Unlock(user.Email, user.ID, UnlockPasswordReset); err != nil {
+		return err
+	}
+	// Whoever knew the old password may have minted keys with it
+	if err := s.apiKeyRepo.DeleteByUserID(user.ID); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAll(user.ID, models.SessionPasswordReset, now)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"github.com/aditherevenger/Budget-Tracker-API/utils"
+)
+
+// fakeTokenRepo keeps one-time tokens in memory
+type fakeTokenRepo struct {
+	tokens []*models.OneTimeToken
+}
+
+func (f *fakeTokenRepo) Create(token *models.OneTimeToken) error {
+	now := time.Now()
+	for _, existing := range f.tokens {
+		if existing.UserID == token.UserID && existing.Purpose == token.Purpose && existing.UsedAt == nil { existing.UsedAt = &now }
+	}
+	token.ID, token.CreatedAt = uint(len(f.tokens)+1), now
+	f.tokens = append(f.tokens, token)
+	return nil
+}
+
+func (f *fakeTokenRepo) Consume(tokenHash string, purpose string, now time.Time) (*models.OneTimeToken, error) {
+	for _, token := range f.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose && token.UsedAt == nil && now.Before(token.ExpiresAt) {
+			token.UsedAt = &now
+			return token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTokenRepo) CountSince(userID uint, purpose string, since time.Time) (int64, error) {
+	var count int64
+	for _, token := range f.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.CreatedAt.After(since) { count++ }
+	}
+	return count, nil
+}
+
+var _ repository.OneTimeTokenRepository = (*fakeTokenRepo)(nil)
+
+type recordingMailer struct{ sent []*EmailMessage }
+
+func (m *recordingMailer) Send(msg *EmailMessage) error { m.sent = append(m.sent, msg); return nil }
+
+// mailedToken finds the token in the text of a mail by matching it against the stored hash
+func mailedToken(t *testing.T, msg *EmailMessage, hash string) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Text) {
+		if utils.HashToken(field) == hash { return field }
+	}
+	t.Fatalf("no token in mail:\n%s", msg.Text)
+	return ""
+}
+
+func passwordFixture(t *testing.T) (*passwordService, *models.User, *fakeSessionRepo, *fakeTokenRepo, *recordingMailer) {
+	t.Helper()
+	hashed, _ := utils.HashPassword("OldPass1")
+	user := &models.User{ID: 5, Email: "jane@example.com", FirstName: "Jane", Password: hashed}
+	users := &mockUserRepo{
+		GetByEmailFn: func(email string) (*models.User, error) {
+			if email != user.Email { return nil, gorm.ErrRecordNotFound }
+			return user, nil
+		},
+		GetByIDFn: func(id uint) (*models.User, error) { return user, nil },
+		UpdateFn: func(updated *models.User) error { *user = *updated; return nil },
+	}
+	sessions, tokens, mailer, throttle := newFakeSessionRepo(), &fakeTokenRepo{}, &recordingMailer{}, newTestThrottle()
+	svc := NewPasswordService(users, sessions, tokens, &fakeAPIKeyRepo{}, throttle, mailer, "").(*passwordService)
+	svc.deliver = func(f func()) { f() }
+	return svc, user, sessions, tokens, mailer
+}
+
+func TestPasswordService_ResetFlow(t *testing.T) {
+	svc, user, sessions, tokens, mailer := passwordFixture(t)
+	sessions.Create(&models.Session{UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}, &models.RefreshToken{TokenHash: "r1"})
+	keys := svc.apiKeyRepo.(*fakeAPIKeyRepo)
+	apiKeys := NewAPIKeyService(keys)
+	key, err := apiKeys.CreateKey(user.ID, &models.CreateAPIKeyRequest{Name: "sync", Scopes: []string{models.ScopeReportsRead}})
+	if err != nil { t.Fatalf("create key: %v", err) }
+	other, _ := apiKeys.CreateKey(user.ID+1, &models.CreateAPIKeyRequest{Name: "other", Scopes: []string{models.ScopeReportsRead}})
+	if _, err := keys.Use(utils.HashToken(key.Key), time.Now()); err != nil { t.Fatalf("expected the key to work before the reset, got %v", err) }
+
+	if err := svc.ForgotPassword("jane@example.com"); err != nil { t.Fatalf("forgot: %v", err) }
+	if len(mailer.sent) != 1 || mailer.sent[0].To != user.Email { t.Fatalf("expected one mail to the user, got %+v", mailer.sent) }
+	if strings.Contains(mailer.sent[0].Text, "http") || strings.Contains(mailer.sent[0].HTML, "href") { t.Fatalf("expected no link without a reset page:\n%s", mailer.sent[0].Text) }
+	stored := tokens.tokens[0]
+	if stored.Purpose != models.TokenPasswordReset || stored.ExpiresAt.Sub(time.Now()) > passwordResetTTL { t.Fatalf("unexpected token %+v", stored) }
+	token := mailedToken(t, mailer.sent[0], stored.TokenHash)
+
+	if err := svc.ResetPassword(&models.ResetPasswordRequest{Token: token, NewPassword: "NewPass1"}); err != nil { t.Fatalf("reset: %v", err) }
+	if !utils.CheckPassword("NewPass1", user.Password) { t.Fatalf("expected the new password to be stored hashed") }
+	if active, _ := sessions.IsActive(1, user.ID, time.Now()); active { t.Fatalf("expected existing sessions to be revoked") }
+	if sessions.sessions[1].RevokedReason != models.SessionPasswordReset { t.Fatalf("unexpected revoke reason %q", sessions.sessions[1].RevokedReason) }
+	if _, err := keys.Use(utils.HashToken(key.Key), time.Now()); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected the API key to stop working after the reset, got %v", err) }
+	if _, err := keys.Use(utils.HashToken(other.Key), time.Now()); err != nil { t.Fatalf("expected another user's key to keep working, got %v", err) }
+
+	if err := svc.ResetPassword(&models.ResetPasswordRequest{Token: token, NewPassword: "Another1"}); !errors.Is(err, ErrInvalidResetToken) { t.Fatalf("expected a used token to be rejected, got %v", err) }
+}
+
+func TestPasswordService_ForgotPassword_UnknownEmail(t *testing.T) {
+	svc, _, _, tokens, mailer := passwordFixture(t)
+	if err := svc.ForgotPassword("nobody@example.com"); err != nil { t.Fatalf("expected no error for an unknown email, got %v", err) }
+	if len(mailer.sent) != 0 || len(tokens.tokens) != 0 { t.Fatalf("expected nothing to be sent or stored") }
+
+	svc.mailer = nil
+	if err := svc.ForgotPassword("jane@example.com"); !errors.Is(err, ErrMailerNotConfigured) { t.Fatalf("expected ErrMailerNotConfigured, got %v", err) }
+}
+
+func TestPasswordService_ForgotPassword_RateLimit(t *testing.T) {
+	svc, user, _, tokens, mailer := passwordFixture(t)
+	if err := svc.ForgotPassword(user.Email); err != nil { t.Fatalf("forgot: %v", err) }
+	// a second request within the minute answers the same but sends nothing
+	if err := svc.ForgotPassword(user.Email); err != nil { t.Fatalf("expected a limited request to look the same, got %v", err) }
+	if len(mailer.sent) != 1 || len(tokens.tokens) != 1 { t.Fatalf("expected one mail and token, got %d and %d", len(mailer.sent), len(tokens.tokens)) }
+
+	// a minute later requests work until five mails went out that day
+	for i := 1; i < passwordResetDailyLimit; i++ {
+		for _, token := range tokens.tokens { token.CreatedAt = token.CreatedAt.Add(-2 * time.Minute) }
+		svc.ForgotPassword(user.Email)
+	}
+	for _, token := range tokens.tokens { token.CreatedAt = token.CreatedAt.Add(-2 * time.Minute) }
+	if err := svc.ForgotPassword(user.Email); err != nil { t.Fatalf("expected a limited request to look the same, got %v", err) }
+	if len(mailer.sent) != passwordResetDailyLimit || len(tokens.tokens) != passwordResetDailyLimit { t.Fatalf("expected %d mails, got %d", passwordResetDailyLimit, len(mailer.sent)) }
+}
+
+func TestPasswordService_ResetPassword_OnlyLatestTokenWorks(t *testing.T) {
+	svc, user, _, tokens, mailer := passwordFixture(t)
+	svc.ForgotPassword(user.Email)
+	tokens.tokens[0].CreatedAt = tokens.tokens[0].CreatedAt.Add(-2 * time.Minute)
+	svc.ForgotPassword(user.Email)
+	first := mailedToken(t, mailer.sent[0], tokens.tokens[0].TokenHash)
+	second := mailedToken(t, mailer.sent[1], tokens.tokens[1].TokenHash)
+
+	if err := svc.ResetPassword(&models.ResetPasswordRequest{Token: first, NewPassword: "NewPass1"}); !errors.Is(err, ErrInvalidResetToken) { t.Fatalf("expected a superseded token to be rejected, got %v", err) }
+	tokens.tokens[1].ExpiresAt = time.Now().Add(-time.Minute)
+	if err := svc.ResetPassword(&models.ResetPasswordRequest{Token: second, NewPassword: "NewPass1"}); !errors.Is(err, ErrInvalidResetToken) { t.Fatalf("expected an expired token to be rejected, got %v", err) }
+	if !utils.CheckPassword("OldPass1", user.Password) { t.Fatalf("expected the password to stay unchanged") }
+}
+
+func TestPasswordService_ResetPassword_LiftsLockout(t *testing.T) {
+	svc, user, _, tokens, mailer := passwordFixture(t)
+	throttle := svc.throttle.(*loginThrottle)
+	for i := 0; i < 3; i++ { throttle.Failure(user.Email, "10.0.0.1", user.ID) }
+	retryAfter(t, throttle.Check(user.Email, ""))
+
+	svc.ForgotPassword(user.Email)
+	token := mailedToken(t, mailer.sent[0], tokens.tokens[0].TokenHash)
+	if err := svc.ResetPassword(&models.ResetPasswordRequest{Token: token, NewPassword: "NewPass1"}); err != nil { t.Fatalf("reset: %v", err) }
+	if err := throttle.Check(user.Email, ""); err != nil { t.Fatalf("expected the reset to unlock the account, got %v", err) }
+	events := throttle.events.(*fakeSecurityEvents).events
+	if last := events[len(events)-1]; last.Type != models.SecurityAccountUnlocked || last.Detail != UnlockPasswordReset { t.Fatalf("expected an unlock event, got %+v", events) }
+}
+
+func TestPasswordService_ResetMessage_LinksToResetPage(t *testing.T) {
+	svc, user, _, _, _ := passwordFixture(t)
+	svc.resetPageURL = "https://app.example.com/reset?lang=en"
+
+	msg, err := svc.resetMessage(user, "tok+en/1")
+	if err != nil { t.Fatalf("message: %v", err) }
+	link := "https://app.example.com/reset?lang=en&token=tok%2Ben%2F1"
+	if !strings.Contains(msg.Text, link) || !strings.Contains(msg.Text, "tok+en/1") { t.Fatalf("expected the page link and the token:\n%s", msg.Text) }
+	if !strings.Contains(msg.HTML, `href="https://app.example.com/reset?lang=en&amp;token=tok%2Ben%2F1"`) { t.Fatalf("expected the page link in the HTML:\n%s", msg.HTML) }
+	if strings.Contains(msg.Text, "/api/auth/password/reset") { t.Fatalf("the API endpoint must not be presented as a link:\n%s", msg.Text) }
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFDocument_WriteTo(t *testing.T) {
+	doc := newPDFDocument()
+	doc.text(50, 60, 12, true, "Hello (world) \\ café ✓")
+	doc.addPage()
+	doc.textRight(500, 60, 10, false, "Second")
+
+	var buf bytes.Buffer
+	if err := doc.writeTo(&buf); err != nil { t.Fatalf("write: %v", err) }
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) { t.Fatalf("missing header or trailer") }
+	if !bytes.Contains(out, []byte("/Count 2")) { t.Fatalf("expected two pages in the page tree") }
+
+	// every xref entry must point at the start of its object
+	startxref, _ := strconv.Atoi(string(regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)[1]))
+	if !bytes.HasPrefix(out[startxref:], []byte("xref\n")) { t.Fatalf("startxref does not point at the xref table") }
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	if len(entries) != 8 { t.Fatalf("expected 8 objects, got %d", len(entries)) }
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if !bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))) { t.Fatalf("xref entry %d points at %q", i+1, out[offset:offset+10]) }
+	}
+
+	content := pdfPageContent(t, out, 0)
+	if !strings.Contains(content, "(Hello \\(world\\) \\\\ caf\xe9 ?) Tj") { t.Fatalf("expected escaped WinAnsi text, got %q", content) }
+}
+
+func TestPDFTextWidthAndTruncate(t *testing.T) {
+	if w := pdfTextWidth("AA", 10, false); w != 13.34 { t.Fatalf("unexpected width %v", w) }
+	if pdfTextWidth("Total", 10, true) <= pdfTextWidth("Total", 10, false) { t.Fatalf("expected bold text to be wider") }
+	short := pdfTruncate("A very long transaction description", 60, 9, false)
+	if !strings.HasSuffix(short, "...") || pdfTextWidth(short, 9, false) > 60 { t.Fatalf("unexpected truncation %q", short) }
+	if pdfTruncate("Rent", 60, 9, false) != "Rent" { t.Fatalf("expected short text untouched") }
+}
+
+// pdfPageContent inflates the content stream of the given page
+func pdfPageContent(t *testing.T, pdf []byte, page int) string {
+	t.Helper()
+	streams := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(pdf, -1)
+	if page >= len(streams) { t.Fatalf("page %d not found", page) }
+	length, _ := strconv.Atoi(string(pdf[streams[page][2]:streams[page][3]]))
+	start := streams[page][1]
+	zr, err := zlib.NewReader(bytes.NewReader(pdf[start : start+length]))
+	if err != nil { t.Fatalf("inflate: %v", err) }
+	content, _ := io.ReadAll(zr)
+	return string(content)
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type qifSplit struct {
+	category string
+	memo     string
+	amount   string
+}
+
+type qifRecord struct {
+	date     string
+	amount   string
+	payee    string
+	memo     string
+	category string
+	splits   []qifSplit
+}
+
+// ParseQIF reads the !Type:Bank and !Type:CCard sections of a Quicken Interchange Format
+// file. Split transactions are expanded into one row per split line. Dates are read as
+// month/day/year unless dayFirst is set.
+func ParseQIF(data io.Reader, dayFirst bool) ([]models.ImportRow, error) {
+	scanner := bufio.NewScanner(data)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []models.ImportRow
+	var record qifRecord
+	sawSection := false
+	inSection := false
+	seq := 0
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if seq == 0 && !sawSection {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if line[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(line))
+			sawSection = true
+			inSection = header == "!type:bank" || header == "!type:ccard"
+			record = qifRecord{}
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+		switch code {
+		case 'D':
+			record.date = value
+		case 'T', 'U':
+			record.amount = value
+		case 'P':
+			record.payee = value
+		case 'M':
+			record.memo = value
+		case 'L':
+			record.category = value
+		case 'S':
+			record.splits = append(record.splits, qifSplit{category: value})
+		case 'E':
+			if n := len(record.splits); n > 0 {
+				record.splits[n-1].memo = value
+			}
+		case '$':
+			if n := len(record.splits); n > 0 {
+				record.splits[n-1].amount = value
+			}
+		case '^':
+			seq++
+			rows = append(rows, qifRows(seq, record, dayFirst)...)
+			record = qifRecord{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read QIF: %w", err)
+	}
+	if !sawSection {
+		return nil, errors.New("file is not a QIF export")
+	}
+
+	return rows, nil
+}
+
+func qifRows(seq int, record qifRecord, dayFirst bool) []models.ImportRow {
+	date, dateErr := parseQIFDate(record.date, dayFirst)
+	description := record.payee
+	if description == "" {
+		description = record.memo
+	}
+
+	build := func(amountRaw, category, memo string) models.ImportRow {
+		row := models.ImportRow{Row: seq, Description: description, CategoryName: qifCategory(category)}
+		if memo != "" && memo != description {
+			if row.Description == "" {
+				row.Description = memo
+			} else {
+				row.Description += " - " + memo
+			}
+		}
+		if dateErr != nil {
+			row.Errors = append(row.Errors, dateErr.Error())
+		} else {
+			row.Date = date
+		}
+
+		amount, err := parseAmount(amountRaw, false)
+		switch {
+		case err != nil:
+			row.Errors = append(row.Errors, err.Error())
+		case amount == 0:
+			row.Errors = append(row.Errors, "amount must not be zero")
+		default:
+			row.Amount = abs(amount)
+			row.Type = models.Income
+			if amount < 0 {
+				row.Type = models.Expense
+			}
+		}
+		return row
+	}
+
+	if len(record.splits) == 0 {
+		return []models.ImportRow{build(record.amount, record.category, record.memo)}
+	}
+
+	rows := make([]models.ImportRow, 0, len(record.splits))
+	for _, split := range record.splits {
+		rows = append(rows, build(split.amount, split.category, split.memo))
+	}
+	return rows
+}
+
+// qifCategory drops the class suffix (Category/Class) and the brackets Quicken puts around transfer accounts
+func qifCategory(value string) string {
+	if i := strings.IndexByte(value, '/'); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(strings.Trim(value, "[]"))
+}
+
+// parseQIFDate accepts the variants Quicken has used over the years: 9/2/2025, 09/02/25, 9/ 2'25 and 2025-09-02
+func parseQIFDate(value string, dayFirst bool) (time.Time, error) {
+	raw := value
+	value = strings.ReplaceAll(value, " ", "")
+	apostrophe := strings.Contains(value, "'")
+	value = strings.NewReplacer("'", "/", "-", "/", ".", "/").Replace(value)
+
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", raw)
+		}
+		nums[i] = n
+	}
+
+	var year, month, day int
+	switch {
+	case len(parts[0]) == 4:
+		year, month, day = nums[0], nums[1], nums[2]
+	case dayFirst:
+		day, month, year = nums[0], nums[1], nums[2]
+	default:
+		month, day, year = nums[0], nums[1], nums[2]
+	}
+	if len(parts[2]) <= 2 && len(parts[0]) != 4 {
+		if apostrophe || year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+	return date, nil
+}
+
+// WriteQIF writes transactions as a single !Type:Bank section; expenses are negative amounts
+func WriteQIF(w io.Writer, transactions []models.Transaction) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("!Type:Bank\n"); err != nil {
+		return err
+	}
+
+	for _, transaction := range transactions {
+		amount := transaction.Amount
+		if transaction.Type == models.Expense {
+			amount = -amount
+		}
+		fmt.Fprintf(bw, "D%s\n", transaction.Date.UTC().Format("01/02/2006"))
+		fmt.Fprintf(bw, "T%s\n", strconv.FormatFloat(amount, 'f', 2, 64))
+		if transaction.Description != "" {
+			fmt.Fprintf(bw, "P%s\n", qifValue(transaction.Description))
+		}
+		if transaction.Category.Name != "" {
+			fmt.Fprintf(bw, "L%s\n", qifValue(transaction.Category.Name))
+		}
+		if _, err := bw.WriteString("^\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// qifValue keeps values on a single line, since QIF is strictly line-oriented
+func qifValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestParseQIF_BankCCardAndSplits(t *testing.T) {
+	rows, err := ParseQIF(openFixture(t, "export.qif"), false)
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if len(rows) != 5 { t.Fatalf("expected 5 rows (2 plain, 2 splits, 1 card), got %d: %+v", len(rows), rows) }
+
+	if rows[0].Type != models.Expense || rows[0].Amount != 1042.17 || rows[0].CategoryName != "Food:Groceries" || rows[0].Description != "Corner Grocery - Weekly shop" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if !rows[0].Date.Equal(time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected date: %v", rows[0].Date) }
+	if rows[1].Type != models.Income || rows[1].CategoryName != "Salary" { t.Fatalf("unexpected income row: %+v", rows[1]) }
+
+	if rows[2].Amount != 30 || rows[2].CategoryName != "Household" || rows[2].Description != "Hardware Store - Light bulbs" || rows[2].Row != 3 {
+		t.Fatalf("unexpected first split: %+v", rows[2])
+	}
+	if rows[3].Amount != 50 || rows[3].CategoryName != "Food" || rows[3].Row != 3 { t.Fatalf("unexpected second split: %+v", rows[3]) }
+	if rows[4].CategoryName != "Savings" || rows[4].Type != models.Expense { t.Fatalf("unexpected card row: %+v", rows[4]) }
+}
+
+func TestParseQIF_DayFirstAndErrors(t *testing.T) {
+	data := "!Type:Bank\nD02/09/2025\nT-5\n^\nD31/02/2025\nT-5\n^\nD02/09/2025\nT0\n^\n"
+	rows, err := ParseQIF(strings.NewReader(data), true)
+	if err != nil { t.Fatalf("parse: %v", err) }
+	if !rows[0].Date.Equal(time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)) { t.Fatalf("expected day-first date, got %v", rows[0].Date) }
+	if len(rows[1].Errors) != 1 || len(rows[2].Errors) != 1 { t.Fatalf("expected invalid date and zero amount errors: %+v", rows) }
+
+	if _, err := ParseQIF(strings.NewReader("date,amount\n"), false); err == nil {
+		t.Fatalf("expected error for non-QIF input")
+	}
+}
+
+func TestWriteQIF_RoundTrip(t *testing.T) {
+	transactions := []models.Transaction{
+		{Amount: 12.5, Type: models.Expense, Description: "Lunch\nwith team", Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Category: models.Category{Name: "Food"}},
+		{Amount: 1000, Type: models.Income, Description: "Pay", Date: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	var buf strings.Builder
+	if err := WriteQIF(&buf, transactions); err != nil { t.Fatalf("write: %v", err) }
+	want := "!Type:Bank\nD09/01/2025\nT-12.50\nPLunch with team\nLFood\n^\nD09/02/2025\nT1000.00\nPPay\n^\n"
+	if buf.String() != want { t.Fatalf("unexpected QIF:\n%s", buf.String()) }
+
+	rows, err := ParseQIF(strings.NewReader(buf.String()), false)
+	if err != nil { t.Fatalf("re-parse: %v", err) }
+	if len(rows) != 2 || rows[0].Amount != 12.5 || rows[0].Type != models.Expense || rows[1].Type != models.Income {
+		t.Fatalf("round trip mismatch: %+v", rows)
+	}
+}
+
+func TestImportService_PreviewQIF_MatchesParentCategory(t *testing.T) {
+	mTxn := &mockTxnRepo{ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) { return nil, nil }}
+	svc := NewImportService(&mockImportRepo{}, mTxn, importCategories())
+
+	preview, err := svc.PreviewQIF(5, openFixture(t, "export.qif"), 2, false)
+	if err != nil { t.Fatalf("preview: %v", err) }
+	if preview.Rows[0].CategoryID != 1 { t.Fatalf("expected Food:Groceries to match Food, got %+v", preview.Rows[0]) }
+	if preview.Rows[1].CategoryID != 2 { t.Fatalf("expected unknown Salary to use the fallback category, got %+v", preview.Rows[1]) }
+	if preview.Valid != 5 { t.Fatalf("expected all rows valid, got %+v", preview) }
+}
+
+func TestTransactionService_ExportQIF_Chronological(t *testing.T) {
+	mTxn := &mockTxnRepo{ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) {
+		return []models.Transaction{
+			{Amount: 2, Type: models.Expense, Date: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)},
+			{Amount: 1, Type: models.Expense, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)},
+		}, nil
+	}}
+	svc := NewTransactionService(mTxn, &mockCatRepo{}, userRepoIn("UTC"))
+	var buf strings.Builder
+	if err := svc.ExportQIF(7, &models.TransactionFilter{}, &buf); err != nil { t.Fatalf("export: %v", err) }
+	if strings.Index(buf.String(), "D09/01/2025") > strings.Index(buf.String(), "D09/02/2025") {
+		t.Fatalf("expected oldest transaction first:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+const (
+	// maxReportAttempts is how often a failed summary is retried before the period is given up
+	maxReportAttempts   = 3
+	reportDeliveryLimit = 50
+	reportTopCategories = 5
+	reportUnusualItems  = 5
+)
+
+//go:embed templates/report_email.txt templates/report_email.html
+var reportEmailTemplates embed.FS
+
+var (
+	reportTextTemplate = template.Must(template.ParseFS(reportEmailTemplates, "templates/report_email.txt"))
+	reportHTMLTemplate = htmltemplate.Must(htmltemplate.ParseFS(reportEmailTemplates, "templates/report_email.html"))
+)
+
+type ReportEmailService interface {
+	Subscribe(userID uint, req *models.SubscribeReportRequest) (*models.ReportSubscription, error)
+	GetSubscriptions(userID uint) ([]models.ReportSubscription, error)
+	DeleteSubscription(id uint, userID uint) error
+	Unsubscribe(token string) error
+	GetDeliveries(userID uint) ([]models.ReportDelivery, error)
+	RunDue(now time.Time) (*models.ReportRunResult, error)
+}
+
+type reportEmailService struct {
+	emailRepo       repository.ReportEmailRepository
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	reportService   ReportService
+	mailer          Mailer
+	publicURL       string
+}
+
+// NewReportEmailService wires the summary emails. mailer may be nil when mail is not configured;
+// subscriptions can still be managed but RunDue fails with ErrMailerNotConfigured.
+func NewReportEmailService(emailRepo repository.ReportEmailRepository, userRepo repository.UserRepository, transactionRepo repository.TransactionRepository, reportService ReportService, mailer Mailer, publicURL string) ReportEmailService {
+	return &reportEmailService{
+		emailRepo:       emailRepo,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		reportService:   reportService,
+		mailer:          mailer,
+		publicURL:       strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// Subscribe creates the subscription or reactivates an existing one. The first email covers the
+// period that is currently running in the user's time zone.
+func (s *reportEmailService) Subscribe(userID uint, req *models.SubscribeReportRequest) (*models.ReportSubscription, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	next := req.Frequency.Next(req.Frequency.PeriodStart(time.Now(), loc), loc).UTC()
+
+	subscription, err := s.emailRepo.GetSubscription(userID, req.Frequency)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		if !subscription.Active {
+			subscription.Active = true
+			subscription.NextRunAt = next
+			if err := s.emailRepo.UpdateSubscription(subscription); err != nil {
+				return nil, err
+			}
+		}
+		return subscription, nil
+	}
+
+	subscription = &models.ReportSubscription{
+		UserID:    userID,
+		Frequency: req.Frequency,
+		Active:    true,
+		NextRunAt: next,
+	}
+	if err := s.emailRepo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *reportEmailService) GetSubscriptions(userID uint) ([]models.ReportSubscription, error) {
+	return s.emailRepo.GetSubscriptionsByUserID(userID)
+}
+
+func (s *reportEmailService) DeleteSubscription(id uint, userID uint) error {
+	return s.emailRepo.DeleteSubscription(id, userID)
+}
+
+// Unsubscribe deactivates the subscription the token was issued for. The subscription is kept so the
+// link keeps working when clicked twice.
+func (s *reportEmailService) Unsubscribe(token string) error {
+	subscription, err := s.emailRepo.GetSubscriptionByToken(token)
+	if err != nil {
+		return err
+	}
+	if !subscription.Active {
+		return nil
+	}
+	subscription.Active = false
+	return s.emailRepo.UpdateSubscription(subscription)
+}
+
+func (s *reportEmailService) GetDeliveries(userID uint) ([]models.ReportDelivery, error) {
+	return s.emailRepo.GetDeliveriesByUserID(userID, reportDeliveryLimit)
+}
+
+// RunDue sends the summary for the last completed period of every due subscription, with weeks and
+// months taken in the user's time zone. Periods missed while the job was not running are skipped
+// rather than sent late. A failed delivery leaves the
+// subscription due so the next run retries it, up to maxReportAttempts.
+func (s *reportEmailService) RunDue(now time.Time) (*models.ReportRunResult, error) {
+	if s.mailer == nil {
+		return nil, ErrMailerNotConfigured
+	}
+	subscriptions, err := s.emailRepo.GetDueSubscriptions(now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ReportRunResult{Due: len(subscriptions)}
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		user, err := s.userRepo.GetByID(subscription.UserID)
+		if err != nil {
+			return result, err
+		}
+		loc := userTimezone(user)
+		end := subscription.Frequency.PeriodStart(now, loc)
+		start := subscription.Frequency.PeriodStart(end.Add(-time.Nanosecond), loc)
+		next := subscription.Frequency.Next(end, loc).UTC()
+
+		delivery := &models.ReportDelivery{
+			SubscriptionID: subscription.ID,
+			UserID:         subscription.UserID,
+			Frequency:      subscription.Frequency,
+			PeriodStart:    start.UTC(),
+			PeriodEnd:      end.UTC(),
+			Status:         models.DeliveryPending,
+		}
+		claimed, err := s.emailRepo.ClaimDelivery(delivery, maxReportAttempts)
+		if err != nil {
+			return result, err
+		}
+		if !claimed {
+			result.Skipped++
+			if delivery.Status == models.DeliverySent || delivery.Attempts >= maxReportAttempts {
+				if err := s.emailRepo.ScheduleNextRun(subscription.ID, next, nil); err != nil {
+					return result, err
+				}
+			}
+			continue
+		}
+
+		msg, err := s.buildReportEmail(subscription, user, start, end)
+		if err == nil {
+			err = s.mailer.Send(msg)
+		}
+		delivery.Attempts++
+		if err != nil {
+			result.Failed++
+			delivery.Status = models.DeliveryFailed
+			delivery.Error = err.Error()
+		} else {
+			result.Sent++
+			sentAt := now
+			delivery.Status = models.DeliverySent
+			delivery.Error = ""
+			delivery.SentAt = &sentAt
+		}
+		if err := s.emailRepo.UpdateDelivery(delivery); err != nil {
+			return result, err
+		}
+		if delivery.Status == models.DeliverySent || delivery.Attempts >= maxReportAttempts {
+			if err := s.emailRepo.ScheduleNextRun(subscription.ID, next, delivery.SentAt); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+type reportEmailData struct {
+	Subject        string
+	Name           string
+	Frequency      models.ReportFrequency
+	PeriodLabel    string
+	Income         string
+	Expense        string
+	Net            string
+	TopCategories  []reportEmailCategory
+	BudgetMonth    string
+	Budgets        []reportEmailBudget
+	Unusual        []reportEmailItem
+	UnsubscribeURL string
+}
+
+type reportEmailCategory struct {
+	Name  string
+	Total string
+	Share string
+}
+
+type reportEmailBudget struct {
+	Name    string
+	Planned string
+	Actual  string
+	Over    bool
+}
+
+type reportEmailItem struct {
+	Date        string
+	Description string
+	Category    string
+	Amount      string
+	Typical     string
+}
+
+// buildReportEmail renders the summary for [start, end), both in the user's time zone. Budget status is
+// the month the period ends in, so weekly emails show month to date.
+func (s *reportEmailService) buildReportEmail(subscription *models.ReportSubscription, user *models.User, start, end time.Time) (*EmailMessage, error) {
+	summary, err := s.transactionRepo.GetSummary(user.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	income, expense := summary.TotalIncome, summary.TotalExpense
+
+	data := &reportEmailData{
+		Name:           user.FirstName,
+		Frequency:      subscription.Frequency,
+		PeriodLabel:    reportPeriodLabel(subscription.Frequency, start, end),
+		Income:         formatStatementAmount(income),
+		Expense:        formatStatementAmount(expense),
+		Net:            formatStatementAmount(income - expense),
+		UnsubscribeURL: s.publicURL + "/api/email/unsubscribe?token=" + url.QueryEscape(subscription.UnsubscribeToken),
+	}
+	data.Subject = "Your " + string(subscription.Frequency) + " summary: " + data.PeriodLabel
+
+	totals, err := s.transactionRepo.GetSummaryByCategory(user.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].Total > totals[j].Total })
+	for _, total := range totals {
+		if total.Type != models.Expense || len(data.TopCategories) == reportTopCategories {
+			continue
+		}
+		category := reportEmailCategory{Name: total.CategoryName, Total: formatStatementAmount(total.Total), Share: "0.00"}
+		if expense != 0 {
+			category.Share = formatStatementAmount(total.Total / expense * 100)
+		}
+		data.TopCategories = append(data.TopCategories, category)
+	}
+
+	lastDay := end.Add(-time.Nanosecond)
+	variance, err := s.reportService.GetBudgetVariance(user.ID, lastDay.Format("2006-01"))
+	if err != nil {
+		return nil, err
+	}
+	data.BudgetMonth = lastDay.Format("January 2006")
+	for _, row := range variance.Categories {
+		if !row.Budgeted || row.Type != models.Expense {
+			continue
+		}
+		data.Budgets = append(data.Budgets, reportEmailBudget{
+			Name:    row.CategoryName,
+			Planned: formatStatementAmount(row.Month.Planned),
+			Actual:  formatStatementAmount(row.Month.Actual),
+			Over:    row.Month.Over,
+		})
+	}
+
+	unusual, err := s.unusualExpenses(user.ID, start, end, userTimezone(user))
+	if err != nil {
+		return nil, err
+	}
+	data.Unusual = unusual
+
+	var text, html bytes.Buffer
+	if err := reportTextTemplate.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := reportHTMLTemplate.Execute(&html, data); err != nil {
+		return nil, err
+	}
+	return &EmailMessage{
+		To:      user.Email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + data.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+// unusualExpenses returns the period's most unusual expenses, as flagged by the anomaly detector
+func (s *reportEmailService) unusualExpenses(userID uint, start, end time.Time, loc *time.Location) ([]reportEmailItem, error) {
+	var flagged []models.Transaction
+	_, err := detectAnomalies(s.transactionRepo, userID, start, end, loc, func(transaction models.Transaction, anomaly *models.TransactionAnomaly) {
+		if anomaly != nil {
+			transaction.Anomaly = anomaly
+			flagged = append(flagged, transaction)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(flagged, func(i, j int) bool { return flagged[i].Anomaly.Score > flagged[j].Anomaly.Score })
+	if len(flagged) > reportUnusualItems {
+		flagged = flagged[:reportUnusualItems]
+	}
+
+	items := make([]reportEmailItem, 0, len(flagged))
+	for _, transaction := range flagged {
+		items = append(items, reportEmailItem{
+			Date:        transaction.Date.In(loc).Format("Jan 2"),
+			Description: transaction.Description,
+			Category:    transaction.Category.Name,
+			Amount:      formatStatementAmount(transaction.Amount),
+			Typical:     formatStatementAmount(transaction.Anomaly.Typical),
+		})
+	}
+	return items, nil
+}
+
+func reportPeriodLabel(frequency models.ReportFrequency, start, end time.Time) string {
+	if frequency == models.ReportMonthly {
+		return start.Format("January 2006")
+	}
+	last := end.AddDate(0, 0, -1)
+	if start.Year() != last.Year() {
+		return start.Format("Jan 2, 2006") + " - " + last.Format("Jan 2, 2006")
+	}
+	return start.Format("Jan 2") + " - " + last.Format("Jan 2, 2006")
+}
+
+// StartReportScheduler runs RunDue immediately and then every interval until ctx is cancelled
+func StartReportScheduler(ctx context.Context, service ReportEmailService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			result, err := service.RunDue(time.Now())
+			if err != nil {
+				log.Printf("Summary emails: %v", err)
+			} else if result.Due > 0 {
+				log.Printf("Summary emails: %d due, %d sent, %d failed, %d skipped", result.Due, result.Sent, result.Failed, result.Skipped)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
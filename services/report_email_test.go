@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+type mockReportEmailRepo struct {
+	subscriptions []models.ReportSubscription
+	deliveries    []models.ReportDelivery
+}
+
+func (m *mockReportEmailRepo) CreateSubscription(subscription *models.ReportSubscription) error {
+	subscription.ID = uint(len(m.subscriptions) + 1)
+	subscription.UnsubscribeToken = "token"
+	m.subscriptions = append(m.subscriptions, *subscription)
+	return nil
+}
+func (m *mockReportEmailRepo) GetSubscriptionsByUserID(userID uint) ([]models.ReportSubscription, error) { return m.subscriptions, nil }
+func (m *mockReportEmailRepo) GetSubscription(userID uint, frequency models.ReportFrequency) (*models.ReportSubscription, error) {
+	for i := range m.subscriptions {
+		if m.subscriptions[i].UserID == userID && m.subscriptions[i].Frequency == frequency { s := m.subscriptions[i]; return &s, nil }
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockReportEmailRepo) GetSubscriptionByID(id uint, userID uint) (*models.ReportSubscription, error) { return nil, gorm.ErrRecordNotFound }
+func (m *mockReportEmailRepo) GetSubscriptionByToken(token string) (*models.ReportSubscription, error) {
+	for i := range m.subscriptions {
+		if m.subscriptions[i].UnsubscribeToken == token { s := m.subscriptions[i]; return &s, nil }
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockReportEmailRepo) GetDueSubscriptions(now time.Time) ([]models.ReportSubscription, error) {
+	var due []models.ReportSubscription
+	for _, s := range m.subscriptions {
+		if s.Active && !s.NextRunAt.After(now) { due = append(due, s) }
+	}
+	return due, nil
+}
+func (m *mockReportEmailRepo) UpdateSubscription(subscription *models.ReportSubscription) error {
+	m.subscriptions[subscription.ID-1] = *subscription
+	return nil
+}
+func (m *mockReportEmailRepo) ScheduleNextRun(id uint, nextRunAt time.Time, sentAt *time.Time) error {
+	m.subscriptions[id-1].NextRunAt = nextRunAt
+	if sentAt != nil { m.subscriptions[id-1].LastSentAt = sentAt }
+	return nil
+}
+func (m *mockReportEmailRepo) DeleteSubscription(id uint, userID uint) error { return nil }
+func (m *mockReportEmailRepo) ClaimDelivery(delivery *models.ReportDelivery, maxAttempts int) (bool, error) {
+	for i := range m.deliveries {
+		existing := &m.deliveries[i]
+		if existing.SubscriptionID != delivery.SubscriptionID || !existing.PeriodStart.Equal(delivery.PeriodStart) { continue }
+		*delivery = *existing
+		if existing.Status != models.DeliveryFailed || existing.Attempts >= maxAttempts { return false, nil }
+		existing.Status = models.DeliveryPending
+		delivery.Status = models.DeliveryPending
+		return true, nil
+	}
+	delivery.ID = uint(len(m.deliveries) + 1)
+	m.deliveries = append(m.deliveries, *delivery)
+	return true, nil
+}
+func (m *mockReportEmailRepo) UpdateDelivery(delivery *models.ReportDelivery) error {
+	m.deliveries[delivery.ID-1] = *delivery
+	return nil
+}
+func (m *mockReportEmailRepo) GetDeliveriesByUserID(userID uint, limit int) ([]models.ReportDelivery, error) { return m.deliveries, nil }
+
+type failingMailer struct{ calls int }
+
+func (m *failingMailer) Send(msg *EmailMessage) error { m.calls++; return errors.New("connection refused") }
+
+// reportEmailFixture is a week of October 2026 with a 400 food budget and one unusually large food purchase
+func reportEmailFixture(t *testing.T) (*mockTxnRepo, *mockBudgetRepo, *mockUserRepo) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 12, 0, 0, 0, time.UTC) }
+	food := models.Category{ID: 1, Name: "Food"}
+	mTxn := &mockTxnRepo{
+		SummaryFn: func(userID uint, start, end time.Time) (*models.Summary, error) {
+			if !start.Equal(time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected summary bounds %v %v", start, end) }
+			return &models.Summary{TotalIncome: 2000, TotalExpense: 640}, nil
+		},
+		SummaryByCategoryFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+			if start.Equal(time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)) {
+				return []models.CategoryTotal{
+					{CategoryID: 1, CategoryName: "Food", Type: models.Expense, Total: 140},
+					{CategoryID: 2, CategoryName: "Rent", Type: models.Expense, Total: 500},
+					{CategoryID: 3, CategoryName: "Salary", Type: models.Income, Total: 2000},
+				}, nil
+			}
+			return []models.CategoryTotal{{CategoryID: 1, CategoryName: "Food", Type: models.Expense, Total: 450}}, nil
+		},
+		StreamFn: func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+			if filter.Type != models.Expense || !filter.StartDate.Equal(time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected unusual item filter %+v", filter) }
+			return fn([]models.Transaction{
+				{CategoryID: 1, Amount: 40, Date: day(9, 1), Category: food},
+				{CategoryID: 1, Amount: 45, Date: day(9, 10), Category: food},
+				{CategoryID: 1, Amount: 50, Date: day(9, 20), Category: food},
+				{CategoryID: 1, Amount: 42, Date: day(9, 24), Category: food},
+				{CategoryID: 1, Amount: 47, Date: day(9, 28), Category: food},
+				{CategoryID: 1, Amount: 48, Date: day(10, 6), Description: "Groceries", Category: food},
+				{CategoryID: 1, Amount: 92, Date: day(10, 9), Description: "Dinner party", Category: food},
+				{CategoryID: 2, Amount: 500, Date: day(10, 5), Description: "Rent", Category: models.Category{ID: 2, Name: "Rent"}},
+			})
+		},
+	}
+	mBudget := &mockBudgetRepo{budgets: []models.Budget{{CategoryID: 1, Type: models.Expense, Amount: 400, StartMonth: "2026-01", Category: food}}}
+	mUser := &mockUserRepo{GetByIDFn: func(id uint) (*models.User, error) {
+		return &models.User{ID: id, Email: "jo@example.com", FirstName: "Jo"}, nil
+	}}
+	return mTxn, mBudget, mUser
+}
+
+func TestReportEmailService_RunDueSendsSummaryThroughSMTP(t *testing.T) {
+	cfg, messages := startSMTPSink(t)
+	mTxn, mBudget, mUser := reportEmailFixture(t)
+	repo := &mockReportEmailRepo{subscriptions: []models.ReportSubscription{
+		{ID: 1, UserID: 1, Frequency: models.ReportWeekly, Active: true, UnsubscribeToken: "tok en", NextRunAt: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+	}}
+	svc := NewReportEmailService(repo, mUser, mTxn, NewReportService(mTxn, mBudget, mUser), NewSMTPMailer(cfg), "https://budget.example.com/")
+
+	now := time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)
+	result, err := svc.RunDue(now)
+	if err != nil { t.Fatalf("run: %v", err) }
+	if result.Due != 1 || result.Sent != 1 || result.Failed != 0 { t.Fatalf("unexpected result %+v", result) }
+
+	msg := <-messages
+	header, parts := parseSinkMessage(t, msg.Data)
+	if header.Get("Subject") != "Your weekly summary: Oct 5 - Oct 11, 2026" { t.Fatalf("unexpected subject %q", header.Get("Subject")) }
+	if header.Get("List-Unsubscribe") != "<https://budget.example.com/api/email/unsubscribe?token=tok+en>" { t.Fatalf("unexpected unsubscribe header %q", header.Get("List-Unsubscribe")) }
+	text := parts["text/plain"]
+	for _, want := range []string{"Income:   2,000.00", "Net:      1,360.00", "Rent: 500.00 (78.13%)", "Food: 450.00 of 400.00 - over budget", "Oct 9 Dinner party (Food): 92.00, usually about 46.00"} {
+		if !strings.Contains(text, want) { t.Fatalf("expected %q in text part:\n%s", want, text) }
+	}
+	if strings.Contains(text, "Groceries") { t.Fatalf("ordinary purchases should not be flagged:\n%s", text) }
+	if !strings.Contains(parts["text/html"], `<a href="https://budget.example.com/api/email/unsubscribe?token=tok&#43;en">`) { t.Fatalf("unexpected html part:\n%s", parts["text/html"]) }
+
+	if d := repo.deliveries[0]; d.Status != models.DeliverySent || d.Attempts != 1 || d.SentAt == nil || !d.PeriodStart.Equal(time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected delivery %+v", d) }
+	if s := repo.subscriptions[0]; !s.NextRunAt.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) || s.LastSentAt == nil { t.Fatalf("expected the next week to be scheduled, got %+v", s) }
+
+	if result, _ := svc.RunDue(now); result.Due != 0 { t.Fatalf("expected nothing due after sending, got %+v", result) }
+}
+
+func TestReportEmailService_RunDueRetriesFailedDeliveries(t *testing.T) {
+	mTxn, mBudget, mUser := reportEmailFixture(t)
+	repo := &mockReportEmailRepo{subscriptions: []models.ReportSubscription{
+		{ID: 1, UserID: 1, Frequency: models.ReportWeekly, Active: true, UnsubscribeToken: "token", NextRunAt: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+	}}
+	mailer := &failingMailer{}
+	svc := NewReportEmailService(repo, mUser, mTxn, NewReportService(mTxn, mBudget, mUser), mailer, "http://localhost:8080")
+
+	now := time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)
+	for i := 1; i <= maxReportAttempts; i++ {
+		result, err := svc.RunDue(now.Add(time.Duration(i) * 15 * time.Minute))
+		if err != nil || result.Failed != 1 { t.Fatalf("run %d: %+v %v", i, result, err) }
+		if d := repo.deliveries[0]; d.Status != models.DeliveryFailed || d.Attempts != i || d.Error != "connection refused" { t.Fatalf("unexpected delivery after run %d: %+v", i, d) }
+		due := repo.subscriptions[0].NextRunAt.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC))
+		if due != (i < maxReportAttempts) { t.Fatalf("run %d: unexpected next run %v", i, repo.subscriptions[0].NextRunAt) }
+	}
+	if len(repo.deliveries) != 1 || mailer.calls != maxReportAttempts { t.Fatalf("expected one delivery retried %d times, got %d deliveries and %d calls", maxReportAttempts, len(repo.deliveries), mailer.calls) }
+}
+
+func TestReportEmailService_RunDueInUserTimezone(t *testing.T) {
+	// Brisbane is UTC+10 all year, so its September runs from 14:00 UTC on 31 August
+	brisbane, err := time.LoadLocation("Australia/Brisbane")
+	if err != nil { t.Fatalf("load zone: %v", err) }
+	start, end := time.Date(2026, 9, 1, 0, 0, 0, 0, brisbane), time.Date(2026, 10, 1, 0, 0, 0, 0, brisbane)
+	mTxn := &mockTxnRepo{
+		SummaryFn: func(userID uint, from, to time.Time) (*models.Summary, error) {
+			if !from.Equal(start) || !to.Equal(end) { t.Fatalf("unexpected summary bounds %v %v", from, to) }
+			return &models.Summary{TotalIncome: 100}, nil
+		},
+		SummaryByCategoryFn: func(userID uint, from, to time.Time) ([]models.CategoryTotal, error) { return nil, nil },
+		StreamFn: func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error { return nil },
+	}
+	mUser := userRepoIn("Australia/Brisbane")
+	repo := &mockReportEmailRepo{}
+	mailer := &recordingMailer{}
+	svc := NewReportEmailService(repo, mUser, mTxn, NewReportService(mTxn, &mockBudgetRepo{}, mUser), mailer, "http://localhost:8080")
+
+	subscription, err := svc.Subscribe(1, &models.SubscribeReportRequest{Frequency: models.ReportMonthly})
+	if err != nil { t.Fatalf("subscribe: %v", err) }
+	if local := subscription.NextRunAt.In(brisbane); local.Day() != 1 || local.Hour() != 0 { t.Fatalf("expected the first run at midnight in Brisbane, got %v", subscription.NextRunAt) }
+
+	repo.subscriptions[0].NextRunAt = end
+	result, err := svc.RunDue(time.Date(2026, 9, 30, 14, 30, 0, 0, time.UTC))
+	if err != nil || result.Sent != 1 { t.Fatalf("run: %+v %v", result, err) }
+	if mailer.sent[0].Subject != "Your monthly summary: September 2026" { t.Fatalf("unexpected subject %q", mailer.sent[0].Subject) }
+	if d := repo.deliveries[0]; !d.PeriodStart.Equal(start) || !d.PeriodEnd.Equal(end) { t.Fatalf("unexpected delivery period %v - %v", d.PeriodStart, d.PeriodEnd) }
+	if next := repo.subscriptions[0].NextRunAt; !next.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, brisbane)) { t.Fatalf("expected the next run at the start of November in Brisbane, got %v", next) }
+}
+
+func TestReportEmailService_RunDueWithoutMailer(t *testing.T) {
+	svc := NewReportEmailService(&mockReportEmailRepo{}, &mockUserRepo{}, &mockTxnRepo{}, nil, nil, "")
+	if _, err := svc.RunDue(time.Now()); !errors.Is(err, ErrMailerNotConfigured) { t.Fatalf("expected ErrMailerNotConfigured, got %v", err) }
+}
+
+func TestReportEmailService_SubscribeAndUnsubscribe(t *testing.T) {
+	repo := &mockReportEmailRepo{}
+	svc := NewReportEmailService(repo, userRepoIn("UTC"), &mockTxnRepo{}, nil, nil, "")
+
+	subscription, err := svc.Subscribe(1, &models.SubscribeReportRequest{Frequency: models.ReportMonthly})
+	if err != nil { t.Fatalf("subscribe: %v", err) }
+	if !subscription.Active || subscription.NextRunAt.Day() != 1 || !subscription.NextRunAt.After(time.Now()) { t.Fatalf("expected the first run at the start of next month, got %+v", subscription) }
+
+	if err := svc.Unsubscribe("token"); err != nil { t.Fatalf("unsubscribe: %v", err) }
+	if err := svc.Unsubscribe("token"); err != nil { t.Fatalf("expected a second unsubscribe to succeed, got %v", err) }
+	if repo.subscriptions[0].Active { t.Fatalf("expected subscription to be inactive") }
+	if err := svc.Unsubscribe("unknown"); !errors.Is(err, gorm.ErrRecordNotFound) { t.Fatalf("expected not found, got %v", err) }
+
+	again, err := svc.Subscribe(1, &models.SubscribeReportRequest{Frequency: models.ReportMonthly})
+	if err != nil || again.ID != subscription.ID || !again.Active || len(repo.subscriptions) != 1 { t.Fatalf("expected the subscription to be reactivated, got %+v %v", again, err) }
+}
+
+func TestReportFrequency_Periods(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	if start := models.ReportWeekly.PeriodStart(sunday, time.UTC); !start.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) { t.Fatalf("expected weeks to start on Monday, got %v", start) }
+	if start := models.ReportMonthly.PeriodStart(sunday, time.UTC); !start.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected month start %v", start) }
+	if label := reportPeriodLabel(models.ReportWeekly, time.Date(2026, 12, 28, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 4, 0, 0, 0, 0, time.UTC)); label != "Dec 28, 2026 - Jan 3, 2027" { t.Fatalf("unexpected label %q", label) }
+}
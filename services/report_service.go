@@ -0,0 +1,286 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+type ReportService interface {
+	GetCategoryBreakdown(userID uint, startDate, endDate string) (*models.CategoryBreakdown, error)
+	ComparePeriods(userID uint, req *models.CompareRequest) (*models.PeriodComparison, error)
+	GetBudgetVariance(userID uint, month string) (*models.VarianceReport, error)
+	GetStatement(userID uint, month string) (*models.Statement, error)
+	GetTaxReport(userID uint, year int, start string) (*models.TaxReport, error)
+}
+
+type reportService struct {
+	transactionRepo repository.TransactionRepository
+	budgetRepo      repository.BudgetRepository
+	userRepo        repository.UserRepository
+}
+
+func NewReportService(transactionRepo repository.TransactionRepository, budgetRepo repository.BudgetRepository, userRepo repository.UserRepository) ReportService {
+	return &reportService{
+		transactionRepo: transactionRepo,
+		budgetRepo:      budgetRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// GetCategoryBreakdown totals the period (default: current month) per category; dates are read in the
+// user's time zone
+func (s *reportService) GetCategoryBreakdown(userID uint, startDate, endDate string) (*models.CategoryBreakdown, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := resolveReportPeriod(startDate, endDate, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.transactionRepo.GetCategoryTotals(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CategoryBreakdown{
+		StartDate: start,
+		EndDate:   end,
+		Income:    breakdownSection(totals, models.Income),
+		Expense:   breakdownSection(totals, models.Expense),
+	}, nil
+}
+
+func breakdownSection(totals []models.CategoryTotal, transactionType models.TransactionType) models.CategoryBreakdownSection {
+	section := models.CategoryBreakdownSection{Categories: []models.CategoryBreakdownRow{}}
+	for _, total := range totals {
+		if total.Type != transactionType {
+			continue
+		}
+		section.Total += total.Total
+		section.Count += total.Count
+		section.Categories = append(section.Categories, models.CategoryBreakdownRow{
+			CategoryID:    total.CategoryID,
+			CategoryName:  total.CategoryName,
+			CategoryColor: total.CategoryColor,
+			Total:         roundCents(total.Total),
+			Count:         total.Count,
+		})
+	}
+
+	for i := range section.Categories {
+		row := &section.Categories[i]
+		if section.Total != 0 {
+			row.Share = roundCents(row.Total / section.Total * 100)
+		}
+		if row.Count > 0 {
+			row.Average = roundCents(row.Total / float64(row.Count))
+		}
+	}
+	sort.SliceStable(section.Categories, func(i, j int) bool {
+		return section.Categories[i].Total > section.Categories[j].Total
+	})
+	section.Total = roundCents(section.Total)
+	return section
+}
+
+// biggestMoverCount is how many categories are listed in biggest_movers
+const biggestMoverCount = 3
+
+// ComparePeriods compares two periods per category; dates and calendar periods are those of the user's
+// time zone
+func (s *reportService) ComparePeriods(userID uint, req *models.CompareRequest) (*models.PeriodComparison, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	current, previous, err := resolveComparePeriods(req, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTotals, err := s.transactionRepo.GetCategoryTotals(userID, current.StartDate, current.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	previousTotals, err := s.transactionRepo.GetCategoryTotals(userID, previous.StartDate, previous.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		categoryID      uint
+		transactionType models.TransactionType
+	}
+	rows := make(map[key]*models.CategoryComparison)
+	var order []key
+	row := func(total models.CategoryTotal) *models.CategoryComparison {
+		k := key{total.CategoryID, total.Type}
+		if existing, ok := rows[k]; ok {
+			return existing
+		}
+		rows[k] = &models.CategoryComparison{CategoryID: total.CategoryID, CategoryName: total.CategoryName, CategoryColor: total.CategoryColor, Type: total.Type}
+		order = append(order, k)
+		return rows[k]
+	}
+
+	var income, expense models.ComparisonValue
+	for _, total := range currentTotals {
+		row(total).Current += total.Total
+		if total.Type == models.Income {
+			income.Current += total.Total
+		} else {
+			expense.Current += total.Total
+		}
+	}
+	for _, total := range previousTotals {
+		row(total).Previous += total.Total
+		if total.Type == models.Income {
+			income.Previous += total.Total
+		} else {
+			expense.Previous += total.Total
+		}
+	}
+
+	comparison := &models.PeriodComparison{
+		Current:       current,
+		Previous:      previous,
+		Income:        compareValues(income.Current, income.Previous),
+		Expense:       compareValues(expense.Current, expense.Previous),
+		Net:           compareValues(income.Current-expense.Current, income.Previous-expense.Previous),
+		Categories:    make([]models.CategoryComparison, 0, len(order)),
+		BiggestMovers: []models.CategoryComparison{},
+	}
+	for _, k := range order {
+		r := rows[k]
+		r.ComparisonValue = compareValues(r.Current, r.Previous)
+		comparison.Categories = append(comparison.Categories, *r)
+	}
+	sort.SliceStable(comparison.Categories, func(i, j int) bool {
+		return math.Abs(comparison.Categories[i].Delta) > math.Abs(comparison.Categories[j].Delta)
+	})
+	for _, r := range comparison.Categories {
+		if len(comparison.BiggestMovers) == biggestMoverCount || r.Delta == 0 {
+			break
+		}
+		comparison.BiggestMovers = append(comparison.BiggestMovers, r)
+	}
+
+	return comparison, nil
+}
+
+func compareValues(current, previous float64) models.ComparisonValue {
+	value := models.ComparisonValue{
+		Current:  roundCents(current),
+		Previous: roundCents(previous),
+		Delta:    roundCents(current - previous),
+	}
+	if previous != 0 {
+		percent := roundCents((current - previous) / math.Abs(previous) * 100)
+		value.DeltaPercent = &percent
+	}
+	return value
+}
+
+// resolveComparePeriods returns the current and previous periods of a comparison. Without an explicit
+// previous range it is the span of equal length right before the current one; whole calendar months
+// are shifted by months so that e.g. March is compared with all of February. Dates are read in loc.
+func resolveComparePeriods(req *models.CompareRequest, now time.Time, loc *time.Location) (models.ReportPeriod, models.ReportPeriod, error) {
+	var current, previous models.ReportPeriod
+
+	if req.CurrentStart != "" || req.CurrentEnd != "" {
+		start, end, err := resolveReportPeriod(req.CurrentStart, req.CurrentEnd, now, loc)
+		if err != nil {
+			return current, previous, err
+		}
+		current = models.ReportPeriod{StartDate: start, EndDate: end}
+		previous = precedingPeriod(current, loc)
+	} else {
+		anchor := now
+		if req.Date != "" {
+			parsed, err := parseRangeStart(req.Date, loc)
+			if err != nil {
+				return current, previous, err
+			}
+			anchor = parsed
+		}
+		months := map[string]int{"": 1, "month": 1, "quarter": 3, "year": 12}[req.Period]
+		if months == 0 {
+			return current, previous, fmt.Errorf("%w: unknown period %q", ErrInvalidSummaryRange, req.Period)
+		}
+		start := bucketStart(anchor, models.IntervalMonth, loc)
+		start = start.AddDate(0, -((int(start.Month()) - 1) % months), 0)
+		current = models.ReportPeriod{StartDate: start, EndDate: start.AddDate(0, months, 0)}
+		previous = models.ReportPeriod{StartDate: start.AddDate(0, -months, 0), EndDate: start}
+	}
+
+	if req.PreviousStart != "" || req.PreviousEnd != "" {
+		start, end, err := resolveReportPeriod(req.PreviousStart, req.PreviousEnd, now, loc)
+		if err != nil {
+			return current, previous, err
+		}
+		previous = models.ReportPeriod{StartDate: start, EndDate: end}
+	}
+	return current, previous, nil
+}
+
+// precedingPeriod is the span of equal length right before period; a run of whole months in loc is
+// shifted by months instead
+func precedingPeriod(period models.ReportPeriod, loc *time.Location) models.ReportPeriod {
+	start, end := period.StartDate.In(loc), period.EndDate.In(loc)
+	if start.Equal(bucketStart(start, models.IntervalMonth, loc)) && end.Equal(bucketStart(end, models.IntervalMonth, loc)) {
+		months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+		return models.ReportPeriod{StartDate: start.AddDate(0, -months, 0), EndDate: start}
+	}
+	return models.ReportPeriod{StartDate: start.Add(-end.Sub(start)), EndDate: start}
+}
+
+// resolveReportPeriod parses an optional date range. A missing bound defaults to the calendar month of the
+// other bound, or of now when neither is given. Dates and months are read in loc. The returned end is
+// exclusive.
+func resolveReportPeriod(startDate, endDate string, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	start := bucketStart(now, models.IntervalMonth, loc)
+	end := nextBucket(start, models.IntervalMonth)
+
+	if endDate != "" {
+		parsed, err := parseRangeEnd(endDate, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = parsed
+		start = bucketStart(end.Add(-time.Nanosecond), models.IntervalMonth, loc)
+	}
+	if startDate != "" {
+		parsed, err := parseRangeStart(startDate, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = parsed
+		if endDate == "" {
+			end = nextBucket(bucketStart(start, models.IntervalMonth, loc), models.IntervalMonth)
+		}
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: start_date must be before end_date", ErrInvalidSummaryRange)
+	}
+	return start, end, nil
+}
+
+// resolveReportMonth parses a YYYY-MM month into its first instant in loc; an empty month is the current one
+func resolveReportMonth(month string, now time.Time, loc *time.Location) (time.Time, error) {
+	if month == "" {
+		now = now.In(loc)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc), nil
+	}
+	start, err := time.ParseInLocation("2006-01", month, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: month must be YYYY-MM", ErrInvalidSummaryRange)
+	}
+	return start, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestReportService_GetCategoryBreakdown(t *testing.T) {
+	mTxn := &mockTxnRepo{CategoryTotalsFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+		if !start.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected period %v - %v", start, end)
+		}
+		return []models.CategoryTotal{
+			{CategoryID: 1, CategoryName: "Food", CategoryColor: "#f00", Type: models.Expense, Total: 30, Count: 4},
+			{CategoryID: 2, CategoryName: "Rent", CategoryColor: "#0f0", Type: models.Expense, Total: 90, Count: 1},
+			{CategoryID: 3, CategoryName: "Salary", Type: models.Income, Total: 1000, Count: 2},
+		}, nil
+	}}
+	svc := NewReportService(mTxn, &mockBudgetRepo{}, userRepoIn("UTC"))
+
+	report, err := svc.GetCategoryBreakdown(1, "2025-09-01", "")
+	if err != nil { t.Fatalf("breakdown: %v", err) }
+	if report.Expense.Total != 120 || report.Expense.Count != 5 || len(report.Expense.Categories) != 2 { t.Fatalf("unexpected expense section %+v", report.Expense) }
+	rent, food := report.Expense.Categories[0], report.Expense.Categories[1]
+	if rent.CategoryName != "Rent" || rent.Share != 75 || rent.Average != 90 { t.Fatalf("unexpected rent row %+v", rent) }
+	if food.Share != 25 || food.Average != 7.5 || food.CategoryColor != "#f00" { t.Fatalf("unexpected food row %+v", food) }
+	if report.Income.Categories[0].Share != 100 || report.Income.Categories[0].Average != 500 { t.Fatalf("unexpected income section %+v", report.Income) }
+}
+
+func TestReportService_EmptyPeriodAndValidation(t *testing.T) {
+	mTxn := &mockTxnRepo{CategoryTotalsFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) { return nil, nil }}
+	svc := NewReportService(mTxn, &mockBudgetRepo{}, userRepoIn("UTC"))
+
+	report, err := svc.GetCategoryBreakdown(1, "", "")
+	if err != nil { t.Fatalf("breakdown: %v", err) }
+	if report.Expense.Categories == nil || len(report.Income.Categories) != 0 { t.Fatalf("expected empty, non-nil sections: %+v", report) }
+
+	if _, err := svc.GetCategoryBreakdown(1, "2025-09-10", "2025-09-01"); !errors.Is(err, ErrInvalidSummaryRange) {
+		t.Fatalf("expected ErrInvalidSummaryRange, got %v", err)
+	}
+}
+
+func TestResolveReportPeriod_Defaults(t *testing.T) {
+	now := time.Date(2025, 9, 17, 12, 0, 0, 0, time.UTC)
+	start, end, _ := resolveReportPeriod("", "", now, time.UTC)
+	if !start.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the current month, got %v - %v", start, end)
+	}
+	start, end, _ = resolveReportPeriod("", "2025-06-30", now, time.UTC)
+	if !start.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected June, got %v - %v", start, end)
+	}
+}
+
+func TestReportService_UserTimezone(t *testing.T) {
+	// 23:30 UTC on 31 August is already September in Berlin
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil { t.Fatalf("load zone: %v", err) }
+	start, end, _ := resolveReportPeriod("", "", time.Date(2025, 8, 31, 23, 30, 0, 0, time.UTC), berlin)
+	if !start.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, berlin)) || !end.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, berlin)) { t.Fatalf("expected September in Berlin, got %v - %v", start, end) }
+
+	var periods [][2]time.Time
+	mTxn := &mockTxnRepo{CategoryTotalsFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+		periods = append(periods, [2]time.Time{start, end})
+		return nil, nil
+	}}
+	svc := NewReportService(mTxn, &mockBudgetRepo{}, userRepoIn("Europe/Berlin"))
+	if _, err := svc.GetCategoryBreakdown(1, "2025-09-01", "2025-09-30"); err != nil { t.Fatalf("breakdown: %v", err) }
+	if !periods[0][0].Equal(time.Date(2025, 8, 31, 22, 0, 0, 0, time.UTC)) || !periods[0][1].Equal(time.Date(2025, 9, 30, 22, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected breakdown bounds %v", periods[0]) }
+
+	// the whole month before starts at midnight too, an hour later in UTC once summer time ends
+	periods = nil
+	if _, err := svc.ComparePeriods(1, &models.CompareRequest{Date: "2025-11-01"}); err != nil { t.Fatalf("compare: %v", err) }
+	if !periods[0][0].Equal(time.Date(2025, 10, 31, 23, 0, 0, 0, time.UTC)) || !periods[1][0].Equal(time.Date(2025, 9, 30, 22, 0, 0, 0, time.UTC)) || !periods[1][1].Equal(periods[0][0]) {
+		t.Fatalf("unexpected compared periods %v", periods)
+	}
+
+	year, end, _, err := resolveTaxYear(0, "04-06", time.Date(2025, 4, 5, 23, 30, 0, 0, time.UTC), berlin)
+	if err != nil || !year.Equal(time.Date(2025, 4, 5, 22, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 4, 5, 22, 0, 0, 0, time.UTC)) { t.Fatalf("expected the tax year to start at midnight in Berlin, got %v - %v (%v)", year, end, err) }
+}
+
+func TestReportService_ComparePeriods(t *testing.T) {
+	sept := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	mTxn := &mockTxnRepo{CategoryTotalsFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+		if start.Equal(sept) {
+			return []models.CategoryTotal{
+				{CategoryID: 1, CategoryName: "Food", Type: models.Expense, Total: 150},
+				{CategoryID: 2, CategoryName: "Fun", Type: models.Expense, Total: 20},
+				{CategoryID: 4, CategoryName: "New", Type: models.Expense, Total: 5},
+				{CategoryID: 3, CategoryName: "Salary", Type: models.Income, Total: 1000},
+			}, nil
+		}
+		if !start.Equal(time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(sept) { t.Fatalf("unexpected previous period %v - %v", start, end) }
+		return []models.CategoryTotal{
+			{CategoryID: 1, CategoryName: "Food", Type: models.Expense, Total: 100},
+			{CategoryID: 2, CategoryName: "Fun", Type: models.Expense, Total: 80},
+			{CategoryID: 3, CategoryName: "Salary", Type: models.Income, Total: 1000},
+		}, nil
+	}}
+	svc := NewReportService(mTxn, &mockBudgetRepo{}, userRepoIn("UTC"))
+
+	report, err := svc.ComparePeriods(1, &models.CompareRequest{Date: "2025-09-15"})
+	if err != nil { t.Fatalf("compare: %v", err) }
+	if report.Expense.Delta != -5 || *report.Expense.DeltaPercent != -2.78 || report.Net.Delta != 5 || *report.Income.DeltaPercent != 0 {
+		t.Fatalf("unexpected totals %+v %+v %+v", report.Income, report.Expense, report.Net)
+	}
+	if report.Categories[0].CategoryName != "Fun" || report.Categories[0].Delta != -60 || *report.Categories[0].DeltaPercent != -75 {
+		t.Fatalf("expected Fun as the biggest mover, got %+v", report.Categories[0])
+	}
+	if len(report.BiggestMovers) != 3 || report.BiggestMovers[1].CategoryName != "Food" || report.BiggestMovers[2].CategoryName != "New" {
+		t.Fatalf("unexpected movers %+v", report.BiggestMovers)
+	}
+	if report.BiggestMovers[2].DeltaPercent != nil { t.Fatalf("expected no percentage for a category without previous spending") }
+}
+
+func TestResolveComparePeriods(t *testing.T) {
+	now := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	current, previous, _ := resolveComparePeriods(&models.CompareRequest{Period: "quarter"}, now, time.UTC)
+	if !current.StartDate.Equal(day(2025, 4, 1)) || !current.EndDate.Equal(day(2025, 7, 1)) || !previous.StartDate.Equal(day(2025, 1, 1)) {
+		t.Fatalf("unexpected quarter periods %+v %+v", current, previous)
+	}
+	current, previous, _ = resolveComparePeriods(&models.CompareRequest{Period: "year"}, now, time.UTC)
+	if !current.StartDate.Equal(day(2025, 1, 1)) || !previous.StartDate.Equal(day(2024, 1, 1)) || !previous.EndDate.Equal(day(2025, 1, 1)) {
+		t.Fatalf("unexpected year periods %+v %+v", current, previous)
+	}
+	current, previous, _ = resolveComparePeriods(&models.CompareRequest{CurrentStart: "2025-03-01", CurrentEnd: "2025-03-31"}, now, time.UTC)
+	if !previous.StartDate.Equal(day(2025, 2, 1)) || !previous.EndDate.Equal(day(2025, 3, 1)) {
+		t.Fatalf("expected all of February, got %+v", previous)
+	}
+	_, previous, _ = resolveComparePeriods(&models.CompareRequest{CurrentStart: "2025-03-10", CurrentEnd: "2025-03-19"}, now, time.UTC)
+	if !previous.StartDate.Equal(day(2025, 2, 28)) || !previous.EndDate.Equal(day(2025, 3, 10)) {
+		t.Fatalf("expected the preceding 10 days, got %+v", previous)
+	}
+	_, previous, _ = resolveComparePeriods(&models.CompareRequest{PreviousStart: "2024-05-01"}, now, time.UTC)
+	if !previous.StartDate.Equal(day(2024, 5, 1)) || !previous.EndDate.Equal(day(2024, 6, 1)) {
+		t.Fatalf("expected explicit previous month, got %+v", previous)
+	}
+}
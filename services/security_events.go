@@ -0,0 +1,33 @@
+package services
+
+import (
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+// securityEventLimit is how many of the latest security events are listed
+const securityEventLimit = 50
+
+type SecurityService interface {
+	GetEvents(userID uint) ([]models.SecurityEvent, error)
+}
+
+type securityService struct {
+	events repository.SecurityEventRepository
+}
+
+func NewSecurityService(events repository.SecurityEventRepository) SecurityService {
+	return &securityService{events: events}
+}
+
+// GetEvents lists the user's latest security events, newest first
+func (s *securityService) GetEvents(userID uint) ([]models.SecurityEvent, error) {
+	events, err := s.events.GetByUserID(userID, securityEventLimit)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []models.SecurityEvent{}
+	}
+	return events, nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// GetStatement gathers a monthly statement. Income and expense come from GetSummary for the month; the
+// opening balance is every transaction before it, so closing = opening + income - expense. The month
+// and the transaction dates are in the user's time zone.
+func (s *reportService) GetStatement(userID uint, month string) (*models.Statement, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := userTimezone(user)
+	start, err := resolveReportMonth(month, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+	end := start.AddDate(0, 1, 0)
+	opening, err := s.transactionRepo.GetBalanceBefore(userID, &models.TransactionFilter{StartDate: start})
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.transactionRepo.GetSummary(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	income, expense := summary.TotalIncome, summary.TotalExpense
+
+	statement := &models.Statement{
+		Month:          start.Format("2006-01"),
+		StartDate:      start,
+		EndDate:        end,
+		AccountHolder:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Email:          user.Email,
+		GeneratedAt:    time.Now().UTC(),
+		OpeningBalance: roundCents(opening),
+		Income:         roundCents(income),
+		Expense:        roundCents(expense),
+		Net:            roundCents(income - expense),
+		ClosingBalance: roundCents(opening + income - expense),
+		Categories:     []models.StatementCategory{},
+		Transactions:   []models.StatementLine{},
+	}
+
+	totals, err := s.transactionRepo.GetSummaryByCategory(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, total := range totals {
+		category := models.StatementCategory{CategoryName: total.CategoryName, Type: total.Type, Count: total.Count, Total: roundCents(total.Total)}
+		sectionTotal := income
+		if total.Type == models.Expense {
+			sectionTotal = expense
+		}
+		if sectionTotal != 0 {
+			category.Share = roundCents(total.Total / sectionTotal * 100)
+		}
+		statement.Categories = append(statement.Categories, category)
+	}
+	sort.SliceStable(statement.Categories, func(i, j int) bool {
+		a, b := statement.Categories[i], statement.Categories[j]
+		if a.Type != b.Type {
+			return a.Type == models.Income
+		}
+		return a.Total > b.Total
+	})
+
+	balance := opening
+	filter := &models.TransactionFilter{StartDate: start, EndDate: end.Add(-time.Nanosecond)}
+	err = s.transactionRepo.StreamByUserID(userID, filter, exportBatchSize, func(batch []models.Transaction) error {
+		for _, transaction := range batch {
+			amount := exportSignedAmount(transaction)
+			balance += amount
+			statement.Transactions = append(statement.Transactions, models.StatementLine{
+				Date:         transaction.Date.In(loc),
+				Description:  transaction.Description,
+				CategoryName: transaction.Category.Name,
+				Type:         transaction.Type,
+				Amount:       roundCents(amount),
+				Balance:      roundCents(balance),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+const (
+	statementMargin = 50.0
+	statementBottom = pdfPageHeight - 60
+	statementRow    = 15.0
+)
+
+type statementColumn struct {
+	title string
+	x     float64
+	width float64
+	right bool
+}
+
+var (
+	statementCategoryColumns = []statementColumn{
+		{"Category", statementMargin, 200, false},
+		{"Type", 260, 70, false},
+		{"Transactions", 410, 80, true},
+		{"Amount", 480, 70, true},
+		{"Share", pdfPageWidth - statementMargin, 50, true},
+	}
+	statementTransactionColumns = []statementColumn{
+		{"Date", statementMargin, 60, false},
+		{"Description", 112, 170, false},
+		{"Category", 288, 100, false},
+		{"Amount", 465, 70, true},
+		{"Balance", pdfPageWidth - statementMargin, 70, true},
+	}
+)
+
+// statementWriter lays out a statement top to bottom, starting new pages as it runs out of room
+type statementWriter struct {
+	doc *pdfDocument
+	y   float64
+}
+
+// WriteStatementPDF renders the statement as an A4 PDF document
+func WriteStatementPDF(w io.Writer, statement *models.Statement) error {
+	sw := &statementWriter{doc: newPDFDocument()}
+	sw.newPage()
+
+	sw.doc.text(statementMargin, sw.y, 18, true, "Account Statement")
+	sw.doc.textRight(pdfPageWidth-statementMargin, sw.y, 12, true, statement.StartDate.Format("January 2006"))
+	sw.y += 10
+	sw.doc.line(statementMargin, sw.y, pdfPageWidth-statementMargin, sw.y)
+	sw.y += 20
+
+	lastDay := statement.EndDate.AddDate(0, 0, -1)
+	for _, line := range [][2]string{
+		{"Account holder", statement.AccountHolder},
+		{"Email", statement.Email},
+		{"Period", statement.StartDate.Format("2 January 2006") + " to " + lastDay.Format("2 January 2006")},
+		{"Generated", statement.GeneratedAt.Format("2 January 2006 15:04 MST")},
+	} {
+		sw.doc.text(statementMargin, sw.y, 10, true, line[0])
+		sw.doc.text(statementMargin+100, sw.y, 10, false, line[1])
+		sw.y += statementRow
+	}
+
+	sw.heading("Summary")
+	for i, line := range []struct {
+		label string
+		value float64
+	}{
+		{"Opening balance", statement.OpeningBalance},
+		{"Total income", statement.Income},
+		{"Total expense", -statement.Expense},
+		{"Net change", statement.Net},
+		{"Closing balance", statement.ClosingBalance},
+	} {
+		bold := i == 0 || i == 4
+		sw.doc.text(statementMargin, sw.y, 10, bold, line.label)
+		sw.doc.textRight(300, sw.y, 10, bold, formatStatementAmount(line.value))
+		sw.y += statementRow
+	}
+
+	sw.heading("Category breakdown")
+	if len(statement.Categories) == 0 {
+		sw.doc.text(statementMargin, sw.y, 10, false, "No transactions in this period.")
+		sw.y += statementRow
+	} else {
+		sw.tableHeader(statementCategoryColumns)
+		for _, category := range statement.Categories {
+			name := category.CategoryName
+			if name == "" {
+				name = "Uncategorised"
+			}
+			sw.tableRow(statementCategoryColumns, []string{
+				name,
+				string(category.Type),
+				strconv.Itoa(category.Count),
+				formatStatementAmount(category.Total),
+				strconv.FormatFloat(category.Share, 'f', 1, 64) + "%",
+			})
+		}
+	}
+
+	sw.heading("Transactions")
+	if len(statement.Transactions) == 0 {
+		sw.doc.text(statementMargin, sw.y, 10, false, "No transactions in this period.")
+	} else {
+		sw.tableHeader(statementTransactionColumns)
+		for _, line := range statement.Transactions {
+			sw.tableRow(statementTransactionColumns, []string{
+				line.Date.Format("2006-01-02"),
+				line.Description,
+				line.CategoryName,
+				formatStatementAmount(line.Amount),
+				formatStatementAmount(line.Balance),
+			})
+		}
+	}
+
+	// Footers are added last, once the page count is known
+	for i := range sw.doc.pages {
+		sw.doc.setPage(i)
+		sw.doc.text(statementMargin, pdfPageHeight-30, 8, false, "Budget Tracker statement "+statement.Month+" - "+statement.Email)
+		sw.doc.textRight(pdfPageWidth-statementMargin, pdfPageHeight-30, 8, false, fmt.Sprintf("Page %d of %d", i+1, len(sw.doc.pages)))
+	}
+
+	return sw.doc.writeTo(w)
+}
+
+func (sw *statementWriter) newPage() {
+	sw.doc.addPage()
+	sw.y = 60
+}
+
+// ensure starts a new page unless height still fits above the bottom margin
+func (sw *statementWriter) ensure(height float64) bool {
+	if sw.y+height <= statementBottom {
+		return false
+	}
+	sw.newPage()
+	return true
+}
+
+func (sw *statementWriter) heading(title string) {
+	sw.y += 15
+	sw.ensure(60)
+	sw.doc.text(statementMargin, sw.y, 13, true, title)
+	sw.y += 18
+}
+
+func (sw *statementWriter) tableHeader(columns []statementColumn) {
+	sw.doc.fillRect(statementMargin-4, sw.y-11, pdfPageWidth-2*statementMargin+8, statementRow, 0.9)
+	for _, column := range columns {
+		sw.cell(column, column.title, true)
+	}
+	sw.y += statementRow
+}
+
+// tableRow writes one row, repeating the table header at the top of a new page
+func (sw *statementWriter) tableRow(columns []statementColumn, values []string) {
+	if sw.ensure(statementRow) {
+		sw.tableHeader(columns)
+	}
+	for i, column := range columns {
+		sw.cell(column, values[i], false)
+	}
+	sw.y += statementRow
+}
+
+func (sw *statementWriter) cell(column statementColumn, value string, bold bool) {
+	value = pdfTruncate(value, column.width, 9, bold)
+	if column.right {
+		sw.doc.textRight(column.x, sw.y, 9, bold, value)
+		return
+	}
+	sw.doc.text(column.x, sw.y, 9, bold, value)
+}
+
+// formatStatementAmount formats a value with two decimals and thousands separators, e.g. -1,234.50
+func formatStatementAmount(value float64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+	}
+	cents := int64(math.Round(math.Abs(value) * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, whole, cents%100)
+}
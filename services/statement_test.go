@@ -0,0 +1,69 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestReportService_GetStatement(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+	mTxn := &mockTxnRepo{
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) {
+			if !filter.StartDate.Equal(day(1)) { t.Fatalf("unexpected opening date %v", filter.StartDate) }
+			return 500, nil
+		},
+		SummaryFn: func(userID uint, start, end time.Time) (*models.Summary, error) {
+			if !start.Equal(day(1)) || !end.Equal(day(1).AddDate(0, 1, 0)) { t.Fatalf("unexpected summary bounds %v %v", start, end) }
+			return &models.Summary{TotalIncome: 3000, TotalExpense: 1250, NetBalance: 1750}, nil
+		},
+		SummaryByCategoryFn: func(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+			return []models.CategoryTotal{
+				{CategoryName: "Food", Type: models.Expense, Total: 250, Count: 2},
+				{CategoryName: "Rent", Type: models.Expense, Total: 1000, Count: 1},
+				{CategoryName: "Salary", Type: models.Income, Total: 3000, Count: 1},
+			}, nil
+		},
+		StreamFn: func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+			return fn([]models.Transaction{
+				{Amount: 1000, Type: models.Expense, Date: day(1), Description: "September rent", Category: models.Category{Name: "Rent"}},
+				{Amount: 3000, Type: models.Income, Date: day(25), Category: models.Category{Name: "Salary"}},
+			})
+		},
+	}
+	mUser := &mockUserRepo{GetByIDFn: func(id uint) (*models.User, error) {
+		return &models.User{ID: id, Email: "jo@example.com", FirstName: "Jo", LastName: "Doe"}, nil
+	}}
+	svc := NewReportService(mTxn, &mockBudgetRepo{}, mUser)
+
+	statement, err := svc.GetStatement(1, "2026-09")
+	if err != nil { t.Fatalf("statement: %v", err) }
+	if statement.AccountHolder != "Jo Doe" || statement.OpeningBalance != 500 || statement.ClosingBalance != 2250 || statement.Net != 1750 { t.Fatalf("unexpected figures %+v", statement) }
+	if statement.Categories[0].CategoryName != "Salary" || statement.Categories[1].CategoryName != "Rent" || statement.Categories[1].Share != 80 { t.Fatalf("unexpected categories %+v", statement.Categories) }
+	if statement.Transactions[0].Balance != -500 || statement.Transactions[1].Balance != 2500 { t.Fatalf("unexpected running balances %+v", statement.Transactions) }
+}
+
+func TestWriteStatementPDF_PaginatesTransactions(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	statement := &models.Statement{Month: "2026-09", StartDate: start, EndDate: start.AddDate(0, 1, 0), AccountHolder: "Jo Doe", ClosingBalance: 1234567.5}
+	for i := 0; i < 120; i++ {
+		statement.Transactions = append(statement.Transactions, models.StatementLine{Date: start, Description: "Coffee", Amount: -3.5})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatementPDF(&buf, statement); err != nil { t.Fatalf("write: %v", err) }
+	if !bytes.Contains(buf.Bytes(), []byte("/Count 3")) { t.Fatalf("expected the listing to run over three pages") }
+
+	first, last := pdfPageContent(t, buf.Bytes(), 0), pdfPageContent(t, buf.Bytes(), 2)
+	if !strings.Contains(first, "(Account Statement)") || !strings.Contains(first, "(1,234,567.50)") || !strings.Contains(first, "(Page 1 of 3)") { t.Fatalf("unexpected first page %q", first) }
+	if !strings.Contains(last, "(Description)") || !strings.Contains(last, "(Page 3 of 3)") { t.Fatalf("expected repeated header and footer on the last page") }
+}
+
+func TestFormatStatementAmount(t *testing.T) {
+	for value, want := range map[float64]string{0: "0.00", -3.5: "-3.50", 999.999: "1,000.00", 1234567.891: "1,234,567.89"} {
+		if got := formatStatementAmount(value); got != want { t.Fatalf("format %v: expected %q, got %q", value, want, got) }
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+var ErrInvalidSummaryRange = errors.New("invalid summary range")
+
+// maxTimeSeriesBuckets keeps a single request from generating an unbounded response
+const maxTimeSeriesBuckets = 1000
+
+// defaultBucketCounts is how far back a time series reaches when no start_date is given
+var defaultBucketCounts = map[models.SummaryInterval]int{
+	models.IntervalDay:   30,
+	models.IntervalWeek:  12,
+	models.IntervalMonth: 12,
+	models.IntervalYear:  5,
+}
+
+// GetTimeSeries totals every bucket of the range; days, weeks, months and years are those of the user's
+// time zone
+func (s *transactionService) GetTimeSeries(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.TimeSeriesSummary, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	interval, start, end, periods, err := resolveSeries(interval, startDate, endDate, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.transactionRepo.GetTimeSeries(userID, interval, start, end, loc)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]models.SummaryBucket, len(rows))
+	for _, row := range rows {
+		found[row.Period] = row
+	}
+
+	buckets := make([]models.SummaryBucket, 0, len(periods))
+	for _, periodStart := range periods {
+		period := periodStart.Format("2006-01-02")
+		bucket, ok := found[period]
+		if !ok {
+			bucket = models.SummaryBucket{Period: period}
+		}
+		bucket.Income = roundCents(bucket.Income)
+		bucket.Expense = roundCents(bucket.Expense)
+		bucket.Net = roundCents(bucket.Income - bucket.Expense)
+		buckets = append(buckets, bucket)
+	}
+
+	return &models.TimeSeriesSummary{
+		Interval:  interval,
+		StartDate: start,
+		EndDate:   end,
+		Buckets:   buckets,
+	}, nil
+}
+
+// resolveSeries validates the interval and date range of a series request and lists the start of every
+// bucket in it. An empty interval means month; without start_date the default number of buckets up to
+// end_date (or today) is used. Dates and buckets are read in loc. The returned end is exclusive.
+func resolveSeries(interval models.SummaryInterval, startDate, endDate string, now time.Time, loc *time.Location) (models.SummaryInterval, time.Time, time.Time, []time.Time, error) {
+	if interval == "" {
+		interval = models.IntervalMonth
+	}
+	if _, ok := defaultBucketCounts[interval]; !ok {
+		return "", time.Time{}, time.Time{}, nil, fmt.Errorf("%w: unknown interval %q", ErrInvalidSummaryRange, interval)
+	}
+
+	end := bucketStart(now, models.IntervalDay, loc).AddDate(0, 0, 1)
+	if endDate != "" {
+		parsed, err := parseRangeEnd(endDate, loc)
+		if err != nil {
+			return "", time.Time{}, time.Time{}, nil, err
+		}
+		end = parsed
+	}
+
+	start := bucketStart(end.Add(-time.Nanosecond), interval, loc)
+	for i := 1; i < defaultBucketCounts[interval]; i++ {
+		start = previousBucket(start, interval)
+	}
+	if startDate != "" {
+		parsed, err := parseRangeStart(startDate, loc)
+		if err != nil {
+			return "", time.Time{}, time.Time{}, nil, err
+		}
+		start = parsed
+	}
+	if !start.Before(end) {
+		return "", time.Time{}, time.Time{}, nil, fmt.Errorf("%w: start_date must be before end_date", ErrInvalidSummaryRange)
+	}
+
+	var periods []time.Time
+	for cursor := bucketStart(start, interval, loc); cursor.Before(end); cursor = nextBucket(cursor, interval) {
+		if len(periods) == maxTimeSeriesBuckets {
+			return "", time.Time{}, time.Time{}, nil, fmt.Errorf("%w: more than %d buckets, use a larger interval", ErrInvalidSummaryRange, maxTimeSeriesBuckets)
+		}
+		periods = append(periods, cursor)
+	}
+	return interval, start, end, periods, nil
+}
+
+// parseRangeStart accepts a date (YYYY-MM-DD), which starts at midnight in loc, or an RFC 3339 timestamp
+func parseRangeStart(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid start_date %q, use YYYY-MM-DD or an RFC 3339 timestamp", ErrInvalidSummaryRange, value)
+	}
+	return t.UTC(), nil
+}
+
+// parseRangeEnd returns an exclusive upper bound: a plain date includes that whole day in loc
+func parseRangeEnd(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid end_date %q, use YYYY-MM-DD or an RFC 3339 timestamp", ErrInvalidSummaryRange, value)
+	}
+	return t.UTC().Add(time.Nanosecond), nil
+}
+
+// bucketStart truncates t (in loc) to the first instant of its interval; weeks start on Monday
+func bucketStart(t time.Time, interval models.SummaryInterval, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch interval {
+	case models.IntervalWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	case models.IntervalYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, loc)
+	default:
+		return day
+	}
+}
+
+func nextBucket(t time.Time, interval models.SummaryInterval) time.Time {
+	switch interval {
+	case models.IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case models.IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	case models.IntervalYear:
+		return t.AddDate(1, 0, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+func previousBucket(t time.Time, interval models.SummaryInterval) time.Time {
+	switch interval {
+	case models.IntervalWeek:
+		return t.AddDate(0, 0, -7)
+	case models.IntervalMonth:
+		return t.AddDate(0, -1, 0)
+	case models.IntervalYear:
+		return t.AddDate(-1, 0, 0)
+	default:
+		return t.AddDate(0, 0, -1)
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestGetTimeSeries_FillsEmptyBuckets(t *testing.T) {
+	mTxn := &mockTxnRepo{TimeSeriesFn: func(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+		if !start.Equal(time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected range %v - %v", start, end)
+		}
+		return []models.SummaryBucket{{Period: "2025-09-01", Income: 100, Expense: 40.1, Count: 3}}, nil
+	}}
+	svc := NewTransactionService(mTxn, &mockCatRepo{}, userRepoIn("UTC"))
+
+	series, err := svc.GetTimeSeries(1, models.IntervalMonth, "2025-07-15", "2025-09-30")
+	if err != nil { t.Fatalf("timeseries: %v", err) }
+	if len(series.Buckets) != 3 { t.Fatalf("expected July, August and September, got %+v", series.Buckets) }
+	if series.Buckets[0].Period != "2025-07-01" || series.Buckets[1].Count != 0 || series.Buckets[2].Net != 59.9 {
+		t.Fatalf("unexpected buckets %+v", series.Buckets)
+	}
+}
+
+func TestGetTimeSeries_DefaultsAndValidation(t *testing.T) {
+	var gotStart time.Time
+	mTxn := &mockTxnRepo{TimeSeriesFn: func(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+		gotStart = start
+		return nil, nil
+	}}
+	svc := NewTransactionService(mTxn, &mockCatRepo{}, userRepoIn("UTC"))
+
+	series, err := svc.GetTimeSeries(1, "", "", "2025-09-10")
+	if err != nil { t.Fatalf("timeseries: %v", err) }
+	if series.Interval != models.IntervalMonth || len(series.Buckets) != 12 || !gotStart.Equal(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the last 12 months by default, got %s %d buckets from %v", series.Interval, len(series.Buckets), gotStart)
+	}
+
+	for _, args := range [][3]string{{"quarter", "", ""}, {"month", "2025-13-01", ""}, {"month", "2025-09-10", "2025-09-01"}, {"day", "2000-01-01", "2025-01-01"}} {
+		if _, err := svc.GetTimeSeries(1, models.SummaryInterval(args[0]), args[1], args[2]); !errors.Is(err, ErrInvalidSummaryRange) {
+			t.Fatalf("expected ErrInvalidSummaryRange for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestGetTimeSeries_UserTimezone(t *testing.T) {
+	var gotLoc *time.Location
+	var gotStart, gotEnd time.Time
+	mTxn := &mockTxnRepo{TimeSeriesFn: func(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+		gotLoc, gotStart, gotEnd = loc, start, end
+		return []models.SummaryBucket{{Period: "2025-09-01", Income: 10}}, nil
+	}}
+	svc := NewTransactionService(mTxn, &mockCatRepo{}, userRepoIn("Asia/Tokyo"))
+
+	series, err := svc.GetTimeSeries(1, models.IntervalMonth, "2025-08-01", "2025-09-30")
+	if err != nil { t.Fatalf("timeseries: %v", err) }
+	if gotLoc.String() != "Asia/Tokyo" { t.Fatalf("expected the buckets in Tokyo, got %v", gotLoc) }
+	if !gotStart.Equal(time.Date(2025, 7, 31, 15, 0, 0, 0, time.UTC)) || !gotEnd.Equal(time.Date(2025, 9, 30, 15, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected bounds %v - %v", gotStart, gotEnd) }
+	if len(series.Buckets) != 2 || series.Buckets[0].Period != "2025-08-01" || series.Buckets[1].Income != 10 { t.Fatalf("unexpected buckets %+v", series.Buckets) }
+}
+
+func TestBucketStart_WeekStartsOnMonday(t *testing.T) {
+	sunday := time.Date(2025, 9, 7, 18, 0, 0, 0, time.UTC)
+	if got := bucketStart(sunday, models.IntervalWeek, time.UTC); !got.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected Monday 2025-09-01, got %v", got)
+	}
+}
+
+func TestTransactionService_GetSummary_UserTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil { t.Fatalf("load zone: %v", err) }
+	var gotStart, gotEnd time.Time
+	mTxn := &mockTxnRepo{SummaryFn: func(userID uint, start, end time.Time) (*models.Summary, error) {
+		gotStart, gotEnd = start, end
+		return &models.Summary{TotalIncome: 10.004, TotalExpense: 2.5}, nil
+	}}
+	svc := NewTransactionService(mTxn, &mockCatRepo{}, userRepoIn("Europe/Berlin"))
+
+	summary, err := svc.GetSummary(1, "2025-09-01", "2025-09-30")
+	if err != nil { t.Fatalf("summary: %v", err) }
+	// both days are inclusive and start at midnight in Berlin (UTC+2 in September)
+	if !gotStart.Equal(time.Date(2025, 8, 31, 22, 0, 0, 0, time.UTC)) || !gotEnd.Equal(time.Date(2025, 9, 30, 22, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected bounds %v - %v", gotStart, gotEnd) }
+	if summary.Timezone != "Europe/Berlin" || !summary.StartDate.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, berlin)) || summary.TotalIncome != 10 || summary.NetBalance != 7.5 { t.Fatalf("unexpected summary %+v", summary) }
+
+	// an RFC 3339 end is an inclusive instant
+	if summary, err = svc.GetSummary(1, "", "2025-09-30T12:00:00Z"); err != nil || summary.StartDate != nil || !gotStart.IsZero() || !gotEnd.Equal(time.Date(2025, 9, 30, 12, 0, 0, 1, time.UTC)) { t.Fatalf("unexpected bounds %v - %v: %v", gotStart, gotEnd, err) }
+
+	for _, args := range [][2]string{{"2025-09-31", ""}, {"09/01/2025", ""}, {"", "2025-9-30"}, {"2025-09-02", "2025-09-01"}} {
+		if _, err := svc.GetSummary(1, args[0], args[1]); !errors.Is(err, ErrInvalidSummaryRange) { t.Fatalf("expected ErrInvalidSummaryRange for %v, got %v", args, err) }
+	}
+}
+
+func TestTransactionService_GetTransactions_ResolvesFilterDates(t *testing.T) {
+	var got *models.TransactionFilter
+	mTxn := &mockTxnRepo{ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) { got = filter; return nil, nil }}
+	svc := NewTransactionService(mTxn, &mockCatRepo{}, userRepoIn("America/New_York"))
+
+	if _, err := svc.GetTransactions(1, &models.TransactionFilter{From: "2025-03-09", To: "2025-03-09"}); err != nil { t.Fatalf("list: %v", err) }
+	// the day clocks go forward in New York is 23 hours long
+	if !got.StartDate.Equal(time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC)) || !got.EndDate.Equal(time.Date(2025, 3, 10, 3, 59, 59, 999999999, time.UTC)) { t.Fatalf("unexpected filter %v - %v", got.StartDate, got.EndDate) }
+
+	if _, err := svc.GetTransactions(1, &models.TransactionFilter{To: "yesterday"}); !errors.Is(err, ErrInvalidSummaryRange) { t.Fatalf("expected ErrInvalidSummaryRange, got %v", err) }
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+// GetTaxReport totals the tax-relevant transactions of a tax year by class. Income counts as taxable,
+// expenses as deductible; classes are listed alphabetically.
+func (s *reportService) GetTaxReport(userID uint, year int, start string) (*models.TaxReport, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	startDate, endDate, label, err := resolveTaxYear(year, start, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.transactionRepo.GetTaxItems(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.TaxReport{
+		TaxYear:            label,
+		StartDate:          startDate,
+		EndDate:            endDate,
+		TaxableIncome:      []models.TaxClassTotal{},
+		DeductibleExpenses: []models.TaxClassTotal{},
+		Items:              items,
+	}
+	if report.Items == nil {
+		report.Items = []models.TaxItem{}
+	}
+	var income, expense float64
+	for _, item := range items {
+		section := &report.DeductibleExpenses
+		if item.Type == models.Income {
+			section = &report.TaxableIncome
+			income += item.Amount
+		} else {
+			expense += item.Amount
+		}
+		// items arrive ordered by class, so a class continues the last row or starts a new one
+		if n := len(*section); n == 0 || (*section)[n-1].TaxClass != item.TaxClass {
+			*section = append(*section, models.TaxClassTotal{TaxClass: item.TaxClass})
+		}
+		row := &(*section)[len(*section)-1]
+		row.Total += item.Amount
+		row.Count++
+	}
+	for _, section := range [][]models.TaxClassTotal{report.TaxableIncome, report.DeductibleExpenses} {
+		for i := range section {
+			section[i].Total = roundCents(section[i].Total)
+		}
+	}
+	report.TotalTaxableIncome = roundCents(income)
+	report.TotalDeductible = roundCents(expense)
+	return report, nil
+}
+
+// resolveTaxYear returns the bounds of the tax year starting on start (MM-DD) in year. Without a year
+// it is the tax year that contains now. The year starts at midnight in loc. 29 February is refused as it
+// does not start every year.
+func resolveTaxYear(year int, start string, now time.Time, loc *time.Location) (time.Time, time.Time, string, error) {
+	if start == "" {
+		start = "01-01"
+	}
+	first, err := time.Parse("01-02", start)
+	if err != nil || (first.Month() == time.February && first.Day() == 29) {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: start must be a MM-DD date that exists every year", ErrInvalidSummaryRange)
+	}
+
+	if year == 0 {
+		now = now.In(loc)
+		year = now.Year()
+		if now.Before(time.Date(year, first.Month(), first.Day(), 0, 0, 0, 0, loc)) {
+			year--
+		}
+	}
+	startDate := time.Date(year, first.Month(), first.Day(), 0, 0, 0, 0, loc)
+
+	label := strconv.Itoa(year)
+	if start != "01-01" {
+		label = fmt.Sprintf("%d/%02d", year, (year+1)%100)
+	}
+	return startDate, startDate.AddDate(1, 0, 0), label, nil
+}
+
+func trimTaxClass(taxClass *string) *string {
+	if taxClass == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*taxClass)
+	return &trimmed
+}
+
+var taxCSVHeader = []string{"date", "type", "tax_class", "category", "description", "amount"}
+
+// WriteTaxReportCSV writes every tax-relevant transaction followed by one total line per class and the
+// overall taxable income and deductible totals
+func WriteTaxReportCSV(w io.Writer, report *models.TaxReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(taxCSVHeader); err != nil {
+		return err
+	}
+
+	for _, item := range report.Items {
+		line := []string{item.Date.UTC().Format("2006-01-02"), string(item.Type), item.TaxClass, item.CategoryName, item.Description, strconv.FormatFloat(item.Amount, 'f', 2, 64)}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	total := func(transactionType models.TransactionType, taxClass, label string, amount float64) error {
+		return cw.Write([]string{"", string(transactionType), taxClass, "", label, strconv.FormatFloat(amount, 'f', 2, 64)})
+	}
+	for _, row := range report.TaxableIncome {
+		if err := total(models.Income, row.TaxClass, "Total "+row.TaxClass, row.Total); err != nil {
+			return err
+		}
+	}
+	for _, row := range report.DeductibleExpenses {
+		if err := total(models.Expense, row.TaxClass, "Total "+row.TaxClass, row.Total); err != nil {
+			return err
+		}
+	}
+	if err := total(models.Income, "", "Total taxable income", report.TotalTaxableIncome); err != nil {
+		return err
+	}
+	if err := total(models.Expense, "", "Total deductible", report.TotalDeductible); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func TestReportService_GetTaxReport(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	mTxn := &mockTxnRepo{TaxItemsFn: func(userID uint, start, end time.Time) ([]models.TaxItem, error) {
+		if !start.Equal(day(time.April, 6)) || !end.Equal(time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected bounds %v - %v", start, end) }
+		return []models.TaxItem{
+			{TransactionID: 1, Date: day(time.May, 1), Description: "Laptop", CategoryName: "Office", TaxClass: "business", Type: models.Expense, Amount: 1200.10},
+			{TransactionID: 2, Date: day(time.June, 1), Description: "Paper", CategoryName: "Office", TaxClass: "business", Type: models.Expense, Amount: 9.95},
+			{TransactionID: 3, Date: day(time.May, 3), Description: "Invoice 12", CategoryName: "Freelance", TaxClass: "business", Type: models.Income, Amount: 2500},
+			{TransactionID: 4, Date: day(time.July, 1), Description: "Red Cross", CategoryName: "Gifts", TaxClass: "charity", Type: models.Expense, Amount: 50},
+		}, nil
+	}}
+	svc := NewReportService(mTxn, &mockBudgetRepo{}, userRepoIn("UTC"))
+
+	report, err := svc.GetTaxReport(1, 2025, "04-06")
+	if err != nil { t.Fatalf("tax report: %v", err) }
+	if report.TaxYear != "2025/26" { t.Fatalf("unexpected label %q", report.TaxYear) }
+	if len(report.DeductibleExpenses) != 2 || report.DeductibleExpenses[0].TaxClass != "business" || report.DeductibleExpenses[0].Total != 1210.05 || report.DeductibleExpenses[0].Count != 2 {
+		t.Fatalf("unexpected deductible rows %+v", report.DeductibleExpenses)
+	}
+	if len(report.TaxableIncome) != 1 || report.TaxableIncome[0].Total != 2500 { t.Fatalf("unexpected income rows %+v", report.TaxableIncome) }
+	if report.TotalDeductible != 1260.05 || report.TotalTaxableIncome != 2500 { t.Fatalf("unexpected totals %v / %v", report.TotalDeductible, report.TotalTaxableIncome) }
+
+	var buf bytes.Buffer
+	if err := WriteTaxReportCSV(&buf, report); err != nil { t.Fatalf("csv: %v", err) }
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil { t.Fatalf("read csv: %v", err) }
+	if len(records) != 10 || records[0][0] != "date" { t.Fatalf("expected header, 4 items, 3 class totals and 2 totals, got %v", records) }
+	if got := records[1]; got[0] != "2025-05-01" || got[2] != "business" || got[5] != "1200.10" { t.Fatalf("unexpected item line %v", got) }
+	if got := records[6]; got[4] != "Total business" || got[1] != "expense" || got[5] != "1210.05" { t.Fatalf("unexpected class total %v", got) }
+	if got := records[9]; got[4] != "Total deductible" || got[5] != "1260.05" { t.Fatalf("unexpected total line %v", got) }
+}
+
+func TestResolveTaxYear(t *testing.T) {
+	now := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	start, end, label, err := resolveTaxYear(0, "", now, time.UTC)
+	if err != nil || !start.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || label != "2025" {
+		t.Fatalf("unexpected calendar year %v %v %q %v", start, end, label, err)
+	}
+	// 10 March falls before 6 April, so the current tax year began the year before
+	start, _, label, err = resolveTaxYear(0, "04-06", now, time.UTC)
+	if err != nil || !start.Equal(time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC)) || label != "2024/25" { t.Fatalf("unexpected tax year %v %q %v", start, label, err) }
+	if _, _, label, _ := resolveTaxYear(1999, "07-01", now, time.UTC); label != "1999/00" { t.Fatalf("unexpected century label %q", label) }
+
+	for _, bad := range []string{"02-29", "13-01", "4-6", "2025-04-06"} {
+		if _, _, _, err := resolveTaxYear(2025, bad, now, time.UTC); !errors.Is(err, ErrInvalidSummaryRange) { t.Fatalf("expected %q to be rejected, got %v", bad, err) }
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	// embed the zone database so time zones resolve on hosts without one
+	_ "time/tzdata"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+	"github.com/aditherevenger/Budget-Tracker-API/repository"
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// loadTimezone resolves an IANA time zone name; an empty name is UTC
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("%w: %q is not an IANA time zone such as Europe/Berlin", ErrInvalidTimezone, name)
+	}
+	return loc, nil
+}
+
+// userTimezone returns the time zone the user's dates are read in. A stored zone that no longer
+// resolves falls back to UTC.
+func userTimezone(user *models.User) *time.Location {
+	loc, err := loadTimezone(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func userLocation(userRepo repository.UserRepository, userID uint) (*time.Location, error) {
+	user, err := userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return userTimezone(user), nil
+}
+
+// resolveDateRange reads optional start_date and end_date parameters in loc. Both days are inclusive,
+// so the returned end is the first instant after end_date. A bound that is not given stays zero.
+func resolveDateRange(startDate, endDate string, loc *time.Location) (time.Time, time.Time, error) {
+	var start, end time.Time
+	if startDate != "" {
+		parsed, err := parseRangeStart(startDate, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = parsed
+	}
+	if endDate != "" {
+		parsed, err := parseRangeEnd(endDate, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = parsed
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: start_date must not be after end_date", ErrInvalidSummaryRange)
+	}
+	return start, end, nil
+}
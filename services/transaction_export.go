@@ -0,0 +1,281 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+const exportBatchSize = 500
+
+type exportWriter interface {
+	WriteRow(values []interface{}) error
+	// Flush pushes everything written so far to the underlying writer
+	Flush() error
+	Close() error
+}
+
+func newExportWriter(format models.ExportFormat, columns []string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case models.ExportFormatCSV, "":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return nil, err
+		}
+		return &csvExportWriter{w: cw}, nil
+	case models.ExportFormatNDJSON:
+		return &ndjsonExportWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case models.ExportFormatXLSX:
+		return newXLSXExportWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// exportValues picks the requested columns for one transaction; signed is the amount with expenses negative
+func exportValues(columns []string, transaction models.Transaction, signed, balance float64) []interface{} {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "id":
+			values[i] = transaction.ID
+		case "date":
+			values[i] = transaction.Date
+		case "type":
+			values[i] = string(transaction.Type)
+		case "category":
+			values[i] = transaction.Category.Name
+		case "description":
+			values[i] = transaction.Description
+		case "amount":
+			values[i] = transaction.Amount
+		case "signed_amount":
+			values[i] = signed
+		case "running_balance":
+			values[i] = balance
+		}
+	}
+	return values
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e *csvExportWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			record[i] = v.UTC().Format("2006-01-02")
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	return e.Flush()
+}
+
+type ndjsonExportWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+// WriteRow writes one JSON object per line, keeping the keys in column order
+func (e *ndjsonExportWriter) WriteRow(values []interface{}) error {
+	e.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.columns[i])
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		e.w.Write(key)
+		e.w.WriteByte(':')
+		e.w.Write(encoded)
+	}
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+func (e *ndjsonExportWriter) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *ndjsonExportWriter) Close() error {
+	return e.w.Flush()
+}
+
+// xlsxExportWriter streams a single-sheet workbook. Strings are written inline so no shared
+// string table has to be held in memory, and the sheet part is compressed as it is written.
+type xlsxExportWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+const (
+	xlsxStyleDate   = 1
+	xlsxStyleAmount = 2
+	xlsxStyleHeader = 3
+)
+
+var xlsxStaticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Transactions" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`},
+}
+
+func newXLSXExportWriter(w io.Writer, columns []string) (*xlsxExportWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e := &xlsxExportWriter{zw: zw, sheet: bufio.NewWriter(f)}
+	e.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	return e, e.writeRow(header, xlsxStyleHeader)
+}
+
+func (e *xlsxExportWriter) WriteRow(values []interface{}) error {
+	return e.writeRow(values, 0)
+}
+
+func (e *xlsxExportWriter) writeRow(values []interface{}, style int) error {
+	e.row++
+	fmt.Fprintf(e.sheet, `<row r="%d">`, e.row)
+	for i, value := range values {
+		ref := xlsxColumnName(i) + strconv.Itoa(e.row)
+		switch v := value.(type) {
+		case time.Time:
+			fmt.Fprintf(e.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, strconv.FormatFloat(xlsxSerialDate(v), 'f', -1, 64))
+		case float64:
+			fmt.Fprintf(e.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleAmount, strconv.FormatFloat(v, 'f', -1, 64))
+		case uint:
+			fmt.Fprintf(e.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		default:
+			if style != 0 {
+				fmt.Fprintf(e.sheet, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			} else {
+				fmt.Fprintf(e.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			}
+			if err := xml.EscapeText(e.sheet, []byte(fmt.Sprint(v))); err != nil {
+				return err
+			}
+			e.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := e.sheet.WriteString("</row>")
+	return err
+}
+
+func (e *xlsxExportWriter) Flush() error {
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zw.Flush()
+}
+
+func (e *xlsxExportWriter) Close() error {
+	e.sheet.WriteString("</sheetData></worksheet>")
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
+
+// xlsxColumnName converts a zero-based index to a spreadsheet column name (0 → A, 26 → AA)
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xlsxSerialDate converts a date to the 1900 date system spreadsheets use, counting days from 1899-12-30
+func xlsxSerialDate(t time.Time) float64 {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return math.Round(day.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// ExportContentType returns the MIME type and file extension for a download in the given format
+func ExportContentType(format models.ExportFormat) (contentType, extension string) {
+	switch format {
+	case models.ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"
+	case models.ExportFormatNDJSON:
+		return "application/x-ndjson", "ndjson"
+	default:
+		return "text/csv; charset=utf-8", "csv"
+	}
+}
+
+// flusher matches response writers that can push buffered data to the client
+type flusher interface {
+	Flush()
+}
+
+func flushResponse(w io.Writer) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
+
+func exportSignedAmount(transaction models.Transaction) float64 {
+	if transaction.Type == models.Expense {
+		return -transaction.Amount
+	}
+	return transaction.Amount
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditherevenger/Budget-Tracker-API/models"
+)
+
+func exportRepo(t *testing.T) *mockTxnRepo {
+	return &mockTxnRepo{
+		BalanceBeforeFn: func(userID uint, filter *models.TransactionFilter) (float64, error) { return 100, nil },
+		StreamFn: func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+			if batchSize != exportBatchSize { t.Fatalf("unexpected batch size %d", batchSize) }
+			first := []models.Transaction{
+				{ID: 1, Amount: 20.10, Type: models.Expense, Description: "Lunch, with \"team\"", Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Category: models.Category{Name: "Food"}},
+			}
+			second := []models.Transaction{
+				{ID: 2, Amount: 50, Type: models.Income, Description: "Refund <online>", Date: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC), Category: models.Category{Name: "Other"}},
+			}
+			if err := fn(first); err != nil { return err }
+			return fn(second)
+		},
+	}
+}
+
+func TestExportTransactions_CSVWithRunningBalance(t *testing.T) {
+	svc := NewTransactionService(exportRepo(t), &mockCatRepo{}, userRepoIn("UTC"))
+	var buf bytes.Buffer
+	if err := svc.ExportTransactions(1, &models.TransactionFilter{}, models.ExportFormatCSV, models.ExportColumns, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := "id,date,type,category,description,amount,signed_amount,running_balance\n" +
+		"1,2025-09-01,expense,Food,\"Lunch, with \"\"team\"\"\",20.10,-20.10,79.90\n" +
+		"2,2025-09-02,income,Other,Refund <online>,50.00,50.00,129.90\n"
+	if buf.String() != want { t.Fatalf("unexpected CSV:\n%s", buf.String()) }
+}
+
+func TestExportTransactions_NDJSONKeepsColumnOrder(t *testing.T) {
+	svc := NewTransactionService(exportRepo(t), &mockCatRepo{}, userRepoIn("UTC"))
+	var buf bytes.Buffer
+	if err := svc.ExportTransactions(1, &models.TransactionFilter{}, models.ExportFormatNDJSON, []string{"running_balance", "category"}, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := "{\"running_balance\":79.9,\"category\":\"Food\"}\n{\"running_balance\":129.9,\"category\":\"Other\"}\n"
+	if buf.String() != want { t.Fatalf("unexpected NDJSON:\n%s", buf.String()) }
+}
+
+func TestExportTransactions_XLSX(t *testing.T) {
+	svc := NewTransactionService(exportRepo(t), &mockCatRepo{}, userRepoIn("UTC"))
+	var buf bytes.Buffer
+	if err := svc.ExportTransactions(1, &models.TransactionFilter{}, models.ExportFormatXLSX, []string{"date", "description", "amount"}, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil { t.Fatalf("expected a zip package: %v", err) }
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(body)
+	}
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml", "xl/styles.xml"} {
+		if _, ok := parts[name]; !ok { t.Fatalf("missing part %s", name) }
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, fragment := range []string{`<c r="A2" s="1"><v>45901</v></c>`, `Refund &lt;online&gt;`, `<c r="C3" s="2"><v>50</v></c>`, `</sheetData></worksheet>`} {
+		if !strings.Contains(sheet, fragment) { t.Fatalf("sheet missing %q:\n%s", fragment, sheet) }
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumnName(index); got != want { t.Fatalf("column %d: expected %s got %s", index, want, got) }
+	}
+}
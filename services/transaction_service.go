@@ -4,6 +4,8 @@ import (
 	"errors"
 	"github.com/aditherevenger/Budget-Tracker-API/models"
 	"github.com/aditherevenger/Budget-Tracker-API/repository"
+	"io"
+	"time"
 )
 
 type TransactionService interface {
@@ -12,18 +14,23 @@ type TransactionService interface {
 	GetTransactionByID(id uint, userID uint) (*models.Transaction, error)
 	UpdateTransaction(id uint, userID uint, req *models.UpdateTransactionRequest) (*models.Transaction, error)
 	DeleteTransaction(id uint, userID uint) error
-	GetSummary(userID uint, startDate, endDate string) (map[string]interface{}, error)
+	GetSummary(userID uint, startDate, endDate string) (*models.Summary, error)
+	GetTimeSeries(userID uint, interval models.SummaryInterval, startDate, endDate string) (*models.TimeSeriesSummary, error)
+	ExportQIF(userID uint, filter *models.TransactionFilter, w io.Writer) error
+	ExportTransactions(userID uint, filter *models.TransactionFilter, format models.ExportFormat, columns []string, w io.Writer) error
 }
 
 type transactionService struct {
 	transactionRepo repository.TransactionRepository
 	categoryRepo    repository.CategoryRepository
+	userRepo        repository.UserRepository
 }
 
-func NewTransactionService(transactionRepo repository.TransactionRepository, categoryRepo repository.CategoryRepository) TransactionService {
+func NewTransactionService(transactionRepo repository.TransactionRepository, categoryRepo repository.CategoryRepository, userRepo repository.UserRepository) TransactionService {
 	return &transactionService{
 		transactionRepo: transactionRepo,
 		categoryRepo:    categoryRepo,
+		userRepo:        userRepo,
 	}
 }
 
@@ -41,6 +48,7 @@ func (s *transactionService) CreateTransaction(userID uint, req *models.CreateTr
 		Type:        req.Type,
 		Description: req.Description,
 		Date:        req.Date,
+		TaxClass:    trimTaxClass(req.TaxClass),
 	}
 
 	err = s.transactionRepo.Create(transaction)
@@ -53,7 +61,17 @@ func (s *transactionService) CreateTransaction(userID uint, req *models.CreateTr
 }
 
 func (s *transactionService) GetTransactions(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) {
-	return s.transactionRepo.GetByUserID(userID, filter)
+	if err := s.resolveFilter(userID, filter); err != nil {
+		return nil, err
+	}
+	transactions, err := s.transactionRepo.GetByUserID(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := annotateAnomalies(s.transactionRepo, userID, transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
 }
 
 func (s *transactionService) GetTransactionByID(id uint, userID uint) (*models.Transaction, error) {
@@ -91,6 +109,12 @@ func (s *transactionService) UpdateTransaction(id uint, userID uint, req *models
 		transaction.Date = *req.Date
 	}
 
+	if req.ClearTaxClass {
+		transaction.TaxClass = nil
+	} else if req.TaxClass != nil {
+		transaction.TaxClass = trimTaxClass(req.TaxClass)
+	}
+
 	err = s.transactionRepo.Update(transaction)
 	if err != nil {
 		return nil, err
@@ -104,6 +128,111 @@ func (s *transactionService) DeleteTransaction(id uint, userID uint) error {
 	return s.transactionRepo.Delete(id, userID)
 }
 
-func (s *transactionService) GetSummary(userID uint, startDate, endDate string) (map[string]interface{}, error) {
-	return s.transactionRepo.GetSummary(userID, startDate, endDate)
+// GetSummary totals the days from startDate through endDate, both read in the user's time zone
+func (s *transactionService) GetSummary(userID uint, startDate, endDate string) (*models.Summary, error) {
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := resolveDateRange(startDate, endDate, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.transactionRepo.GetSummary(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	summary.Timezone = loc.String()
+	if !start.IsZero() {
+		summary.StartDate = &start
+	}
+	if !end.IsZero() {
+		summary.EndDate = &end
+	}
+	summary.TotalIncome = roundCents(summary.TotalIncome)
+	summary.TotalExpense = roundCents(summary.TotalExpense)
+	summary.NetBalance = roundCents(summary.TotalIncome - summary.TotalExpense)
+	return summary, nil
+}
+
+// resolveFilter sets the filter's StartDate and EndDate from the raw start_date and end_date, read in the
+// user's time zone like the summary
+func (s *transactionService) resolveFilter(userID uint, filter *models.TransactionFilter) error {
+	if filter.From == "" && filter.To == "" {
+		return nil
+	}
+	loc, err := userLocation(s.userRepo, userID)
+	if err != nil {
+		return err
+	}
+	start, end, err := resolveDateRange(filter.From, filter.To, loc)
+	if err != nil {
+		return err
+	}
+	filter.StartDate = start
+	filter.EndDate = time.Time{}
+	if !end.IsZero() {
+		filter.EndDate = end.Add(-time.Nanosecond)
+	}
+	return nil
+}
+
+func (s *transactionService) ExportQIF(userID uint, filter *models.TransactionFilter, w io.Writer) error {
+	if err := s.resolveFilter(userID, filter); err != nil {
+		return err
+	}
+	transactions, err := s.transactionRepo.GetByUserID(userID, filter)
+	if err != nil {
+		return err
+	}
+
+	// The repository lists newest first; registers read oldest first
+	for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+		transactions[i], transactions[j] = transactions[j], transactions[i]
+	}
+
+	return WriteQIF(w, transactions)
+}
+
+// ExportTransactions streams the filtered transactions oldest first in the requested format. The running
+// balance starts from the net of matching transactions before the start date, so it lines up with the
+// account balance when no type or category filter is applied.
+func (s *transactionService) ExportTransactions(userID uint, filter *models.TransactionFilter, format models.ExportFormat, columns []string, w io.Writer) error {
+	if err := s.resolveFilter(userID, filter); err != nil {
+		return err
+	}
+	balance, err := s.transactionRepo.GetBalanceBefore(userID, filter)
+	if err != nil {
+		return err
+	}
+
+	writer, err := newExportWriter(format, columns, w)
+	if err != nil {
+		return err
+	}
+
+	err = s.transactionRepo.StreamByUserID(userID, filter, exportBatchSize, func(batch []models.Transaction) error {
+		for _, transaction := range batch {
+			signed := exportSignedAmount(transaction)
+			balance = roundCents(balance + signed)
+			if err := writer.WriteRow(exportValues(columns, transaction, signed, balance)); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		flushResponse(w)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	flushResponse(w)
+	return nil
 }
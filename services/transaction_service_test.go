@@ -15,7 +15,16 @@ type mockTxnRepo struct {
 	ListFn     func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error)
 	UpdateFn   func(transaction *models.Transaction) error
 	DeleteFn   func(id uint, userID uint) error
-	SummaryFn  func(userID uint, startDate, endDate string) (map[string]interface{}, error)
+	SummaryFn  func(userID uint, start, end time.Time) (*models.Summary, error)
+	CreateImportFn func(batch *models.ImportBatch, transactions []models.Transaction) error
+	UndoImportFn   func(batchID uint, userID uint) error
+	FindExternalIDsFn func(userID uint, externalIDs []string) ([]string, error)
+	StreamFn          func(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error
+	BalanceBeforeFn   func(userID uint, filter *models.TransactionFilter) (float64, error)
+	TimeSeriesFn      func(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error)
+	CategoryTotalsFn  func(userID uint, start, end time.Time) ([]models.CategoryTotal, error)
+	SummaryByCategoryFn func(userID uint, start, end time.Time) ([]models.CategoryTotal, error)
+	TaxItemsFn          func(userID uint, start, end time.Time) ([]models.TaxItem, error)
 }
 
 func (m *mockTxnRepo) Create(transaction *models.Transaction) error                                    { return m.CreateFn(transaction) }
@@ -25,18 +34,54 @@ func (m *mockTxnRepo) GetByUserID(userID uint, filter *models.TransactionFilter)
 }
 func (m *mockTxnRepo) Update(transaction *models.Transaction) error                                    { return m.UpdateFn(transaction) }
 func (m *mockTxnRepo) Delete(id uint, userID uint) error                                               { return m.DeleteFn(id, userID) }
-func (m *mockTxnRepo) GetSummary(userID uint, startDate, endDate string) (map[string]interface{}, error) {
-	return m.SummaryFn(userID, startDate, endDate)
+func (m *mockTxnRepo) GetSummary(userID uint, start, end time.Time) (*models.Summary, error) {
+	return m.SummaryFn(userID, start, end)
+}
+
+func (m *mockTxnRepo) CreateImport(batch *models.ImportBatch, transactions []models.Transaction) error {
+	return m.CreateImportFn(batch, transactions)
+}
+func (m *mockTxnRepo) UndoImport(batchID uint, userID uint) error { return m.UndoImportFn(batchID, userID) }
+func (m *mockTxnRepo) FindExternalIDs(userID uint, externalIDs []string) ([]string, error) {
+	return m.FindExternalIDsFn(userID, externalIDs)
+}
+func (m *mockTxnRepo) StreamByUserID(userID uint, filter *models.TransactionFilter, batchSize int, fn func([]models.Transaction) error) error {
+	return m.StreamFn(userID, filter, batchSize, fn)
+}
+func (m *mockTxnRepo) GetBalanceBefore(userID uint, filter *models.TransactionFilter) (float64, error) {
+	return m.BalanceBeforeFn(userID, filter)
+}
+func (m *mockTxnRepo) GetTimeSeries(userID uint, interval models.SummaryInterval, start, end time.Time, loc *time.Location) ([]models.SummaryBucket, error) {
+	return m.TimeSeriesFn(userID, interval, start, end, loc)
+}
+func (m *mockTxnRepo) GetCategoryTotals(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+	return m.CategoryTotalsFn(userID, start, end)
+}
+
+func (m *mockTxnRepo) GetSummaryByCategory(userID uint, start, end time.Time) ([]models.CategoryTotal, error) {
+	return m.SummaryByCategoryFn(userID, start, end)
+}
+func (m *mockTxnRepo) GetTaxItems(userID uint, start, end time.Time) ([]models.TaxItem, error) {
+	return m.TaxItemsFn(userID, start, end)
 }
 
 var _ repository.TransactionRepository = (*mockTxnRepo)(nil)
 
+// userRepoIn returns a user repository whose users read their dates in timezone
+func userRepoIn(timezone string) *mockUserRepo {
+	return &mockUserRepo{GetByIDFn: func(id uint) (*models.User, error) { return &models.User{ID: id, Timezone: timezone}, nil }}
+}
+
 type mockCatRepo struct {
-	GetByIDFn func(id uint, userID uint) (*models.Category, error)
+	GetByIDFn     func(id uint, userID uint) (*models.Category, error)
+	GetByUserIDFn func(userID uint) ([]models.Category, error)
 }
 
 func (m *mockCatRepo) Create(category *models.Category) error { return nil }
-func (m *mockCatRepo) GetByUserID(userID uint, filter *models.User) ([]models.Category, error) { return nil, nil }
+func (m *mockCatRepo) GetByUserID(userID uint, filter *models.User) ([]models.Category, error) {
+	if m.GetByUserIDFn == nil { return nil, nil }
+	return m.GetByUserIDFn(userID)
+}
 func (m *mockCatRepo) GetByID(id uint, userID uint) (*models.Category, error) { return m.GetByIDFn(id, userID) }
 func (m *mockCatRepo) Update(category *models.Category) error { return nil }
 func (m *mockCatRepo) Delete(id uint, userID uint) error { return nil }
@@ -46,7 +91,7 @@ var _ repository.CategoryRepository = (*mockCatRepo)(nil)
 func TestTransactionService_Create_Success(t *testing.T) {
 	mTxn := &mockTxnRepo{ CreateFn: func(transaction *models.Transaction) error { transaction.ID = 1; return nil }, GetByIDFn: func(id uint, userID uint) (*models.Transaction, error) { return &models.Transaction{ID: id, UserID: userID, CategoryID: 2, Amount: 10, Type: models.Expense}, nil } }
 	mCat := &mockCatRepo{ GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return &models.Category{ID: id, UserID: userID, Name: "Food"}, nil } }
-	svc := NewTransactionService(mTxn, mCat)
+	svc := NewTransactionService(mTxn, mCat, userRepoIn("UTC"))
 	req := &models.CreateTransactionRequest{CategoryID: 2, Amount: 10, Type: models.Expense, Description: "Coffee", Date: time.Now().UTC()}
 	tx, err := svc.CreateTransaction(5, req)
 	if err != nil { t.Fatalf("create: %v", err) }
@@ -56,7 +101,7 @@ func TestTransactionService_Create_Success(t *testing.T) {
 func TestTransactionService_Create_CategoryNotOwned(t *testing.T) {
 	mTxn := &mockTxnRepo{}
 	mCat := &mockCatRepo{ GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return nil, errors.New("not found") } }
-	svc := NewTransactionService(mTxn, mCat)
+	svc := NewTransactionService(mTxn, mCat, userRepoIn("UTC"))
 	_, err := svc.CreateTransaction(5, &models.CreateTransactionRequest{CategoryID: 2, Amount: 10, Type: models.Expense, Date: time.Now().UTC()})
 	if err == nil { t.Fatalf("expected error when category not owned") }
 }
@@ -65,7 +110,7 @@ func TestTransactionService_List_Get_GetByID(t *testing.T) {
 	now := time.Now().UTC()
 	mTxn := &mockTxnRepo{ ListFn: func(userID uint, filter *models.TransactionFilter) ([]models.Transaction, error) { return []models.Transaction{{ID: 1, UserID: userID}}, nil }, GetByIDFn: func(id uint, userID uint) (*models.Transaction, error) { return &models.Transaction{ID: id, UserID: userID, Date: now}, nil } }
 	mCat := &mockCatRepo{ GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return &models.Category{ID: id, UserID: userID}, nil } }
-	svc := NewTransactionService(mTxn, mCat)
+	svc := NewTransactionService(mTxn, mCat, userRepoIn("UTC"))
 	items, err := svc.GetTransactions(7, &models.TransactionFilter{})
 	if err != nil || len(items) != 1 { t.Fatalf("list: %v len=%d", err, len(items)) }
 	got, err := svc.GetTransactionByID(1, 7)
@@ -83,7 +128,7 @@ func TestTransactionService_Update_Success(t *testing.T) {
         },
     }
     mCat := &mockCatRepo{ GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return &models.Category{ID: id, UserID: userID}, nil } }
-    svc := NewTransactionService(mTxn, mCat)
+    svc := NewTransactionService(mTxn, mCat, userRepoIn("UTC"))
     newAmt := 20.0
     newCat := uint(3)
     req := &models.UpdateTransactionRequest{Amount: &newAmt, CategoryID: &newCat}
@@ -94,21 +139,33 @@ func TestTransactionService_Update_Success(t *testing.T) {
     if err != nil || got.ID != 1 || got.Amount != 20 || got.CategoryID != 3 { t.Fatalf("get: %v got=%+v", err, got) }
 }
 
+func TestTransactionService_Update_TaxClass(t *testing.T) {
+	business := "business"
+	current := &models.Transaction{ID: 1, UserID: 7, CategoryID: 2, Amount: 10, Type: models.Expense, TaxClass: &business}
+	mTxn := &mockTxnRepo{ GetByIDFn: func(id uint, userID uint) (*models.Transaction, error) { return current, nil }, UpdateFn: func(transaction *models.Transaction) error { current = transaction; return nil } }
+	svc := NewTransactionService(mTxn, &mockCatRepo{}, userRepoIn("UTC"))
+	override := "  medical "
+	tx, err := svc.UpdateTransaction(1, 7, &models.UpdateTransactionRequest{TaxClass: &override})
+	if err != nil || tx.TaxClass == nil || *tx.TaxClass != "medical" { t.Fatalf("expected trimmed override, got %+v %v", tx, err) }
+	tx, err = svc.UpdateTransaction(1, 7, &models.UpdateTransactionRequest{ClearTaxClass: true})
+	if err != nil || tx.TaxClass != nil { t.Fatalf("expected override cleared, got %+v %v", tx, err) }
+}
+
 func TestTransactionService_Update_InvalidCategory(t *testing.T) {
 	mTxn := &mockTxnRepo{ GetByIDFn: func(id uint, userID uint) (*models.Transaction, error) { return &models.Transaction{ID: id, UserID: userID, CategoryID: 2, Amount: 10, Type: models.Expense}, nil } }
 	mCat := &mockCatRepo{ GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return nil, errors.New("not found") } }
-	svc := NewTransactionService(mTxn, mCat)
+	svc := NewTransactionService(mTxn, mCat, userRepoIn("UTC"))
 	newCat := uint(99)
 	_, err := svc.UpdateTransaction(1, 7, &models.UpdateTransactionRequest{CategoryID: &newCat})
 	if err == nil { t.Fatalf("expected error when category not found/owned") }
 }
 
 func TestTransactionService_Delete_And_Summary(t *testing.T) {
-	mTxn := &mockTxnRepo{ DeleteFn: func(id uint, userID uint) error { return nil }, SummaryFn: func(userID uint, startDate, endDate string) (map[string]interface{}, error) { return map[string]interface{}{"total_income": 100.0, "total_expense": 50.0, "net_balance": 50.0}, nil } }
+	mTxn := &mockTxnRepo{ DeleteFn: func(id uint, userID uint) error { return nil }, SummaryFn: func(userID uint, start, end time.Time) (*models.Summary, error) { return &models.Summary{TotalIncome: 100.0, TotalExpense: 49.995, NetBalance: 50.005}, nil } }
 	mCat := &mockCatRepo{ GetByIDFn: func(id uint, userID uint) (*models.Category, error) { return &models.Category{ID: id, UserID: userID}, nil } }
-	svc := NewTransactionService(mTxn, mCat)
+	svc := NewTransactionService(mTxn, mCat, userRepoIn("UTC"))
 	if err := svc.DeleteTransaction(2, 7); err != nil { t.Fatalf("delete: %v", err) }
 	sum, err := svc.GetSummary(7, "", "")
 	if err != nil { t.Fatalf("summary: %v", err) }
-	if sum["net_balance"].(float64) != 50.0 { t.Fatalf("unexpected summary: %+v", sum) }
+	if sum.NetBalance != 50.0 || sum.TotalExpense != 50.0 || sum.Timezone != "UTC" || sum.StartDate != nil || sum.EndDate != nil { t.Fatalf("unexpected summary: %+v", sum) }
 }
@@ -3,45 +3,36 @@ package utils
 import (
 	"errors"
 	"github.com/golang-jwt/jwt/v4"
-	"os"
 	"time"
 )
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID uint   `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GetJWTSecret() []byte {
-	secret := os.Getenv("JWT_SECRET")
-	if secret != "" {
-		secret = "your-secret-key-change-this-in-production"
-	}
-	return []byte("secret")
-}
-
-func GenerateToken(userID uint, email string) (string, error) {
+// GenerateToken issues an access token for the session that expires after ttl
+func GenerateToken(userID uint, email string, sessionID uint, ttl time.Duration) (string, error) {
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(GetJWTSecret())
+	return activeKeys().sign(claims)
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return GetJWTSecret(), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, activeKeys().verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -53,3 +44,38 @@ func ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// ChallengeClaims identify a user who passed the password check but still owes a second factor. They
+// carry no session, so AuthMiddleware refuses them as access tokens.
+type ChallengeClaims struct {
+	UserID uint `json:"user_id"`
+	MFA    bool `json:"mfa"`
+	jwt.RegisteredClaims
+}
+
+// GenerateChallengeToken issues the token that /api/auth/mfa/verify trades for a session
+func GenerateChallengeToken(userID uint, ttl time.Duration) (string, error) {
+	claims := &ChallengeClaims{
+		UserID: userID,
+		MFA:    true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return activeKeys().sign(claims)
+}
+
+// ValidateChallengeToken returns the user of a valid challenge token
+func ValidateChallengeToken(tokenString string) (uint, error) {
+	claims := &ChallengeClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, activeKeys().verificationKey)
+	if err != nil {
+		return 0, err
+	}
+	if !token.Valid || !claims.MFA || claims.UserID == 0 {
+		return 0, errors.New("invalid challenge token")
+	}
+	return claims.UserID, nil
+}
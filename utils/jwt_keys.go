@@ -0,0 +1,302 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+)
+
+// MinSecretLength is the shortest HS256 secret accepted in release mode
+const MinSecretLength = 32
+
+var (
+	ErrWeakJWTSecret   = errors.New("JWT secret must be a random value of at least 32 bytes")
+	ErrInvalidJWTKey   = errors.New("invalid JWT signing key")
+	ErrUnknownJWTKeyID = errors.New("token signed with an unknown key")
+)
+
+// placeholderSecrets are the example values shipped in the config defaults and the README
+var placeholderSecrets = map[string]bool{
+	"default-secret-change-in-production":       true,
+	"your-secret-key-change-this-in-production": true,
+	"secret": true,
+}
+
+type jwtKey struct {
+	id     string
+	method jwt.SigningMethod
+	// private is nil for keys that only verify tokens signed before a rotation
+	private interface{}
+	public  interface{}
+	weak    bool
+}
+
+// KeySet signs access tokens with its current key and accepts tokens signed by any of its keys
+type KeySet struct {
+	current *jwtKey
+	keys    map[string]*jwtKey
+}
+
+// JWK is the public half of a signing key as published in the JWKS document
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	jwtKeys   *KeySet
+	jwtKeysMu sync.Mutex
+)
+
+// SetKeySet installs the keys GenerateToken and ValidateToken use
+func SetKeySet(keys *KeySet) {
+	jwtKeysMu.Lock()
+	defer jwtKeysMu.Unlock()
+	jwtKeys = keys
+}
+
+// activeKeys returns the installed keys. Without configuration a random secret is generated, so
+// tokens stay valid only as long as the process runs.
+func activeKeys() *KeySet {
+	jwtKeysMu.Lock()
+	defer jwtKeysMu.Unlock()
+	if jwtKeys == nil {
+		secret := make([]byte, MinSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+		key := hmacKey(string(secret))
+		jwtKeys = &KeySet{current: key, keys: map[string]*jwtKey{key.id: key}}
+	}
+	return jwtKeys
+}
+
+// NewKeySet builds the signing keys described by cfg. In release mode a weak or placeholder HS256
+// secret, current or previous, is an error rather than a warning.
+func NewKeySet(cfg config.JWTConfig, release bool) (*KeySet, error) {
+	var current *jwtKey
+	switch cfg.Algorithm {
+	case "", "HS256":
+		current = hmacKey(cfg.Secret)
+	case "RS256", "EdDSA":
+		if cfg.PrivateKeyFile == "" {
+			return nil, fmt.Errorf("%w: %s requires JWT_PRIVATE_KEY_FILE", ErrInvalidJWTKey, cfg.Algorithm)
+		}
+		key, err := loadKeyFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.private == nil || key.method.Alg() != cfg.Algorithm {
+			return nil, fmt.Errorf("%w: %s does not hold a %s private key", ErrInvalidJWTKey, cfg.PrivateKeyFile, cfg.Algorithm)
+		}
+		current = key
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q, use HS256, RS256 or EdDSA", ErrInvalidJWTKey, cfg.Algorithm)
+	}
+
+	set := &KeySet{current: current, keys: map[string]*jwtKey{current.id: current}}
+	for _, secret := range cfg.PreviousSecrets {
+		key := hmacKey(secret)
+		key.private = nil
+		set.add(key)
+	}
+	for _, path := range cfg.PreviousPublicKeyFiles {
+		key, err := loadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key.private = nil
+		set.add(key)
+	}
+
+	if release && set.Weak() {
+		return nil, ErrWeakJWTSecret
+	}
+	return set, nil
+}
+
+// add keeps the current key when a previous key has the same id
+func (k *KeySet) add(key *jwtKey) {
+	if _, ok := k.keys[key.id]; !ok {
+		k.keys[key.id] = key
+	}
+}
+
+// Weak reports whether any HS256 secret in the set is short or a placeholder
+func (k *KeySet) Weak() bool {
+	for _, key := range k.keys {
+		if key.weak {
+			return true
+		}
+	}
+	return false
+}
+
+// JWKS lists the public keys in the set; HS256 secrets are never published
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range k.sortedKeys() {
+		if jwk, ok := publicJWK(key); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+// CurrentJWKS publishes the public keys of the installed key set
+func CurrentJWKS() JWKS {
+	return activeKeys().JWKS()
+}
+
+// sortedKeys returns the current key first, then the previous keys by id
+func (k *KeySet) sortedKeys() []*jwtKey {
+	keys := []*jwtKey{k.current}
+	var ids []string
+	for id := range k.keys {
+		if id != k.current.id {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		keys = append(keys, k.keys[id])
+	}
+	return keys
+}
+
+func (k *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.current.method, claims)
+	token.Header["kid"] = k.current.id
+	return token.SignedString(k.current.private)
+}
+
+// verificationKey picks the key named by the token's kid and insists the token uses that key's
+// algorithm, so a public key can never be used as an HMAC secret
+func (k *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownJWTKeyID
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// hmacKey derives the key id from the secret so it stays the same after the secret is rotated out
+func hmacKey(secret string) *jwtKey {
+	sum := sha256.Sum256([]byte("kid:" + secret))
+	return &jwtKey{
+		id:      "hs-" + hex.EncodeToString(sum[:8]),
+		method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+		weak:    len(secret) < MinSecretLength || placeholderSecrets[secret],
+	}
+}
+
+// loadKeyFile reads an RSA or Ed25519 key from a PEM file. Private keys in PKCS#1 or PKCS#8 and
+// public keys in PKIX or PKCS#1 form are accepted.
+func loadKeyFile(path string) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWTKey, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s is not PEM encoded", ErrInvalidJWTKey, path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: %s holds an unsupported %q block", ErrInvalidJWTKey, path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidJWTKey, path, err)
+	}
+
+	key := &jwtKey{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("%w: %s holds a %T, use an RSA or Ed25519 key", ErrInvalidJWTKey, path, parsed)
+	}
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("%w: %s is a %d-bit RSA key, use at least 2048 bits", ErrInvalidJWTKey, path, rsaKey.N.BitLen())
+	}
+	jwk, _ := publicJWK(key)
+	key.id = jwk.Kid
+	return key, nil
+}
+
+// publicJWK describes the public half of an asymmetric key. Its kid is the RFC 7638 thumbprint.
+func publicJWK(key *jwtKey) (JWK, bool) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	var jwk JWK
+	var thumbprint interface{}
+	switch public := key.public.(type) {
+	case *rsa.PublicKey:
+		jwk = JWK{Kty: "RSA", N: b64(public.N.Bytes()), E: b64(big.NewInt(int64(public.E)).Bytes())}
+		thumbprint = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case ed25519.PublicKey:
+		jwk = JWK{Kty: "OKP", Crv: "Ed25519", X: b64(public)}
+		thumbprint = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	default:
+		return JWK{}, false
+	}
+	members, _ := json.Marshal(thumbprint)
+	sum := sha256.Sum256(members)
+	jwk.Kid, jwk.Use, jwk.Alg = b64(sum[:]), "sig", key.method.Alg()
+	return jwk, true
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/aditherevenger/Budget-Tracker-API/config"
+)
+
+const strongSecret = "0123456789abcdef0123456789abcdef"
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil { t.Fatalf("write key: %v", err) }
+	return path
+}
+
+func rsaKeyFiles(t *testing.T) (private, public string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil { t.Fatalf("generate rsa: %v", err) }
+	pub, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	return writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)), writePEM(t, "PUBLIC KEY", pub)
+}
+
+func ed25519KeyFile(t *testing.T) string {
+	t.Helper()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	return writePEM(t, "PRIVATE KEY", der)
+}
+
+func useKeys(t *testing.T, cfg config.JWTConfig) *KeySet {
+	t.Helper()
+	keys, err := NewKeySet(cfg, true)
+	if err != nil { t.Fatalf("new key set: %v", err) }
+	SetKeySet(keys)
+	t.Cleanup(func() { SetKeySet(nil) })
+	return keys
+}
+
+func tokenKid(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Claims{})
+	if err != nil { t.Fatalf("parse: %v", err) }
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestNewKeySet_WeakSecret(t *testing.T) {
+	for _, secret := range []string{"short", "default-secret-change-in-production"} {
+		if _, err := NewKeySet(config.JWTConfig{Algorithm: "HS256", Secret: secret}, true); !errors.Is(err, ErrWeakJWTSecret) { t.Fatalf("%q: expected ErrWeakJWTSecret in release mode, got %v", secret, err) }
+		keys, err := NewKeySet(config.JWTConfig{Algorithm: "HS256", Secret: secret}, false)
+		if err != nil || !keys.Weak() { t.Fatalf("%q: expected a weak key set outside release mode, got %v", secret, err) }
+	}
+	if _, err := NewKeySet(config.JWTConfig{Secret: strongSecret, PreviousSecrets: []string{"old"}}, true); !errors.Is(err, ErrWeakJWTSecret) { t.Fatalf("expected a weak previous secret to be rejected, got %v", err) }
+	if _, err := NewKeySet(config.JWTConfig{Algorithm: "none"}, false); !errors.Is(err, ErrInvalidJWTKey) { t.Fatalf("expected ErrInvalidJWTKey, got %v", err) }
+	if _, err := NewKeySet(config.JWTConfig{Algorithm: "RS256"}, false); !errors.Is(err, ErrInvalidJWTKey) { t.Fatalf("expected a missing key file to be rejected, got %v", err) }
+}
+
+func TestKeySet_HS256Rotation(t *testing.T) {
+	old := useKeys(t, config.JWTConfig{Algorithm: "HS256", Secret: strongSecret})
+	token, err := GenerateToken(1, "a@example.com", 1, time.Minute)
+	if err != nil { t.Fatalf("generate: %v", err) }
+	if kid := tokenKid(t, token); kid != old.current.id || !strings.HasPrefix(kid, "hs-") { t.Fatalf("unexpected kid %q", kid) }
+	if jwks := CurrentJWKS(); len(jwks.Keys) != 0 { t.Fatalf("expected HS256 secrets to stay unpublished, got %+v", jwks) }
+
+	useKeys(t, config.JWTConfig{Secret: strings.Repeat("n", 40), PreviousSecrets: []string{strongSecret}})
+	if _, err := ValidateToken(token); err != nil { t.Fatalf("expected a token signed with the previous secret to validate: %v", err) }
+	fresh, _ := GenerateToken(1, "a@example.com", 1, time.Minute)
+	if tokenKid(t, fresh) == old.current.id { t.Fatalf("expected new tokens to be signed with the new secret") }
+
+	useKeys(t, config.JWTConfig{Secret: strings.Repeat("n", 40)})
+	if _, err := ValidateToken(token); err == nil { t.Fatalf("expected a token signed with a retired secret to be rejected") }
+}
+
+func TestKeySet_RS256AndJWKS(t *testing.T) {
+	private, public := rsaKeyFiles(t)
+	keys := useKeys(t, config.JWTConfig{Algorithm: "RS256", PrivateKeyFile: private})
+	token, err := GenerateToken(2, "b@example.com", 4, time.Minute)
+	if err != nil { t.Fatalf("generate: %v", err) }
+	claims, err := ValidateToken(token)
+	if err != nil || claims.UserID != 2 { t.Fatalf("validate: %v %+v", err, claims) }
+
+	jwks := CurrentJWKS()
+	if len(jwks.Keys) != 1 { t.Fatalf("expected one published key, got %+v", jwks) }
+	jwk := jwks.Keys[0]
+	if jwk.Kty != "RSA" || jwk.Alg != "RS256" || jwk.Use != "sig" || jwk.E != "AQAB" || jwk.Kid != tokenKid(t, token) { t.Fatalf("unexpected jwk %+v", jwk) }
+
+	// the public key file yields the same kid as the private key it belongs to
+	fromPublic, err := loadKeyFile(public)
+	if err != nil || fromPublic.id != keys.current.id { t.Fatalf("expected matching kids: %v", err) }
+
+	// an HS256 token keyed with the public key bytes must not pass as the RSA key
+	pemBytes, _ := os.ReadFile(public)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: 1})
+	forged.Header["kid"] = jwk.Kid
+	forgedString, _ := forged.SignedString(pemBytes)
+	if _, err := ValidateToken(forgedString); err == nil { t.Fatalf("expected an algorithm mismatch to be rejected") }
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: 1})
+	unsignedString, _ := unsigned.SignedString([]byte(strongSecret))
+	if _, err := ValidateToken(unsignedString); err == nil { t.Fatalf("expected a token without kid to be rejected") }
+}
+
+func TestKeySet_EdDSAWithPreviousRSAKey(t *testing.T) {
+	rsaPrivate, rsaPublic := rsaKeyFiles(t)
+	useKeys(t, config.JWTConfig{Algorithm: "RS256", PrivateKeyFile: rsaPrivate})
+	oldToken, _ := GenerateToken(3, "c@example.com", 5, time.Minute)
+
+	edPrivate := ed25519KeyFile(t)
+	if _, err := NewKeySet(config.JWTConfig{Algorithm: "RS256", PrivateKeyFile: edPrivate}, true); !errors.Is(err, ErrInvalidJWTKey) { t.Fatalf("expected an Ed25519 key to be rejected for RS256, got %v", err) }
+	if _, err := NewKeySet(config.JWTConfig{Algorithm: "RS256", PrivateKeyFile: rsaPublic}, true); !errors.Is(err, ErrInvalidJWTKey) { t.Fatalf("expected a public key to be rejected for signing, got %v", err) }
+
+	useKeys(t, config.JWTConfig{Algorithm: "EdDSA", PrivateKeyFile: edPrivate, PreviousPublicKeyFiles: []string{rsaPublic}})
+	newToken, err := GenerateToken(3, "c@example.com", 5, time.Minute)
+	if err != nil { t.Fatalf("generate: %v", err) }
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := ValidateToken(token); err != nil { t.Fatalf("validate: %v", err) }
+	}
+
+	jwks := CurrentJWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kty != "OKP" || jwks.Keys[0].Crv != "Ed25519" || jwks.Keys[0].Kid != tokenKid(t, newToken) || jwks.Keys[1].Kid != tokenKid(t, oldToken) { t.Fatalf("unexpected jwks %+v", jwks) }
+}
@@ -6,15 +6,42 @@ import (
 )
 
 func TestGenerateAndValidateToken_Success(t *testing.T) {
-	token, err := GenerateToken(7, "user@example.com")
+	token, err := GenerateToken(7, "user@example.com", 3, 15*time.Minute)
 	if err != nil { t.Fatalf("generate: %v", err) }
 	claims, err := ValidateToken(token)
 	if err != nil { t.Fatalf("validate: %v", err) }
-	if claims.UserID != 7 || claims.Email != "user@example.com" { t.Fatalf("unexpected claims: %+v", claims) }
-	if claims.ExpiresAt.Time.Before(time.Now()) { t.Fatalf("token should not be expired") }
+	if claims.UserID != 7 || claims.Email != "user@example.com" || claims.SessionID != 3 { t.Fatalf("unexpected claims: %+v", claims) }
+	if claims.ExpiresAt.Time.Before(time.Now()) || claims.ExpiresAt.Time.After(time.Now().Add(15*time.Minute)) { t.Fatalf("unexpected expiry %v", claims.ExpiresAt) }
+}
+
+func TestValidateToken_Expired(t *testing.T) {
+	token, err := GenerateToken(7, "user@example.com", 3, -time.Minute)
+	if err != nil { t.Fatalf("generate: %v", err) }
+	if _, err := ValidateToken(token); err == nil { t.Fatalf("expected expired token to be rejected") }
+}
+
+func TestNewOpaqueToken(t *testing.T) {
+	token, hash, err := NewOpaqueToken()
+	if err != nil { t.Fatalf("new token: %v", err) }
+	other, _, _ := NewOpaqueToken()
+	if len(token) != 43 || token == other || hash != HashToken(token) || len(hash) != 64 || hash == token { t.Fatalf("unexpected token %q / hash %q", token, hash) }
 }
 
 func TestValidateToken_Invalid(t *testing.T) {
 	_, err := ValidateToken("invalid.token.here")
 	if err == nil { t.Fatalf("expected error for invalid token") }
 }
+
+func TestChallengeToken(t *testing.T) {
+	challenge, err := GenerateChallengeToken(7, 5*time.Minute)
+	if err != nil { t.Fatalf("generate: %v", err) }
+	userID, err := ValidateChallengeToken(challenge)
+	if err != nil || userID != 7 { t.Fatalf("validate: %v user=%d", err, userID) }
+
+	// neither token passes for the other kind
+	if claims, err := ValidateToken(challenge); err == nil && claims.SessionID != 0 { t.Fatalf("expected a challenge token to carry no session") }
+	access, _ := GenerateToken(7, "user@example.com", 3, time.Minute)
+	if _, err := ValidateChallengeToken(access); err == nil { t.Fatalf("expected an access token to be rejected as a challenge") }
+	expired, _ := GenerateChallengeToken(7, -time.Minute)
+	if _, err := ValidateChallengeToken(expired); err == nil { t.Fatalf("expected an expired challenge to be rejected") }
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key of RFC 6238, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// the RFC lists 8 digit codes; the 6 digit code is their last six digits
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 1111111111: "050471", 1234567890: "005924", 2000000000: "279037"} {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil || code != want { t.Fatalf("time %d: expected %s, got %s (%v)", unix, want, code, err) }
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+	previous, _ := TOTPCode(rfc6238Secret, step-1)
+	current, _ := TOTPCode(rfc6238Secret, step)
+	stale, _ := TOTPCode(rfc6238Secret, step-3)
+
+	if got, ok := VerifyTOTP(rfc6238Secret, current, now, 0); !ok || got != step { t.Fatalf("expected the current code to match step %d, got %d %v", step, got, ok) }
+	if got, ok := VerifyTOTP(rfc6238Secret, previous[:3]+" "+previous[3:], now, 0); !ok || got != step-1 { t.Fatalf("expected the previous step to be accepted for clock drift") }
+	if _, ok := VerifyTOTP(rfc6238Secret, stale, now, 0); ok { t.Fatalf("expected a code three steps old to be rejected") }
+	if _, ok := VerifyTOTP(rfc6238Secret, current, now, step); ok { t.Fatalf("expected a used step to be rejected") }
+	if _, ok := VerifyTOTP(rfc6238Secret, "12345", now, 0); ok { t.Fatalf("expected a short code to be rejected") }
+}
+
+func TestNewTOTPSecretAndURI(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil || len(secret) != 32 { t.Fatalf("unexpected secret %q (%v)", secret, err) }
+	if _, err := TOTPCode(secret, 1); err != nil { t.Fatalf("expected a usable secret: %v", err) }
+
+	uri, err := url.Parse(TOTPURI("Budget Tracker", "jane@example.com", secret))
+	if err != nil { t.Fatalf("parse uri: %v", err) }
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Budget Tracker:jane@example.com" { t.Fatalf("unexpected uri %s", uri) }
+	if q := uri.Query(); q.Get("secret") != secret || q.Get("issuer") != "Budget Tracker" || q.Get("digits") != "6" || q.Get("period") != "30" { t.Fatalf("unexpected query %v", q) }
+}